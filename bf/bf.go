@@ -21,10 +21,120 @@ type Formula interface {
 // Solve solves the given formula.
 // f is first converted as a CNF formula. It is then given to gophersat.
 // The function returns a model associating each variable name with its binding, or nil if the formula was not satisfiable.
+// If f is simplified to a constant by Simplify, the solver is not called: all variables are false in the model
+// of a formula that is always true.
 func Solve(f Formula) map[string]bool {
+	switch Simplify(f).(type) {
+	case trueConst:
+		model := make(map[string]bool)
+		varNames(f, model)
+		return model
+	case falseConst:
+		return nil
+	}
 	return asCnf(f).solve()
 }
 
+// varNames adds the names of the variables of f to names, with a false binding.
+func varNames(f Formula, names map[string]bool) {
+	switch f := f.(type) {
+	case variable:
+		if !f.dummy {
+			names[f.name] = false
+		}
+	case lit:
+		varNames(f.v, names)
+	case not:
+		varNames(f[0], names)
+	case and:
+		for _, sub := range f {
+			varNames(sub, names)
+		}
+	case or:
+		for _, sub := range f {
+			varNames(sub, names)
+		}
+	case chain:
+		for _, sub := range f {
+			varNames(sub, names)
+		}
+	}
+}
+
+// Simplify returns a simplified version of f, in negation normal form.
+// Constants are propagated, duplicate literals are removed from conjunctions and disjunctions,
+// and conjunctions (resp. disjunctions) containing both a literal and its negation are replaced by False (resp. True).
+// The result is equivalent to f, except when f contains a chain, since chains are encoded with auxiliary variables:
+// the result is then only satisfiable iff f is.
+// In particular, if f is always true (resp. false), the result might be True (resp. False).
+func Simplify(f Formula) Formula {
+	return simplify(f.nnf())
+}
+
+// simplify simplifies the given NNF formula.
+func simplify(f Formula) Formula {
+	switch f := f.(type) {
+	case and:
+		res := make(and, 0, len(f))
+		seen := make(map[lit]bool)
+		for _, sub := range f {
+			switch sub := simplify(sub).(type) {
+			case falseConst:
+				return False
+			case trueConst:
+			case lit:
+				if seen[lit{v: sub.v, signed: !sub.signed}] {
+					return False
+				}
+				if !seen[sub] {
+					seen[sub] = true
+					res = append(res, sub)
+				}
+			default:
+				res = append(res, sub)
+			}
+		}
+		switch len(res) {
+		case 0:
+			return True
+		case 1:
+			return res[0]
+		default:
+			return res
+		}
+	case or:
+		res := make(or, 0, len(f))
+		seen := make(map[lit]bool)
+		for _, sub := range f {
+			switch sub := simplify(sub).(type) {
+			case trueConst:
+				return True
+			case falseConst:
+			case lit:
+				if seen[lit{v: sub.v, signed: !sub.signed}] {
+					return True
+				}
+				if !seen[sub] {
+					seen[sub] = true
+					res = append(res, sub)
+				}
+			default:
+				res = append(res, sub)
+			}
+		}
+		switch len(res) {
+		case 0:
+			return False
+		case 1:
+			return res[0]
+		default:
+			return res
+		}
+	default:
+		return f
+	}
+}
+
 // Dimacs writes the DIMACS CNF version of the formula on w.
 // It is useful so as to feed it to any SAT solver.
 // The original names of variables is associated with their DIMACS integer counterparts
@@ -66,6 +176,113 @@ func Dimacs(f Formula, w io.Writer) error {
 	return nil
 }
 
+// A WeightedFormula is a formula associated with a weight,
+// such as the cost of violating it in an optimization problem.
+type WeightedFormula struct {
+	Formula Formula
+	Weight  int
+}
+
+// CNF returns the CNF translation of each of the given formulas, as a list of clauses in the DIMACS format.
+// All formulas share the same variables: names associates the name of each variable with its DIMACS index,
+// and nbVars is the total number of variables, including dummy variables created during the translation,
+// which have no name.
+// It is useful to translate several formulas that must be treated differently, e.g some hard and some soft ones.
+func CNF(fs ...Formula) (cnfs [][][]int, names map[string]int, nbVars int) {
+	vs := vars{all: make(map[variable]int), pb: make(map[variable]int)}
+	cnfs = make([][][]int, len(fs))
+	for i, f := range fs {
+		cnfs[i] = cnfRec(f.nnf(), &vs)
+	}
+	names = make(map[string]int, len(vs.pb))
+	for v, idx := range vs.pb {
+		if !v.dummy {
+			names[v.name] = idx
+		}
+	}
+	return cnfs, names, len(vs.all)
+}
+
+// CNFWithMap returns the CNF translation of f, as a list of clauses in the DIMACS format,
+// along with the DIMACS index of each variable of f, and the description of each auxiliary variable
+// created during the translation, indexed by its DIMACS index.
+// Models of the clauses can thus be projected back onto the variables of f.
+// Auxiliary variables standing for a subformula are described as "tseitin-" followed by the subformula,
+// those created by Unique as "line-" or "col-" followed by their position and the unique variables,
+// and others as "dummy-" followed by their index.
+func CNFWithMap(f Formula) (clauses [][]int, names map[string]int, aux map[int]string) {
+	cnf := asCnf(f)
+	names = make(map[string]int, len(cnf.vars.pb))
+	aux = make(map[int]string)
+	for v, idx := range cnf.vars.all {
+		if v.dummy {
+			aux[idx] = v.name
+		} else {
+			names[v.name] = idx
+		}
+	}
+	return cnf.clauses, names, aux
+}
+
+// Eval evaluates f under the given, possibly partial, assignment.
+// known is true iff the value of f does not depend on the variables that are not bound by assignment,
+// in which case value is the truth value of f; otherwise, value is false.
+// Unlike the Eval method of formulas, it does not panic when a variable has no binding.
+// Dummy variables, such as the ones created by Unique with many variables, are never bound by the assignment.
+func Eval(f Formula, assignment map[string]bool) (value, known bool) {
+	switch f := f.(type) {
+	case trueConst:
+		return true, true
+	case falseConst:
+		return false, true
+	case variable:
+		value, known = assignment[f.name]
+		return value, known
+	case lit:
+		value, known = Eval(f.v, assignment)
+		return known && value != f.signed, known
+	case not:
+		value, known = Eval(f[0], assignment)
+		return known && !value, known
+	case and:
+		known = true
+		for _, sub := range f {
+			v, k := Eval(sub, assignment)
+			if k && !v { // One false subformula is enough
+				return false, true
+			}
+			known = known && k
+		}
+		return known, known
+	case or:
+		known = true
+		for _, sub := range f {
+			v, k := Eval(sub, assignment)
+			if k && v { // One true subformula is enough
+				return true, true
+			}
+			known = known && k
+		}
+		return false, known
+	case chain:
+		known = true
+		for i := 0; i < len(f)-1; i++ {
+			v1, k1 := Eval(f[i], assignment)
+			v2, k2 := Eval(f[i+1], assignment)
+			switch {
+			case k1 && v1 && k2 && !v2: // Broken link
+				return false, true
+			case (k1 && !v1) || (k2 && v2): // Satisfied link
+			default:
+				known = false
+			}
+		}
+		return known, known
+	default:
+		panic(fmt.Errorf("unexpected formula type %T", f))
+	}
+}
+
 // The "true" constant.
 type trueConst struct{}
 
@@ -174,6 +391,11 @@ func (n not) nnf() Formula {
 			subs[i] = not{sub}.nnf()
 		}
 		return and(subs).nnf()
+	case chain: // Dummy variables cannot be used under a negation
+		if len(f) < 2 {
+			return False
+		}
+		return f.negation().nnf()
 	case trueConst:
 		return False
 	case falseConst:
@@ -308,6 +530,86 @@ func Xor(f1, f2 Formula) Formula {
 	return and{or{not{f1}, not{f2}}, or{f1, f2}}
 }
 
+// Rule indicates the conjunction of the given antecedents implies the consequent,
+// i.e (a1 & a2 & ...) => c.
+// If there are no antecedents, the consequent must be true.
+func Rule(antecedents []Formula, consequent Formula) Formula {
+	subs := make([]Formula, len(antecedents)+1)
+	for i, a := range antecedents {
+		subs[i] = not{a}
+	}
+	subs[len(antecedents)] = consequent
+	return or(subs)
+}
+
+// Chain indicates each given subformula implies the next one, i.e (f0 => f1) & (f1 => f2) & ...
+// Each subformula that is not a literal is associated with a dummy variable when converted in CNF,
+// so that it is only encoded once even though it appears twice in the chain.
+// Identical subformulas, even in different chains, share the same dummy variable.
+func Chain(fs ...Formula) Formula {
+	return chain(fs)
+}
+
+type chain []Formula
+
+// negation returns the negation of the chain, i.e a formula stating one of the subformulas is true
+// while the next one is false.
+func (c chain) negation() Formula {
+	res := make(or, 0, len(c))
+	for i := 0; i < len(c)-1; i++ {
+		res = append(res, and{c[i], not{c[i+1]}})
+	}
+	return res
+}
+
+func (c chain) nnf() Formula {
+	if len(c) < 2 {
+		return True
+	}
+	links := make([]Formula, len(c))
+	var res and
+	for i, f := range c {
+		nnf := f.nnf()
+		if _, ok := nnf.(lit); ok || i == 0 || i == len(c)-1 { // First and last formulas only appear once
+			links[i] = nnf
+			continue
+		}
+		d := dummyVar("tseitin-" + nnf.String())
+		links[i] = d
+		res = append(res, Eq(d, nnf))
+	}
+	for i := 0; i < len(links)-1; i++ {
+		switch nnf := Implies(links[i], links[i+1]).nnf().(type) {
+		case trueConst:
+		case falseConst:
+			return False
+		default:
+			res = append(res, nnf)
+		}
+	}
+	if len(res) == 0 { // All implications are trivially true
+		return True
+	}
+	return res.nnf()
+}
+
+func (c chain) String() string {
+	strs := make([]string, len(c))
+	for i, f := range c {
+		strs[i] = f.String()
+	}
+	return "chain(" + strings.Join(strs, ", ") + ")"
+}
+
+func (c chain) Eval(model map[string]bool) bool {
+	for i := 0; i < len(c)-1; i++ {
+		if c[i].Eval(model) && !c[i+1].Eval(model) {
+			return false
+		}
+	}
+	return true
+}
+
 // Unique indicates exactly one of the given variables must be true.
 // It might create dummy variables to reduce the number of generated clauses.
 func Unique(vars ...string) Formula {
@@ -3,7 +3,10 @@ package bf
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/crillab/gophersat/solver"
 )
 
 func TestMultipleTimesSameVariable(t *testing.T) {
@@ -53,6 +56,75 @@ func TestCNF(t *testing.T) {
 	}
 }
 
+func TestCNFSharedVars(t *testing.T) {
+	cnfs, names, nbVars := CNF(Or(Var("a"), Var("b")), Not(Var("a")), Or(Var("c"), And(Var("a"), Var("b"))))
+	if len(cnfs) != 3 || len(names) != 3 || nbVars != 4 {
+		t.Fatalf("invalid translation: %v, %v, %d vars", cnfs, names, nbVars)
+	}
+	if fmt.Sprint(cnfs[1]) != fmt.Sprint([][]int{{-names["a"]}}) {
+		t.Errorf("invalid translation of second formula: %v", cnfs[1])
+	}
+}
+
+func TestCNFWithMap(t *testing.T) {
+	ab := And(Var("a"), Var("b"))
+	f := And(Chain(Var("x"), ab, Var("y")), Var("x"), Unique("a", "c", "d", "e", "f"))
+	clauses, names, aux := CNFWithMap(f)
+	if len(names) != 8 {
+		t.Errorf("expected 8 named vars, got %v", names)
+	}
+	nbTseitin := 0
+	for idx, name := range aux {
+		if _, ok := names[name]; ok || idx < 1 || idx > len(names)+len(aux) {
+			t.Errorf("invalid auxiliary var %d=%s", idx, name)
+		}
+		if strings.HasPrefix(name, "tseitin-") {
+			nbTseitin++
+		}
+	}
+	if nbTseitin != 1 {
+		t.Errorf("expected 1 tseitin var, got %d in %v", nbTseitin, aux)
+	}
+	s := solver.New(solver.ParseSliceNb(clauses, len(names)+len(aux)))
+	if s.Solve() != solver.Sat {
+		t.Fatalf("expected sat")
+	}
+	model := s.Model()
+	assignment := make(map[string]bool, len(names))
+	for name, idx := range names {
+		assignment[name] = model[idx-1]
+	}
+	if !assignment["a"] || assignment["c"] || !assignment["x"] || !assignment["y"] {
+		t.Errorf("invalid projected model %v", assignment)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	x, y := Var("x"), Var("y")
+	tests := []struct {
+		f        Formula
+		expected string
+	}{
+		{True, "⊤"},
+		{And(x, Not(x)), "⊥"},
+		{Or(x, Not(x), y), "⊤"},
+		{And(Or(x, Not(x)), y, y), "y"},
+		{And(Or(x, y), Not(Or(x, y))), "and(or(x, y), not(x), not(y))"},
+		{Or(And(x, False), Not(Implies(y, y))), "⊥"},
+	}
+	for _, test := range tests {
+		if res := Simplify(test.f).String(); res != test.expected {
+			t.Errorf("simplifying %v: expected %s, got %s", test.f, test.expected, res)
+		}
+	}
+	if model := Solve(And(x, Not(x))); model != nil {
+		t.Errorf("expected unsat, got %v", model)
+	}
+	if model := Solve(Or(x, Not(And(x, True)))); model == nil || len(model) != 1 {
+		t.Errorf("expected model for x, got %v", model)
+	}
+}
+
 func TestUnique(t *testing.T) {
 	f := And(Var("a"), Unique("a", "b", "c", "d", "e"))
 	model := Solve(f)
@@ -322,3 +394,91 @@ func TestPanic2(t *testing.T) {
 		t.Errorf("should be exactly two vars")
 	}
 }
+
+func TestChain(t *testing.T) {
+	ab := And(Var("a"), Var("b"))
+	f := And(Chain(Var("x"), ab, Or(Var("c"), Var("d")), Var("y")), Var("x"), Not(Var("y")))
+	if model := Solve(f); model != nil {
+		t.Errorf("expected unsat, got %v", model)
+	}
+	f = And(Chain(Var("x"), ab, Var("y")), Chain(Var("z"), ab), Var("z"))
+	model := Solve(f)
+	if model == nil {
+		t.Fatalf("expected sat, got unsat")
+	}
+	if !model["a"] || !model["b"] || !f.Eval(model) {
+		t.Errorf("invalid model %v", model)
+	}
+	nbTseitin := 0
+	for v := range asCnf(And(Chain(Var("x"), ab, Var("y")), Chain(Var("z"), ab, Var("t")))).vars.all {
+		if strings.HasPrefix(v.name, "tseitin-") {
+			nbTseitin++
+		}
+	}
+	if nbTseitin != 1 {
+		t.Errorf("expected 1 shared dummy var, got %d", nbTseitin)
+	}
+	f = And(Not(Chain(Var("x"), ab, Var("y"))), Var("x"), Var("a"), Var("b"))
+	if model := Solve(f); model == nil || model["y"] {
+		t.Errorf("invalid model for negated chain %v", model)
+	}
+	if !Chain(Var("x")).Eval(map[string]bool{"x": false}) {
+		t.Errorf("chain with one formula should be true")
+	}
+}
+
+func TestRule(t *testing.T) {
+	f := Rule([]Formula{Var("a"), Var("b"), Not(Var("c"))}, Var("d"))
+	if cnf := asCnf(f); len(cnf.clauses) != 1 {
+		t.Errorf("rule should be encoded as a single clause, got %v", cnf.clauses)
+	}
+	model := map[string]bool{"a": true, "b": true, "c": false, "d": false}
+	if f.Eval(model) {
+		t.Errorf("rule should be false")
+	}
+	model["c"] = true
+	if !f.Eval(model) {
+		t.Errorf("rule should be true")
+	}
+	if model := Solve(And(f, Var("a"), Var("b"), Not(Var("c")))); model == nil || !model["d"] {
+		t.Errorf("invalid model %v", model)
+	}
+}
+
+func TestEval(t *testing.T) {
+	f := And(Or(Var("a"), Var("b")), Implies(Var("c"), Not(Var("d"))), Chain(Var("x"), Var("y")))
+	tests := []struct {
+		assignment   map[string]bool
+		value, known bool
+	}{
+		{map[string]bool{}, false, false},
+		{map[string]bool{"a": false, "b": false}, false, true},
+		{map[string]bool{"a": true, "c": false, "x": false}, true, true},
+		{map[string]bool{"a": true, "c": false}, false, false},
+		{map[string]bool{"a": true, "d": false, "y": true}, true, true},
+		{map[string]bool{"b": true, "c": true, "d": true, "x": false}, false, true},
+		{map[string]bool{"b": true, "d": false, "x": true, "y": false}, false, true},
+		{map[string]bool{"a": true, "b": false, "c": true, "d": false, "x": true, "y": true}, true, true},
+	}
+	for _, test := range tests {
+		value, known := Eval(f, test.assignment)
+		if value != test.value || known != test.known {
+			t.Errorf("with %v: expected (%t, %t), got (%t, %t)", test.assignment, test.value, test.known, value, known)
+		}
+		if known && value != f.Eval(fullAssignment(test.assignment, "a", "b", "c", "d", "x", "y")) {
+			t.Errorf("with %v: Eval disagrees with the Eval method", test.assignment)
+		}
+	}
+	if value, known := Eval(And(True, Not(False)), nil); !value || !known {
+		t.Errorf("expected constants to be known, got (%t, %t)", value, known)
+	}
+}
+
+// fullAssignment returns a copy of assignment where the given unbound vars are false.
+func fullAssignment(assignment map[string]bool, vars ...string) map[string]bool {
+	res := make(map[string]bool, len(vars))
+	for _, v := range vars {
+		res[v] = assignment[v]
+	}
+	return res
+}
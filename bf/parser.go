@@ -8,9 +8,45 @@ import (
 )
 
 type parser struct {
-	s     scanner.Scanner
-	eof   bool   // Have we reached eof yet?
-	token string // Last token read
+	s       scanner.Scanner
+	eof     bool   // Have we reached eof yet?
+	token   string // Last token read
+	funcs   bool   // Whether the functional syntax of ParseFile, such as "and(a, b)", is accepted
+	scanErr error  // First error reported by the scanner, if any
+}
+
+// A ParseError is an error met while parsing a formula, because its syntax is invalid.
+type ParseError struct {
+	Line int    // Line where the error was found, starting at 1.
+	Col  int    // Column where the error was found, starting at 1.
+	Msg  string // Description of the error.
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, col %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// newParser returns a parser reading from r.
+// Errors met by the underlying scanner, such as unterminated comments, are kept in p.scanErr.
+func newParser(r io.Reader, funcs bool) *parser {
+	p := &parser{funcs: funcs}
+	p.s.Init(r)
+	p.s.Error = func(s *scanner.Scanner, msg string) {
+		if p.scanErr == nil {
+			pos := s.Pos()
+			p.scanErr = &ParseError{Line: pos.Line, Col: pos.Column, Msg: msg}
+		}
+	}
+	return p
+}
+
+// errorf returns a ParseError located at the last read token.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	pos := p.s.Position
+	if !pos.IsValid() {
+		pos = p.s.Pos()
+	}
+	return &ParseError{Line: pos.Line, Col: pos.Column, Msg: fmt.Sprintf(format, args...)}
 }
 
 // Parse parses the formula from the given input Reader.
@@ -36,17 +72,60 @@ type parser struct {
 // Note there are two ways to write conjunctions, one with a low priority, one with a high priority.
 // The low-priority one is useful when the user wants to describe a whole formula as a set of smaller formulas
 // that must all be true.
+// Syntax errors are reported as *ParseError.
 func Parse(r io.Reader) (Formula, error) {
-	var s scanner.Scanner
-	s.Init(r)
-	p := parser{s: s}
+	return newParser(r, false).parse()
+}
+
+// ParseFile parses a file in the BF format from r, and returns the corresponding Formula.
+// A BF file is a formula in the syntax of Parse, usually made of several subformulas separated by ";",
+// that can span several lines, as in:
+//
+//	// Each pigeon is in a hole
+//	p1h1 | p1h2;
+//	p2h1 | p2h2;
+//	/* No hole holds two pigeons */
+//	and(or(^p1h1, ^p2h1), or(^p1h2, ^p2h2));
+//
+// The accepted grammar is the one of Parse, extended as follows:
+//
+//	formula ::= { clause ';' }* [ clause ]
+//	atom    ::= ident | '(' clause ')' | '{' ident { ',' ident }* '}' | call
+//	call    ::= ('and' | 'or') '(' clause { ',' clause }* ')' | 'not' '(' clause ')'
+//
+// where clause is a formula with no ";" operator. The functional syntax of calls is the one used by
+// the String method of formulas. Comments start with "//" and end with the line, or are enclosed in "/*" and "*/".
+// A file that contains no formula, e.g only comments, denotes True.
+// Syntax errors are reported as *ParseError, with the line and column of the offending token.
+func ParseFile(r io.Reader) (Formula, error) {
+	p := newParser(r, true)
 	p.scan()
+	if p.eof {
+		if p.scanErr != nil {
+			return nil, p.scanErr
+		}
+		return True, nil
+	}
+	return p.parseFrom()
+}
+
+// parse parses a whole formula and checks nothing follows it.
+func (p *parser) parse() (Formula, error) {
+	p.scan()
+	return p.parseFrom()
+}
+
+// parseFrom is like parse, but the first token was already read.
+func (p *parser) parseFrom() (Formula, error) {
 	f, err := p.parseClause()
+	if p.scanErr != nil { // Errors met by the scanner explain the other ones, if any
+		return nil, p.scanErr
+	}
 	if err != nil {
 		return f, err
 	}
 	if !p.eof {
-		return nil, fmt.Errorf("expected EOF, found %q at %v", p.token, p.s.Pos())
+		return nil, p.errorf("expected EOF, found %q", p.token)
 	}
 	return f, nil
 }
@@ -62,7 +141,7 @@ func (p *parser) scan() {
 
 func (p *parser) parseClause() (f Formula, err error) {
 	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %q", p.token)
 	}
 	f, err = p.parseEquiv()
 	if err != nil {
@@ -87,10 +166,10 @@ func (p *parser) parseClause() (f Formula, err error) {
 
 func (p *parser) parseEquiv() (f Formula, err error) {
 	if p.eof {
-		return nil, fmt.Errorf("At position %v, expected expression, found EOF", p.s.Pos())
+		return nil, p.errorf("expected expression, found EOF")
 	}
 	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %q", p.token)
 	}
 	f, err = p.parseImplies()
 	if err != nil {
@@ -102,7 +181,7 @@ func (p *parser) parseEquiv() (f Formula, err error) {
 	if p.token == "=" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		f2, err := p.parseEquiv()
 		if err != nil {
@@ -124,14 +203,14 @@ func (p *parser) parseImplies() (f Formula, err error) {
 	if p.token == "-" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		if p.token != ">" {
-			return nil, fmt.Errorf("invalid token %q at %v", "-"+p.token, p.s.Pos())
+			return nil, p.errorf("invalid token %q", "-"+p.token)
 		}
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		f2, err := p.parseImplies()
 		if err != nil {
@@ -153,7 +232,7 @@ func (p *parser) parseOr() (f Formula, err error) {
 	if p.token == "|" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		f2, err := p.parseOr()
 		if err != nil {
@@ -175,7 +254,7 @@ func (p *parser) parseAnd() (f Formula, err error) {
 	if p.token == "&" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		f2, err := p.parseAnd()
 		if err != nil {
@@ -188,12 +267,12 @@ func (p *parser) parseAnd() (f Formula, err error) {
 
 func (p *parser) parseNot() (f Formula, err error) {
 	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %q", p.token)
 	}
 	if p.token == "^" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF")
 		}
 		f, err = p.parseNot()
 		if err != nil {
@@ -210,7 +289,7 @@ func (p *parser) parseNot() (f Formula, err error) {
 
 func (p *parser) parseBasic() (f Formula, err error) {
 	if isOperator(p.token) || p.token == ")" {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %q", p.token)
 	}
 	if p.token == "(" {
 		p.scan()
@@ -219,10 +298,10 @@ func (p *parser) parseBasic() (f Formula, err error) {
 			return nil, err
 		}
 		if p.eof {
-			return nil, fmt.Errorf("expected closing parenthesis, found EOF at %s", p.s.Pos())
+			return nil, p.errorf("expected closing parenthesis, found EOF")
 		}
 		if p.token != ")" {
-			return nil, fmt.Errorf("expected closing parenthesis, found %q at %s", p.token, p.s.Pos())
+			return nil, p.errorf("expected closing parenthesis, found %q", p.token)
 		}
 		p.scan()
 		return f, nil
@@ -232,23 +311,62 @@ func (p *parser) parseBasic() (f Formula, err error) {
 		for p.token != "}" {
 			p.scan()
 			if p.eof {
-				return nil, fmt.Errorf("expected identifier, found EOF at %s", p.s.Pos())
+				return nil, p.errorf("expected identifier, found EOF")
 			}
 			if token.Lookup(p.token) != token.IDENT {
-				return nil, fmt.Errorf("expected variable name, found %q at %s", p.token, p.s.Pos())
+				return nil, p.errorf("expected variable name, found %q", p.token)
 			}
 			vars = append(vars, p.token)
 			p.scan()
 			if p.eof {
-				return nil, fmt.Errorf("expected comma or closing brace, found EOF at %s", p.s.Pos())
+				return nil, p.errorf("expected comma or closing brace, found EOF")
 			}
 			if p.token != "}" && p.token != "," {
-				return nil, fmt.Errorf("expected comma or closing brace, found %q at %v", p.token, p.s.Pos())
+				return nil, p.errorf("expected comma or closing brace, found %q", p.token)
 			}
 		}
 		p.scan()
 		return Unique(vars...), nil
 	}
-	defer p.scan()
-	return Var(p.token), nil
+	name := p.token
+	p.scan()
+	if p.funcs && (name == "and" || name == "or" || name == "not") && !p.eof && p.token == "(" {
+		return p.parseCall(name)
+	}
+	return Var(name), nil
+}
+
+// parseCall parses the arguments of a call to the given function, such as "and(a, b)".
+// The current token is the opening parenthesis.
+func (p *parser) parseCall(name string) (f Formula, err error) {
+	var args []Formula
+	for p.token != ")" {
+		p.scan()
+		if p.eof {
+			return nil, p.errorf("expected expression, found EOF")
+		}
+		arg, err := p.parseEquiv()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.eof {
+			return nil, p.errorf("expected comma or closing parenthesis, found EOF")
+		}
+		if p.token != ")" && p.token != "," {
+			return nil, p.errorf("expected comma or closing parenthesis, found %q", p.token)
+		}
+	}
+	p.scan()
+	switch name {
+	case "and":
+		return And(args...), nil
+	case "or":
+		return Or(args...), nil
+	default:
+		if len(args) != 1 {
+			return nil, p.errorf("not expects exactly one argument, found %d", len(args))
+		}
+		return Not(args[0]), nil
+	}
 }
@@ -1,6 +1,7 @@
 package bf
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -38,6 +39,49 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseFile(t *testing.T) {
+	const file = `// Each pigeon is in a hole
+p1h1 | p1h2;
+p2h1 | p2h2;
+/* No hole holds two pigeons */
+and(or(^p1h1, ^p2h1), or(^p1h2, ^p2h2));
+`
+	f, err := ParseFile(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("could not parse file: %v", err)
+	}
+	expected := "and(or(p1h1, p1h2), and(or(p2h1, p2h2), and(or(not(p1h1), not(p2h1)), or(not(p1h2), not(p2h2)))))"
+	if f.String() != expected {
+		t.Errorf("expected formula %q, got %q", expected, f.String())
+	}
+	if f2, err := ParseFile(strings.NewReader(f.String())); err != nil {
+		t.Errorf("could not parse the output of String: %v", err)
+	} else if f2.String() != expected {
+		t.Errorf("expected formula %q after round trip, got %q", expected, f2.String())
+	}
+	if f, err := ParseFile(strings.NewReader("// Nothing here\n")); err != nil || f != True {
+		t.Errorf("expected True for an empty file, got %v, %v", f, err)
+	}
+	errs := []struct {
+		file      string
+		line, col int
+	}{
+		{"a | b;\nc & & d", 2, 5},
+		{"a;\nnot(a, b)", 2, 10},
+		{"and(a, b", 1, 9},
+		{"a;\n/* unterminated", 2, 16},
+	}
+	for _, test := range errs {
+		_, err := ParseFile(strings.NewReader(test.file))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("expected a ParseError for %q, got %v", test.file, err)
+		} else if perr.Line != test.line || perr.Col != test.col {
+			t.Errorf("expected error at line %d, col %d for %q, got %v", test.line, test.col, test.file, perr)
+		}
+	}
+}
+
 func ExampleParse() {
 	expr := "a & ^(b -> c) & (c = d | ^a)"
 	f, err := Parse(strings.NewReader(expr))
@@ -148,7 +148,7 @@ func parseAndSolveWCNF(path string, verbose bool) error {
 	}
 	results := make(chan solver.Result)
 	go s.Optimal(results, nil)
-	printOptimizationResults(results)
+	printOptimizationResults(results, func(cost int) int { return cost })
 	return nil
 }
 
@@ -191,7 +191,8 @@ func parse(path string) (pb *solver.Problem, printFn func(chan solver.Result), e
 		if err != nil {
 			return nil, nil, fmt.Errorf("could not parse OPB file %q: %v", path, err)
 		}
-		return pb, printOptimizationResults, nil
+		printFn := func(results chan solver.Result) { printOptimizationResults(results, pb.ObjectiveValue) }
+		return pb, printFn, nil
 	}
 	return nil, nil, fmt.Errorf("invalid file format for %q", path)
 }
@@ -237,11 +238,13 @@ func printDecisionResults(results chan solver.Result) {
 }
 
 // prints the result to a PB optimization problem in the competition format.
-func printOptimizationResults(results chan solver.Result) {
+// value converts the costs minimized by the solver into values of the objective function of the problem,
+// e.g for maximization problems.
+func printOptimizationResults(results chan solver.Result, value func(cost int) int) {
 	var res solver.Result
 	for res = range results {
 		if res.Status == solver.Sat {
-			fmt.Printf("o %d\n", res.Weight)
+			fmt.Printf("o %d\n", value(res.Weight))
 		}
 	}
 	switch res.Status {
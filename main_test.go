@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what f writes on the standard output.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("could not create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	return <-out
+}
+
+func TestSolveMaxOPB(t *testing.T) {
+	opb := `* #variable= 4 #constraint= 3
+max: +3 x1 +2 x2 +4 x3 -1 x4 ;
++1 ~x1 +1 ~x3 >= 1 ;
++1 ~x2 +1 ~x3 >= 1 ;
++1 x4 >= 1 ;
+`
+	path := filepath.Join(t.TempDir(), "max.opb")
+	if err := os.WriteFile(path, []byte(opb), 0600); err != nil {
+		t.Fatalf("could not write OPB file: %v", err)
+	}
+	pb, printFn, err := parse(path)
+	if err != nil {
+		t.Fatalf("could not parse %q: %v", path, err)
+	}
+	out := captureStdout(t, func() { solve(pb, false, false, false, printFn) })
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	var last string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "o ") {
+			last = line
+		}
+	}
+	if last != "o 4" {
+		t.Errorf("invalid last objective line: expected \"o 4\", got %q in output %q", last, out)
+	}
+	if !strings.Contains(out, "s OPTIMUM FOUND") || !strings.Contains(out, "v x1 x2 -x3 x4 ") {
+		t.Errorf("invalid output %q", out)
+	}
+}
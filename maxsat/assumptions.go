@@ -0,0 +1,92 @@
+package maxsat
+
+import "github.com/crillab/gophersat/solver"
+
+// An AssumptionSet is a set of values assumed for some vars of a problem, that can be updated in place
+// and given to SolveWithSet, e.g to explore many scenarios that share most of their assumptions.
+// The name of each var is translated to a lit once, when its value is set, and the underlying slice of lits
+// is reused from one call to the next, so that solving under the set does not need to translate or allocate the assumptions again.
+// An AssumptionSet must only be used with the problem that created it.
+type AssumptionSet struct {
+	pb      *Problem
+	lits    []solver.Lit    // Assumed lits
+	names   []string        // Name of the var of each assumed lit
+	idx     map[string]int  // Index of the lit assumed for each var in lits
+	unknown map[string]bool // Values set for vars that do not appear in the problem
+}
+
+// NewAssumptionSet returns a new, empty set of assumptions for pb.
+func (pb *Problem) NewAssumptionSet() *AssumptionSet {
+	return &AssumptionSet{pb: pb, idx: make(map[string]int), unknown: make(map[string]bool)}
+}
+
+// Set assumes the given value for the var with the given name, replacing the previous value, if any.
+// As in SolveFixing, vars that do not appear in the problem when Set is called are simply given their value
+// in the models returned by SolveWithSet.
+func (as *AssumptionSet) Set(name string, val bool) {
+	v, ok := as.pb.intVars[name]
+	if !ok {
+		as.unknown[name] = val
+		return
+	}
+	if !val {
+		v = -v
+	}
+	lit := solver.IntToLit(int32(v))
+	if i, ok := as.idx[name]; ok {
+		as.lits[i] = lit
+		return
+	}
+	as.idx[name] = len(as.lits)
+	as.lits = append(as.lits, lit)
+	as.names = append(as.names, name)
+}
+
+// Unset removes the assumption on the var with the given name, if any.
+// The order of the remaining assumptions can change.
+func (as *AssumptionSet) Unset(name string) {
+	delete(as.unknown, name)
+	i, ok := as.idx[name]
+	if !ok {
+		return
+	}
+	last := len(as.lits) - 1
+	as.lits[i], as.names[i] = as.lits[last], as.names[last]
+	as.idx[as.names[i]] = i
+	as.lits, as.names = as.lits[:last], as.names[:last]
+	delete(as.idx, name)
+}
+
+// Clear removes all assumptions from the set, keeping the allocated memory for later assumptions.
+func (as *AssumptionSet) Clear() {
+	as.lits, as.names = as.lits[:0], as.names[:0]
+	for name := range as.idx {
+		delete(as.idx, name)
+	}
+	for name := range as.unknown {
+		delete(as.unknown, name)
+	}
+}
+
+// Len returns the number of vars whose value is assumed in the set.
+func (as *AssumptionSet) Len() int {
+	return len(as.lits) + len(as.unknown)
+}
+
+// SolveWithSet is like SolveFixing, with the values assumed in the given set, but without translating
+// or allocating the assumptions, so it is better suited to solving the problem many times under different assumptions.
+// As with SolveFixing, assumptions are only made for this call.
+// It panics if as was created by another problem.
+func (pb *Problem) SolveWithSet(as *AssumptionSet) (Model, int, []int) {
+	if as.pb != pb {
+		panic("cannot solve with an assumption set created by another problem")
+	}
+	defer pb.assume(nil)
+	model, cost, broken := pb.solveUnder(as.lits)
+	if model != nil {
+		for name, val := range as.unknown {
+			model[name] = val
+		}
+	}
+	return model, cost, broken
+}
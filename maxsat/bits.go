@@ -0,0 +1,74 @@
+package maxsat
+
+// A Bitset is a compact representation of a model: the binding of the var whose numeric id is id
+// is stored in a single bit.
+type Bitset []uint64
+
+// newBitset returns the Bitset equivalent to the given model, as returned by the solver.
+func newBitset(model []bool) Bitset {
+	res := make(Bitset, (len(model)+63)/64)
+	for i, val := range model {
+		if val {
+			res[i/64] |= 1 << uint(i%64)
+		}
+	}
+	return res
+}
+
+// Get returns the binding of the var whose numeric id is id.
+// Ids start at 1; false is returned for unknown ids.
+func (b Bitset) Get(id int) bool {
+	i := id - 1
+	if i < 0 || i/64 >= len(b) {
+		return false
+	}
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
+
+// SolveBits is like Solve, but it returns the model as a Bitset, indexed by the numeric ids of the vars,
+// along with the indices of the soft constraints it violates, sorted in increasing order.
+// Numeric ids can be retrieved with ID. This is much cheaper than a Model for problems with lots of vars.
+// Auxiliary vars also appear in the Bitset.
+// If the problem is not satisfiable, it returns nil, -1 and nil.
+func (pb *Problem) SolveBits() (Bitset, int, []int) {
+	cost := pb.solve()
+	if cost == -1 {
+		return nil, -1, nil
+	}
+	return newBitset(pb.lastModel), cost, pb.broken(pb.lastModel)
+}
+
+// ID returns the numeric id of the var with the given name, as used in the Bitset returned by SolveBits,
+// and true, or 0 and false if there is no such var in the problem.
+func (pb *Problem) ID(name string) (int, bool) {
+	id, ok := pb.intVars[name]
+	return id, ok
+}
+
+// A CompactModel is a model stored as a Bitset, whose vars can still be accessed by their names.
+type CompactModel struct {
+	bits Bitset
+	pb   *Problem
+}
+
+// Value returns the binding of the var with the given name, or false if there is no such var.
+func (m *CompactModel) Value(name string) bool {
+	id, ok := m.pb.intVars[name]
+	return ok && m.bits.Get(id)
+}
+
+// Bits returns the underlying Bitset of m.
+func (m *CompactModel) Bits() Bitset {
+	return m.bits
+}
+
+// SolveCompact is like Solve, but it returns the model as a CompactModel,
+// whose names are only mapped to their binding when they are queried.
+// If the problem is not satisfiable, it returns nil and -1.
+func (pb *Problem) SolveCompact() (*CompactModel, int) {
+	bits, cost, _ := pb.SolveBits()
+	if bits == nil {
+		return nil, -1
+	}
+	return &CompactModel{bits: bits, pb: pb}, cost
+}
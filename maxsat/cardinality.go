@@ -0,0 +1,67 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// AddGlobalAtLeast adds a new hard constraint to the problem, stating that at least k of the given vars must be true.
+// Unlike AddConstr with a cardinality constraint, that is given as is to the solver, the constraint is encoded
+// into clauses with a sequential counter, whose auxiliary vars are not part of the models, e.g to count over many vars
+// with a solver that handles clauses better than cardinality constraints.
+// The encoding needs at most 2·n·(k+1) clauses and n·k auxiliary vars, where n is the number of vars, so it is linear in n
+// for a fixed k. When k is more than n/2, the false vars are counted instead, up to n-k.
+// As with AddConstr, the solver is reused, and vars that were not part of the problem yet are added to it.
+// The constraint is part of the problem as a single hard constraint, whose index is the number of constraints
+// so far, e.g for TagConstr. An error is returned if a var appears more than once.
+func (pb *Problem) AddGlobalAtLeast(k int, vars []string) error {
+	lits, err := cardLits(vars, false)
+	if err != nil {
+		return err
+	}
+	pb.addCard(lits, k)
+	return nil
+}
+
+// AddGlobalAtMost is like AddGlobalAtLeast, but the new hard constraint states that at most k of the given vars can be true,
+// i.e that at least n-k of them must be false, which is encoded with at most 2·n·(k+1) clauses and n·k auxiliary vars.
+func (pb *Problem) AddGlobalAtMost(k int, vars []string) error {
+	lits, err := cardLits(vars, true)
+	if err != nil {
+		return err
+	}
+	pb.addCard(lits, len(vars)-k)
+	return nil
+}
+
+// cardLits returns the lits on the given vars, negated if negated is true,
+// or an error if a var appears more than once.
+func cardLits(vars []string, negated bool) ([]Lit, error) {
+	lits := make([]Lit, len(vars))
+	seen := make(map[string]bool, len(vars))
+	for i, name := range vars {
+		if seen[name] {
+			return nil, fmt.Errorf("cannot add global cardinality constraint: var %q appears more than once", name)
+		}
+		seen[name] = true
+		lits[i] = Lit{Var: name, Negated: negated}
+	}
+	return lits, nil
+}
+
+// addCard adds a new hard constraint stating that at least k of the given lits must be true,
+// encoded with a sequential counter, as described in AddGlobalAtLeast.
+func (pb *Problem) addCard(lits []Lit, k int) {
+	for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+		pb.varInts = append(pb.varInts, "")
+	}
+	ints := pb.intLits(lits)
+	enc := solver.NewCardinalityEncoder()
+	enc.NbVars = len(pb.varInts)
+	parts := enc.Encode(ints, k)
+	for len(pb.varInts) < enc.NbVars { // Auxiliary vars of the encoding
+		pb.varInts = append(pb.varInts, "")
+	}
+	pb.addHard(parts)
+}
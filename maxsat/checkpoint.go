@@ -0,0 +1,233 @@
+package maxsat
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// A checkpoint is the serialized state of a problem, as written by Checkpoint.
+type checkpoint struct {
+	Constrs       [][]solver.PBConstr
+	VarInts       []string
+	Blocks        []int
+	BlockWeights  map[int]int
+	MaxWeight     int
+	Labels        map[string]string
+	Ctrls         map[int]int
+	Tags          map[int]string
+	Disabled      map[string]bool
+	CostBound     int
+	HasCostBound  bool
+	BoundHint     bool
+	DisabledSoft  map[int]int
+	CoreBound     int
+	Order         []int
+	Retractables  map[int][]solver.PBConstr
+	Capped        map[int]checkpointCapped
+	Enums         map[string][]string
+	Objectives    map[string]checkpointObjective
+	Linear        map[string]int
+	DecisionVars  map[string]bool
+	HasDecisions  bool // Whether decisions were restricted by SetDecisionVars, since gob does not tell empty maps from nil ones
+	WeightFuncs   map[int]checkpointWeightFunc
+	Opts          Options
+	Incumbent     []bool // Best model known so far, as returned by the solver, if any
+	IncumbentCost int
+	Solver        solver.State
+}
+
+// A checkpointObjective is the serialized version of a namedObjective.
+type checkpointObjective struct {
+	Lits    []Lit
+	Weights []int
+}
+
+// A checkpointCapped is the serialized version of a capped.
+type checkpointCapped struct {
+	Lits   []int
+	Weight int
+}
+
+// A checkpointWeightFunc is the serialized version of a weightFunc.
+type checkpointWeightFunc struct {
+	Base, Slope int
+}
+
+// Checkpoint writes the state of the problem to w, so that it can be restored later with Resume,
+// e.g to resume an optimization that was interrupted by the end of the process.
+// Along with the constraints, tags, bounds, hints, decision vars and weight functions of the problem, the state includes the clauses learned
+// by the solver and the activity of its vars, the best model found so far, either by Step or by
+// another solving method, and the lower bound given by SeedCores.
+// Problems made by Wrap cannot be checkpointed, since their constraints are not known: an error is returned for them.
+// An error is also returned if the state cannot be written to w.
+func (pb *Problem) Checkpoint(w io.Writer) error {
+	if pb.wrapUsed != nil {
+		return fmt.Errorf("cannot checkpoint a problem made by Wrap")
+	}
+	cp := checkpoint{
+		Constrs:      pb.constrs,
+		VarInts:      pb.varInts,
+		Blocks:       pb.blocks,
+		BlockWeights: pb.blockWeights,
+		MaxWeight:    pb.maxWeight,
+		Labels:       pb.labels,
+		Ctrls:        pb.ctrls,
+		Tags:         pb.tags,
+		Disabled:     pb.disabled,
+		CostBound:    pb.costBound,
+		HasCostBound: pb.hasCostBound,
+		BoundHint:    pb.boundHint,
+		DisabledSoft: pb.disabledSoft,
+		CoreBound:    pb.coreBound,
+		Order:        pb.order,
+		Retractables: pb.retractables,
+		Enums:        pb.enums,
+		Linear:       pb.linear,
+		DecisionVars: pb.decisionVars,
+		HasDecisions: pb.decisionVars != nil,
+		Opts:         pb.opts,
+		Solver:       pb.solver.State(),
+	}
+	if len(pb.capped) != 0 {
+		cp.Capped = make(map[int]checkpointCapped, len(pb.capped))
+		for i, c := range pb.capped {
+			cp.Capped[i] = checkpointCapped{Lits: c.lits, Weight: c.weight}
+		}
+	}
+	if len(pb.weightFuncs) != 0 {
+		cp.WeightFuncs = make(map[int]checkpointWeightFunc, len(pb.weightFuncs))
+		for i, f := range pb.weightFuncs {
+			cp.WeightFuncs[i] = checkpointWeightFunc{Base: f.base, Slope: f.slope}
+		}
+	}
+	if len(pb.objectives) != 0 {
+		cp.Objectives = make(map[string]checkpointObjective, len(pb.objectives))
+		for name, obj := range pb.objectives {
+			cp.Objectives[name] = checkpointObjective{Lits: obj.lits, Weights: obj.weights}
+		}
+	}
+	if pb.step != nil && pb.step.model != nil {
+		cp.Incumbent, cp.IncumbentCost = pb.step.model, pb.step.cost
+	} else if pb.lastModel != nil {
+		cp.Incumbent, cp.IncumbentCost = pb.lastModel, pb.cost(pb.lastModel)
+	}
+	cp.Solver.Lemmas = pb.stableLemmas(cp.Solver.Lemmas)
+	if err := gob.NewEncoder(w).Encode(cp); err != nil {
+		return fmt.Errorf("could not write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// stableLemmas returns the given lemmas, except those containing vars that are not part of the problem itself,
+// such as activation vars created by the solver, since such vars can mean something else once the solver is rebuilt.
+func (pb *Problem) stableLemmas(lemmas [][]int) [][]int {
+	stable := make(map[int]bool)
+	for v, name := range pb.varInts {
+		if name != "" {
+			stable[v+1] = true
+		}
+	}
+	for i, bl := range pb.blocks {
+		stable[bl] = true
+		for _, l := range pb.capped[i].lits {
+			stable[l] = true
+		}
+	}
+	for _, ctrl := range pb.ctrls {
+		stable[ctrl] = true
+	}
+	for act := range pb.retractables {
+		stable[act] = true
+	}
+	var res [][]int
+	for _, lemma := range lemmas {
+		ok := true
+		for _, lit := range lemma {
+			if lit < 0 {
+				lit = -lit
+			}
+			ok = ok && stable[lit]
+		}
+		if ok {
+			res = append(res, lemma)
+		}
+	}
+	return res
+}
+
+// Resume returns the problem whose state was written to r by Checkpoint.
+// If a model was found before the checkpoint, it is the last model of the problem, as used by Broken,
+// and the search for a better one can be resumed by calling Step, so that only models that improve on it are looked for.
+// Other solving methods start a new search, but the clauses learned before the checkpoint are still used.
+// An error is returned if the state cannot be read from r.
+func Resume(r io.Reader) (*Problem, error) {
+	var cp checkpoint
+	if err := gob.NewDecoder(r).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %w", err)
+	}
+	pb := &Problem{
+		intVars:      make(map[string]int),
+		varInts:      cp.VarInts,
+		blockWeights: cp.BlockWeights,
+		maxWeight:    cp.MaxWeight,
+		blocks:       cp.Blocks,
+		constrs:      cp.Constrs,
+		labels:       cp.Labels,
+		ctrls:        cp.Ctrls,
+		tags:         cp.Tags,
+		disabled:     cp.Disabled,
+		costBound:    cp.CostBound,
+		hasCostBound: cp.HasCostBound,
+		boundHint:    cp.BoundHint,
+		disabledSoft: cp.DisabledSoft,
+		coreBound:    cp.CoreBound,
+		order:        cp.Order,
+		retractables: cp.Retractables,
+		enums:        cp.Enums,
+		linear:       cp.Linear,
+		opts:         cp.Opts,
+	}
+	if pb.blockWeights == nil {
+		pb.blockWeights = make(map[int]int)
+	}
+	for i, name := range pb.varInts {
+		if name != "" {
+			pb.intVars[name] = i + 1
+		}
+	}
+	for i, c := range cp.Capped {
+		if pb.capped == nil {
+			pb.capped = make(map[int]capped)
+		}
+		pb.capped[i] = capped{lits: c.Lits, weight: c.Weight}
+	}
+	if cp.HasDecisions {
+		pb.decisionVars = make(map[string]bool, len(cp.DecisionVars))
+		for name := range cp.DecisionVars {
+			pb.decisionVars[name] = true
+		}
+	}
+	for i, f := range cp.WeightFuncs {
+		if pb.weightFuncs == nil {
+			pb.weightFuncs = make(map[int]weightFunc)
+		}
+		pb.weightFuncs[i] = weightFunc{base: f.Base, slope: f.Slope}
+	}
+	for name, obj := range cp.Objectives {
+		pb.DefineObjective(name, obj.Lits, obj.Weights)
+	}
+	pb.build()
+	if err := pb.solver.Restore(cp.Solver); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	if cp.Incumbent != nil {
+		model := make([]bool, len(pb.varInts))
+		copy(model, cp.Incumbent)
+		pb.lastModel = model
+		pb.step = &stepState{model: model, cost: cp.IncumbentCost, resumed: true}
+	}
+	return pb, nil
+}
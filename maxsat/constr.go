@@ -1,5 +1,13 @@
 package maxsat
 
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // A Lit is a potentially-negated boolean variable.
 type Lit struct {
 	Var     string
@@ -28,12 +36,52 @@ func (l Lit) Negation() Lit {
 	return Lit{Var: l.Var, Negated: !l.Negated}
 }
 
+// A Comparator is the relational operator of a constraint.
+type Comparator int
+
+const (
+	// GE means the weighted sum of the lits must be greater than or equal to the bound of the constraint.
+	GE Comparator = iota
+	// LE means the weighted sum of the lits must be lower than or equal to the bound of the constraint.
+	LE
+	// EQ means the weighted sum of the lits must be equal to the bound of the constraint.
+	EQ
+)
+
+// Hard is a special weight meaning a constraint is hard, i.e it must be satisfied, just like a weight of 0.
+// It is useful when weights are computed uniformly, some of them being mandatory, like the "top" weight in the WCNF format.
+// Hard constraints have no blocking literal and do not contribute to the cost of models, nor to MaxWeight.
+const Hard = math.MaxInt
+
 // A Constr is a weighted pseudo-boolean constraint.
 type Constr struct {
-	Lits    []Lit // The list of lits in the problem.
-	Coeffs  []int // The coefficients associated with each literals. If nil, all coeffs are supposed to be 1.
-	AtLeast int   // Minimal cardinality for the constr to be satisfied.
-	Weight  int   // The weight of the clause, or 0 for a hard clause.
+	Lits       []Lit      // The list of lits in the problem.
+	Coeffs     []int      // The coefficients associated with each literals. If nil, all coeffs are supposed to be 1.
+	AtLeast    int        // Minimal cardinality for the constr to be satisfied, or maximal or exact one, depending on Comparator.
+	Weight     int        // The weight of the clause, or 0 or Hard for a hard clause.
+	Comparator Comparator // How the weighted sum of the lits is compared to AtLeast. Defaults to GE.
+	Reified    string     // If not empty, the name of a var that is true iff the constraint is satisfied. The constraint is then not enforced, and its weight is ignored.
+	WeightCap  int        // If positive, the cost of the soft constraint is Weight for each unit its weighted sum misses AtLeast by, but never more than WeightCap. Ignored for EQ constraints.
+}
+
+// soft returns true iff c is a soft constraint, i.e iff its weight is neither 0 nor Hard.
+// Reified constraints are never soft.
+func (c Constr) soft() bool {
+	return c.Weight != 0 && c.Weight != Hard && c.Reified == ""
+}
+
+// trivial returns true iff c is always satisfied, i.e iff it is a non-reified GE constraint whose bound is 0 or less
+// and whose coefficients are all positive or null.
+func (c Constr) trivial() bool {
+	if c.Comparator != GE || c.Reified != "" || c.AtLeast > 0 {
+		return false
+	}
+	for _, coeff := range c.Coeffs {
+		if coeff < 0 {
+			return false
+		}
+	}
+	return true
 }
 
 // HardClause returns a propositional clause that must be satisfied.
@@ -65,3 +113,96 @@ func SoftPBConstr(lits []Lit, coeffs []int, atLeast int) Constr {
 func WeightedPBConstr(lits []Lit, coeffs []int, atLeast int, weight int) Constr {
 	return Constr{Lits: lits, Coeffs: coeffs, AtLeast: atLeast, Weight: weight}
 }
+
+// CanonicalKey returns a string representing the constraint, that can be used as a key for caching purposes.
+// Two constraints only differing by the order of their terms have the same key,
+// and constraints with nil coefficients have the same key as constraints with all coefficients set to 1.
+// The key is stable across runs.
+func (c Constr) CanonicalKey() string {
+	terms := make([]string, len(c.Lits))
+	for i, lit := range c.Lits {
+		coeff := 1
+		if c.Coeffs != nil {
+			coeff = c.Coeffs[i]
+		}
+		sign := ""
+		if lit.Negated {
+			sign = "~"
+		}
+		terms[i] = fmt.Sprintf("%s%s*%d", sign, strconv.Quote(lit.Var), coeff)
+	}
+	sort.Strings(terms)
+	op := ">="
+	switch c.Comparator {
+	case LE:
+		op = "<="
+	case EQ:
+		op = "="
+	}
+	key := fmt.Sprintf("%s %s %d w%d", strings.Join(terms, " "), op, c.AtLeast, c.Weight)
+	if c.Reified != "" {
+		key += " r" + strconv.Quote(c.Reified)
+	}
+	return key
+}
+
+// Reified returns a constraint stating that the var named name is true iff the weighted sum of lits is at least atLeast.
+// If coeffs is nil, all coeffs are supposed to be 1.
+// The constraint itself is not enforced, but name can be used in other constraints.
+func Reified(name string, lits []Lit, coeffs []int, atLeast int) Constr {
+	return Constr{Lits: lits, Coeffs: coeffs, AtLeast: atLeast, Reified: name}
+}
+
+// Neg returns the name denoting the negation of the var named name in AtLeastK, AtMostK and WeightedGE,
+// i.e name prefixed by "~", as in the WBO format.
+func Neg(name string) string {
+	return "~" + name
+}
+
+// namedLit returns the lit denoted by name, as used by AtLeastK, AtMostK and WeightedGE:
+// a name prefixed by "~" denotes the negation of the var with the rest of the name.
+func namedLit(name string) Lit {
+	if strings.HasPrefix(name, "~") {
+		return Not(name[1:])
+	}
+	return Var(name)
+}
+
+// AtLeastK returns a constraint stating that at least k of the given vars must be true.
+// A var can be negated with Neg, so that it counts when it is false.
+// weight is the weight of the constraint, or 0 or Hard for a hard constraint.
+func AtLeastK(k int, weight int, vars ...string) Constr {
+	lits := make([]Lit, len(vars))
+	for i, name := range vars {
+		lits[i] = namedLit(name)
+	}
+	return Constr{Lits: lits, AtLeast: k, Weight: weight}
+}
+
+// AtMostK returns a constraint stating that at most k of the given vars can be true.
+// A var can be negated with Neg, so that it counts when it is false.
+// weight is the weight of the constraint, or 0 or Hard for a hard constraint.
+func AtMostK(k int, weight int, vars ...string) Constr {
+	c := AtLeastK(k, weight, vars...)
+	c.Comparator = LE
+	return c
+}
+
+// WeightedGE returns a constraint stating that the sum of the coefficients of the true terms must be at least rhs.
+// Each key of terms is the name of a var, possibly negated with Neg, and its value is its coefficient.
+// Lits are sorted by name, so that the result does not depend on the iteration order of terms.
+// weight is the weight of the constraint, or 0 or Hard for a hard constraint.
+func WeightedGE(rhs int, weight int, terms map[string]int) Constr {
+	names := make([]string, 0, len(terms))
+	for name := range terms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lits := make([]Lit, len(names))
+	coeffs := make([]int, len(names))
+	for i, name := range names {
+		lits[i] = namedLit(name)
+		coeffs[i] = terms[name]
+	}
+	return Constr{Lits: lits, Coeffs: coeffs, AtLeast: rhs, Weight: weight}
+}
@@ -0,0 +1,97 @@
+package maxsat
+
+import "testing"
+
+func TestCanonicalKey(t *testing.T) {
+	c1 := WeightedPBConstr([]Lit{Var("a"), Not("b"), Var("c")}, []int{1, 2, 3}, 3, 4)
+	c2 := WeightedPBConstr([]Lit{Var("c"), Var("a"), Not("b")}, []int{3, 1, 2}, 3, 4)
+	if c1.CanonicalKey() != c2.CanonicalKey() {
+		t.Errorf("keys should be equal: %q and %q", c1.CanonicalKey(), c2.CanonicalKey())
+	}
+	different := []Constr{
+		WeightedPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, []int{1, 2, 3}, 3, 4),
+		WeightedPBConstr([]Lit{Var("a"), Not("b"), Var("c")}, []int{2, 1, 3}, 3, 4),
+		WeightedPBConstr([]Lit{Var("a"), Not("b"), Var("c")}, []int{1, 2, 3}, 2, 4),
+		WeightedPBConstr([]Lit{Var("a"), Not("b"), Var("c")}, []int{1, 2, 3}, 3, 1),
+		{Lits: []Lit{Var("a"), Not("b"), Var("c")}, Coeffs: []int{1, 2, 3}, AtLeast: 3, Weight: 4, Comparator: LE},
+	}
+	for _, c := range different {
+		if c.CanonicalKey() == c1.CanonicalKey() {
+			t.Errorf("keys should be different: %q", c.CanonicalKey())
+		}
+	}
+	c3 := HardClause(Var("a"), Not("b"))
+	c4 := HardPBConstr([]Lit{Not("b"), Var("a")}, []int{1, 1}, 1)
+	if c3.CanonicalKey() != c4.CanonicalKey() {
+		t.Errorf("keys should be equal: %q and %q", c3.CanonicalKey(), c4.CanonicalKey())
+	}
+}
+
+func TestHardWeight(t *testing.T) {
+	pb := New(
+		Constr{Lits: []Lit{Var("a"), Var("b")}, AtLeast: 1, Weight: Hard},
+		Constr{Lits: []Lit{Not("a")}, AtLeast: 1, Weight: Hard},
+		WeightedClause([]Lit{Not("b")}, 4),
+		WeightedClause([]Lit{Var("c")}, 2),
+	)
+	if w := pb.MaxWeight(); w != 6 {
+		t.Errorf("invalid max weight: expected 6, got %d", w)
+	}
+	model, cost := pb.Solve()
+	if model == nil || model["a"] || !model["b"] || cost != 4 {
+		t.Errorf("invalid solution: expected cost 4 with a false and b true, got %v with cost %d", model, cost)
+	}
+	if err := pb.AddConstr(Constr{Lits: []Lit{Not("c")}, AtLeast: 1, Weight: Hard}); err != nil {
+		t.Errorf("could not add hard constraint: %v", err)
+	}
+	if _, cost := pb.Solve(); cost != 6 {
+		t.Errorf("invalid cost after adding hard constraint: expected 6, got %d", cost)
+	}
+}
+
+func TestReified(t *testing.T) {
+	lits := []Lit{Var("a"), Var("b"), Var("c")}
+	pb := New(
+		Reified("big", lits, []int{3, 2, 1}, 5),
+		Constr{Lits: lits, AtLeast: 2, Comparator: EQ, Reified: "two"},
+		HardClause(Var("a")),
+		HardClause(Var("b")),
+		HardClause(Not("big"), Var("x")),
+		SoftClause(Not("x")),
+		SoftClause(Not("two")),
+	)
+	model, cost := pb.Solve()
+	if model == nil || !model["big"] || !model["x"] || !model["c"] || model["two"] || cost != 1 {
+		t.Errorf("invalid solution: got %v with cost %d", model, cost)
+	}
+	if err := pb.AddConstr(HardClause(Not("c"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	model, cost = pb.Solve()
+	if model == nil || !model["big"] || !model["two"] || cost != 2 {
+		t.Errorf("invalid solution once c is false: got %v with cost %d", model, cost)
+	}
+}
+
+func TestBuilders(t *testing.T) {
+	pb := New(
+		AtLeastK(2, Hard, "a", "b", Neg("c")),
+		AtMostK(1, 0, "a", "b"),
+		WeightedGE(4, 0, map[string]int{"b": 3, "c": 2, Neg("a"): 2}),
+		AtLeastK(1, 5, "a"),
+	)
+	model, cost := pb.Solve()
+	if model == nil {
+		t.Fatalf("expected a model")
+	}
+	if cost != 5 || model["a"] || !model["b"] || model["c"] {
+		t.Errorf("expected model with b only and cost 5, got %v with cost %d", model, cost)
+	}
+	c := WeightedGE(3, 2, map[string]int{"y": 1, Neg("x"): 2})
+	if key := c.CanonicalKey(); key != `"y"*1 ~"x"*2 >= 3 w2` {
+		t.Errorf("invalid constraint %v: key %s", c, key)
+	}
+	if c := AtMostK(2, 1, "x", Neg("y")); c.Comparator != LE || c.AtLeast != 2 || c.Lits[1] != Not("y") {
+		t.Errorf("invalid constraint %v", c)
+	}
+}
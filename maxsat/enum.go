@@ -0,0 +1,91 @@
+package maxsat
+
+import "fmt"
+
+// EnumVar returns the name of the boolean var that is true iff the finite-domain var with the given name,
+// as declared by AddEnum, takes the given value, i.e "name=value".
+func EnumVar(name, value string) string {
+	return fmt.Sprintf("%s=%s", name, value)
+}
+
+// AddEnum declares a finite-domain var with the given name, that takes exactly one of the values of domain.
+// It is encoded with one boolean var per value, named as returned by EnumVar, and a hard constraint stating
+// that exactly one of them is true. These boolean vars can then be used in any constraint.
+// An error is returned if a finite-domain var with the same name was already declared, or if domain
+// is empty or contains duplicate values.
+func (pb *Problem) AddEnum(name string, domain []string) error {
+	if _, ok := pb.enums[name]; ok {
+		return fmt.Errorf("cannot declare enum %q twice", name)
+	}
+	if len(domain) == 0 {
+		return fmt.Errorf("cannot declare enum %q with an empty domain", name)
+	}
+	seen := make(map[string]bool, len(domain))
+	lits := make([]Lit, len(domain))
+	for i, value := range domain {
+		if seen[value] {
+			return fmt.Errorf("cannot declare enum %q: duplicate value %q", name, value)
+		}
+		seen[value] = true
+		lits[i] = Var(EnumVar(name, value))
+	}
+	if err := pb.AddConstr(Constr{Lits: lits, AtLeast: 1, Comparator: EQ}); err != nil {
+		return err
+	}
+	if pb.enums == nil {
+		pb.enums = make(map[string][]string)
+	}
+	pb.enums[name] = append([]string(nil), domain...)
+	return nil
+}
+
+// AddAllDifferent adds hard constraints stating that the finite-domain vars with the given names,
+// as declared by AddEnum, all take distinct values.
+// For each value of their domain, a single cardinality constraint states at most one of the vars takes it,
+// so the encoding is linear in the number of vars, rather than quadratic.
+// An error is returned if one of the names was not declared by AddEnum, or if the vars do not share the same domain.
+func (pb *Problem) AddAllDifferent(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	domain, ok := pb.enums[names[0]]
+	if !ok {
+		return fmt.Errorf("%q is not an enum", names[0])
+	}
+	for _, name := range names[1:] {
+		dom, ok := pb.enums[name]
+		if !ok {
+			return fmt.Errorf("%q is not an enum", name)
+		}
+		if !sameDomain(domain, dom) {
+			return fmt.Errorf("enums %q and %q have different domains", names[0], name)
+		}
+	}
+	for _, value := range domain {
+		lits := make([]Lit, len(names))
+		for i, name := range names {
+			lits[i] = Var(EnumVar(name, value))
+		}
+		if err := pb.AddConstr(Constr{Lits: lits, AtLeast: 1, Comparator: LE}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sameDomain returns true iff both domains contain the same values, in any order.
+func sameDomain(d1, d2 []string) bool {
+	if len(d1) != len(d2) {
+		return false
+	}
+	values := make(map[string]bool, len(d1))
+	for _, value := range d1 {
+		values[value] = true
+	}
+	for _, value := range d2 {
+		if !values[value] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package maxsat
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// EquivalenceClasses returns the classes of vars that are equivalent under the hard constraints, i.e that have the same value
+// in every model of the hard constraints, e.g to find redundant vars in an encoding.
+// Anti-equivalences are reported too: a var that has the opposite value of the others in every model
+// appears in their class negated, i.e prefixed by "~", as returned by Neg. Within a class, vars are sorted by name,
+// and the first one is never negated; classes are sorted by their first var, and classes with a single var are not returned.
+// Vars whose value is the same in all models are equivalent to each other, so they all appear in the same class.
+// Soft constraints, and the bound set by SetCostUpperBound, if any, are ignored.
+// If the hard constraints cannot be satisfied, it returns nil.
+//
+// Candidate classes are first given by the vars that have the same value in a model, and refined by the models
+// that are found while checking whether each var is equivalent to the first one of its candidate class, with up to two calls
+// to the solver per var and per refinement. Calls are made under assumptions, so the clauses learned by the solver are kept.
+func (pb *Problem) EquivalenceClasses() [][]string {
+	defer pb.assume(nil)
+	pb.assume(nil) // Rebuilds the solver, if needed
+	pb.solver.ClearCostBound()
+	defer pb.restoreCostBound()
+	if pb.solver.Solve() != solver.Sat {
+		return nil
+	}
+	model := pb.solver.Model()
+	names := make([]string, 0, len(pb.intVars))
+	for name := range pb.intVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	class := make([]int, len(names)) // Lits that are true in the first model
+	for i, name := range names {
+		class[i] = pb.intVars[name]
+		if !model[class[i]-1] {
+			class[i] = -class[i]
+		}
+	}
+	classes := [][]int{class}
+	refine := func(model []bool) { // Splits candidate classes whose lits have different values in model
+		for i, class := range classes {
+			var same, other []int
+			for _, lit := range class {
+				if model[abs(lit)-1] == model[abs(class[0])-1] == (lit > 0 == (class[0] > 0)) {
+					same = append(same, lit)
+				} else {
+					other = append(other, lit)
+				}
+			}
+			classes[i] = same
+			if len(other) > 1 {
+				classes = append(classes, other)
+			}
+		}
+	}
+	for i := 0; i < len(classes); i++ {
+		for j := 1; j < len(classes[i]); {
+			if model := pb.distinguish(classes[i][0], classes[i][j]); model != nil {
+				refine(model)
+			} else {
+				j++
+			}
+		}
+	}
+	var res [][]string
+	for _, class := range classes {
+		if len(class) < 2 {
+			continue
+		}
+		sort.Slice(class, func(i, j int) bool { return pb.varInts[abs(class[i])-1] < pb.varInts[abs(class[j])-1] })
+		names := make([]string, len(class))
+		for i, lit := range class {
+			names[i] = pb.varInts[abs(lit)-1]
+			if (lit > 0) != (class[0] > 0) {
+				names[i] = Neg(names[i])
+			}
+		}
+		res = append(res, names)
+	}
+	sort.Slice(res, func(i, j int) bool { return strings.Compare(res[i][0], res[j][0]) < 0 })
+	return res
+}
+
+// distinguish returns a model of the hard constraints where the lits l1 and l2 have different values,
+// or nil if there is none, i.e if they are equivalent.
+func (pb *Problem) distinguish(l1, l2 int) []bool {
+	for _, lits := range [][2]int{{l1, -l2}, {-l1, l2}} {
+		if pb.assume(toLits(lits[:])) != solver.Unsat && pb.solver.Solve() == solver.Sat {
+			return pb.solver.Model()
+		}
+	}
+	return nil
+}
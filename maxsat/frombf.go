@@ -0,0 +1,56 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/crillab/gophersat/bf"
+	"github.com/crillab/gophersat/solver"
+)
+
+// FromBF returns a new problem whose hard constraints are the given hard formulas,
+// and whose soft constraints are the given weighted soft formulas.
+// Formulas are translated to CNF, and all the clauses of the ith soft formula are relaxed by a blocking literal
+// whose weight is the weight of the formula, so that the formula is broken iff its blocking literal is true;
+// its index is i in the result of Broken.
+// Models returned by the problem associate the names of the vars of the formulas with their bindings.
+// An error is returned if a formula is nil or if a soft formula has a weight that is not strictly positive.
+func FromBF(hard []bf.Formula, soft []bf.WeightedFormula) (*Problem, error) {
+	fs := make([]bf.Formula, 0, len(hard)+len(soft))
+	for i, f := range hard {
+		if f == nil {
+			return nil, fmt.Errorf("hard formula #%d is nil", i)
+		}
+		fs = append(fs, f)
+	}
+	for i, wf := range soft {
+		if wf.Formula == nil {
+			return nil, fmt.Errorf("soft formula #%d is nil", i)
+		}
+		if wf.Weight <= 0 {
+			return nil, fmt.Errorf("soft formula #%d has invalid weight %d", i, wf.Weight)
+		}
+		fs = append(fs, wf.Formula)
+	}
+	cnfs, vars, nbVars := bf.CNF(fs...)
+	var clauses [][]int
+	for _, cnf := range cnfs[:len(hard)] {
+		clauses = append(clauses, cnf...)
+	}
+	blocks := make([]solver.Lit, len(soft))
+	weights := make([]int, len(soft))
+	for i, cnf := range cnfs[len(hard):] {
+		bl := nbVars + i + 1
+		for _, clause := range cnf {
+			clauses = append(clauses, append(clause[:len(clause):len(clause)], bl))
+		}
+		blocks[i] = solver.IntToLit(int32(bl))
+		weights[i] = soft[i].Weight
+	}
+	prob := solver.ParseSliceNb(clauses, nbVars+len(soft))
+	prob.SetCostFunc(blocks, weights)
+	names := make(map[int]string, len(vars))
+	for name, v := range vars {
+		names[v] = name
+	}
+	return Wrap(prob, names), nil
+}
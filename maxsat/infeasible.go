@@ -0,0 +1,64 @@
+package maxsat
+
+import "github.com/crillab/gophersat/solver"
+
+// TriviallyInfeasible returns true iff the hard constraints of the problem were found to contradict each other
+// when the problem was built, i.e iff unit propagation alone shows they cannot be satisfied, e.g because
+// a hard unit clause and its negation are both present, or because a hard constraint can never be satisfied.
+// Solve then returns a nil model right away, and ContradictingConstrs tells which constraints contradict each other.
+// Contradictions that can only be found by searching are not detected, and neither are those involving constraints
+// added by AddConstr or AddRetractable, which are found by the next call to Solve as usual.
+func (pb *Problem) TriviallyInfeasible() bool {
+	return pb.contradiction != nil
+}
+
+// ContradictingConstrs returns the indices of hard constraints that contradict each other, in increasing order,
+// as found when building the problem, or nil if TriviallyInfeasible is false.
+// The indices are those of the constraints that were needed by unit propagation to derive the contradiction,
+// so the set is small, but not necessarily minimal.
+// Tagged constraints are not taken into account, since they can be disabled: tagging one of the returned constraints
+// checks the constraints again.
+func (pb *Problem) ContradictingConstrs() []int {
+	if pb.contradiction == nil {
+		return nil
+	}
+	res := make([]int, len(pb.contradiction))
+	copy(res, pb.contradiction)
+	return res
+}
+
+// checkContradiction looks for a contradiction among the hard constraints that are not tagged, through unit propagation,
+// and records it for TriviallyInfeasible and Solve.
+func (pb *Problem) checkContradiction() {
+	pb.contradiction = pb.contradictingConstrs()
+	pb.hardUnsat = pb.contradiction != nil
+}
+
+// contradictingConstrs returns the indices of the hard constraints that are not tagged from which unit propagation
+// derives a contradiction, in increasing order, or nil if it derives none.
+// Propagation is done by the solver, on the parts of the translations of the constraints, that are then mapped back
+// to the constraints they belong to.
+func (pb *Problem) contradictingConstrs() []int {
+	var parts []solver.PBConstr
+	var owners []int // For each part, the index of the constraint it belongs to
+	for i, cs := range pb.constrs {
+		if pb.blocks[i] != 0 || pb.ctrls[i] != 0 {
+			continue
+		}
+		for _, c := range cs {
+			parts = append(parts, c)
+			owners = append(owners, i)
+		}
+	}
+	conflict := solver.TopLevelConflict(parts)
+	if conflict == nil {
+		return nil
+	}
+	var res []int
+	for _, j := range conflict { // Parts of a constraint are contiguous, so indices are sorted and duplicates adjacent
+		if i := owners[j]; len(res) == 0 || res[len(res)-1] != i {
+			res = append(res, i)
+		}
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package maxsat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A jsonResult is the result of a call to SolveJSON, as written to its writer.
+type jsonResult struct {
+	Status       string          `json:"status"`                  // "OPTIMUM FOUND" or "UNSATISFIABLE", as in the MaxSAT evaluation output
+	Cost         int             `json:"cost"`                    // Optimal cost, or -1 if the problem is not satisfiable
+	Model        map[string]bool `json:"model"`                   // Value of each named var in the optimal model, or null
+	Broken       []int           `json:"broken"`                  // Indices of the soft constraints violated by the optimal model
+	BrokenLabels []string        `json:"broken_labels,omitempty"` // Labels of the violated soft constraints, if a BlockPrefix was given
+	Stats        jsonStats       `json:"stats"`
+}
+
+// A jsonStats holds the statistics of the solver written by SolveJSON.
+type jsonStats struct {
+	Conflicts   int   `json:"conflicts"`
+	Decisions   int   `json:"decisions"`
+	Restarts    int   `json:"restarts"`
+	Learned     int   `json:"learned"`
+	SolveTimeMS int64 `json:"solve_time_ms"`
+}
+
+// SolveJSON is like Solve, but it writes the result to w as a JSON object, e.g for programs written in other languages
+// that call gophersat as a subprocess. The object has the following fields:
+// "status", either "OPTIMUM FOUND" or "UNSATISFIABLE"; "cost", the optimal cost, or -1;
+// "model", an object giving the value of each var of the problem in the optimal model, or null;
+// "broken", the indices of the soft constraints violated by the model, in increasing order, as returned by Broken;
+// "broken_labels", only present if a BlockPrefix was given to NewWithOptions, the labels of the violated constraints,
+// as in the output of WriteOPB; and "stats", the number of "conflicts", "decisions", "restarts" and "learned" clauses
+// of the underlying solver so far, along with the total time spent solving, in milliseconds, as "solve_time_ms".
+// An error is returned if the result cannot be written to w.
+func (pb *Problem) SolveJSON(w io.Writer) error {
+	model, cost := pb.Solve()
+	res := jsonResult{Status: "OPTIMUM FOUND", Cost: cost, Model: model, Broken: []int{}}
+	if model == nil {
+		res.Status = "UNSATISFIABLE"
+	} else if broken := pb.Broken(); broken != nil {
+		res.Broken = broken
+	}
+	if pb.opts.BlockPrefix != "" {
+		for _, i := range res.Broken {
+			res.BrokenLabels = append(res.BrokenLabels, pb.label(pb.blocks[i]))
+		}
+	}
+	st := pb.solver.Stats
+	res.Stats = jsonStats{
+		Conflicts:   st.NbConflicts,
+		Decisions:   st.NbDecisions,
+		Restarts:    st.NbRestarts,
+		Learned:     st.NbLearned,
+		SolveTimeMS: pb.solver.SolveDuration().Milliseconds(),
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		return fmt.Errorf("could not write JSON output: %v", err)
+	}
+	return nil
+}
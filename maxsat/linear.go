@@ -0,0 +1,61 @@
+package maxsat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SetLinearObjective replaces the cost function of the problem, i.e the sum of the weights of the violated
+// soft constraints, by the given linear objective: the cost of a model is then the sum of the coefficients
+// of the terms that are true in it, so that Solve minimizes this sum subject to the hard constraints,
+// making the problem a general 0/1 integer linear minimization problem.
+// Each key of terms is the name of a var, whose coefficient applies when it is true,
+// or the name of a var prefixed by "~", whose coefficient applies when it is false, as in the WBO format.
+// Coefficients must be positive, or SetLinearObjective panics; terms with a coefficient of 0 are ignored,
+// and so are the vars that do not appear in the problem yet. A negative coefficient c for x can be expressed
+// as the coefficient -c for "~x", the cost then being shifted by -c.
+// Soft constraints are still part of the problem, and Broken, CostBreakdown, SolveMax and MaxWeight still refer to them,
+// but they do not impact the cost anymore. The lower bounds given by SeedCores and the hint given
+// by SetUpperBoundHint are ignored, since they are about soft constraints.
+// If terms is nil, the cost function is given by the soft constraints again.
+func (pb *Problem) SetLinearObjective(terms map[string]int) {
+	var linear map[string]int
+	if terms != nil {
+		linear = make(map[string]int, len(terms))
+		for name, coeff := range terms {
+			if coeff < 0 {
+				panic(fmt.Sprintf("cannot set linear objective: term %q has a negative coefficient %d", name, coeff))
+			}
+			linear[name] = coeff
+		}
+	}
+	pb.linear = linear
+	pb.updateCostFunc()
+}
+
+// linearObjective returns the lits and weights of the linear objective set by SetLinearObjective,
+// sorted by var id, ignoring the terms with a null coefficient and the vars that do not appear in the problem.
+func (pb *Problem) linearObjective() (lits, weights []int) {
+	for name, coeff := range pb.linear {
+		negated := strings.HasPrefix(name, "~")
+		v, ok := pb.intVars[strings.TrimPrefix(name, "~")]
+		if !ok || coeff == 0 {
+			continue
+		}
+		if negated {
+			v = -v
+		}
+		lits = append(lits, v)
+	}
+	sort.Slice(lits, func(i, j int) bool { return abs(lits[i]) < abs(lits[j]) || (lits[i] == -lits[j] && lits[i] > 0) })
+	weights = make([]int, len(lits))
+	for i, lit := range lits {
+		name := pb.varInts[abs(lit)-1]
+		if lit < 0 {
+			name = "~" + name
+		}
+		weights[i] = pb.linear[name]
+	}
+	return lits, weights
+}
@@ -0,0 +1,174 @@
+package maxsat
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// Merge returns a new problem whose constraints are those of a, followed by those of b:
+// the index of the ith constraint of b is i plus the number of constraints of a, e.g in the result of Broken.
+// Vars with the same name in both problems are unified, while auxiliary vars, such as blocking literals,
+// are kept distinct. Soft constraints keep their weights, and tags, retractable constraints that were not retracted yet,
+// objectives registered by DefineObjective, the linear objective set by SetLinearObjective and enums declared by AddEnum are carried over,
+// along with the options given to NewWithOptions for a.
+// Bounds on the cost and models found for a and b are not.
+// An error is returned if one of the problems was made by Wrap, since its constraints are not known,
+// or if both problems are inconsistent: a var with a different label in each problem, a tag that is disabled
+// in only one of them, an objective or an enum with the same name but different definitions, or different linear objectives.
+func Merge(a, b *Problem) (*Problem, error) {
+	if a.wrapUsed != nil || b.wrapUsed != nil {
+		return nil, fmt.Errorf("cannot merge a problem made by Wrap")
+	}
+	pb := &Problem{intVars: make(map[string]int), blockWeights: make(map[int]int), opts: a.opts}
+	for _, src := range []*Problem{a, b} {
+		if err := pb.mergeFrom(src); err != nil {
+			return nil, err
+		}
+	}
+	pb.build()
+	pb.checkContradiction()
+	return pb, nil
+}
+
+// mergeFrom appends the constraints of src to pb, as described in Merge.
+func (pb *Problem) mergeFrom(src *Problem) error {
+	for name, label := range src.labels {
+		if prev, ok := pb.labels[name]; ok && prev != label {
+			return fmt.Errorf("cannot merge problems: var %q has labels %q and %q", name, prev, label)
+		}
+	}
+	for _, tag := range src.tags {
+		if pb.hasTag(tag) && pb.disabled[tag] != src.disabled[tag] {
+			return fmt.Errorf("cannot merge problems: tag %q is disabled in only one of them", tag)
+		}
+	}
+	for name, obj := range src.objectives {
+		if prev, ok := pb.objectives[name]; ok && !reflect.DeepEqual(prev, obj) {
+			return fmt.Errorf("cannot merge problems: objective %q has different definitions", name)
+		}
+	}
+	if pb.linear != nil && src.linear != nil && !reflect.DeepEqual(pb.linear, src.linear) {
+		return fmt.Errorf("cannot merge problems: they have different linear objectives")
+	}
+	for name, domain := range src.enums {
+		if prev, ok := pb.enums[name]; ok && !sameDomain(prev, domain) {
+			return fmt.Errorf("cannot merge problems: enum %q has different domains", name)
+		}
+	}
+	ids := make(map[int]int) // For each var of src, its id in pb
+	id := func(v int) int {
+		if res, ok := ids[v]; ok {
+			return res
+		}
+		if name := src.varInts[v-1]; name != "" {
+			ids[v] = pb.intLits([]Lit{Var(name)})[0]
+		} else {
+			pb.varInts = append(pb.varInts, "")
+			ids[v] = len(pb.varInts)
+		}
+		return ids[v]
+	}
+	remap := func(cs []solver.PBConstr) []solver.PBConstr {
+		res := make([]solver.PBConstr, len(cs))
+		for i, c := range cs {
+			c = copyPBConstr(c)
+			for j, lit := range c.Lits {
+				if lit < 0 {
+					c.Lits[j] = -id(-lit)
+				} else {
+					c.Lits[j] = id(lit)
+				}
+			}
+			res[i] = c
+		}
+		return res
+	}
+	offset := len(pb.constrs)
+	for i, cs := range src.constrs {
+		pb.constrs = append(pb.constrs, remap(cs))
+		bl := src.blocks[i]
+		if bl == 0 {
+			pb.blocks = append(pb.blocks, 0)
+			continue
+		}
+		pb.blocks = append(pb.blocks, id(bl))
+		pb.blockWeights[id(bl)] = src.blockWeights[bl]
+		if c, ok := src.capped[i]; ok {
+			lits := make([]int, len(c.lits))
+			for j, l := range c.lits {
+				lits[j] = id(l)
+				pb.blockWeights[lits[j]] = src.blockWeights[l]
+			}
+			if pb.capped == nil {
+				pb.capped = make(map[int]capped)
+			}
+			pb.capped[offset+i] = capped{lits: lits, weight: c.weight}
+		}
+		if w, ok := src.disabledSoft[i]; ok {
+			if pb.disabledSoft == nil {
+				pb.disabledSoft = make(map[int]int)
+			}
+			pb.disabledSoft[offset+i] = w
+		}
+	}
+	pb.maxWeight += src.maxWeight
+	for i, ctrl := range src.ctrls {
+		if pb.ctrls == nil {
+			pb.ctrls = make(map[int]int)
+			pb.tags = make(map[int]string)
+		}
+		pb.ctrls[offset+i] = id(ctrl)
+		pb.tags[offset+i] = src.tags[i]
+	}
+	for tag := range src.disabled {
+		if pb.disabled == nil {
+			pb.disabled = make(map[string]bool)
+		}
+		pb.disabled[tag] = true
+	}
+	for act, cs := range src.retractables {
+		if pb.retractables == nil {
+			pb.retractables = make(map[int][]solver.PBConstr)
+		}
+		pb.retractables[id(act)] = remap(cs)
+	}
+	for name, label := range src.labels {
+		if pb.labels == nil {
+			pb.labels = make(map[string]string)
+		}
+		pb.labels[name] = label
+	}
+	for name, obj := range src.objectives {
+		if pb.objectives == nil {
+			pb.objectives = make(map[string]namedObjective)
+		}
+		pb.objectives[name] = obj
+	}
+	for name, domain := range src.enums {
+		if pb.enums == nil {
+			pb.enums = make(map[string][]string)
+		}
+		pb.enums[name] = domain
+	}
+	if src.linear != nil {
+		pb.linear = src.linear
+	}
+	for _, name := range src.varInts { // Keep vars that only appear in the constraints of src that are trivially satisfied
+		if name != "" {
+			pb.intLits([]Lit{Var(name)})
+		}
+	}
+	return nil
+}
+
+// hasTag returns true iff at least one constraint of pb has the given tag.
+func (pb *Problem) hasTag(tag string) bool {
+	for _, t := range pb.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
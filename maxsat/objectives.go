@@ -0,0 +1,60 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// A namedObjective is an alternative cost function registered by DefineObjective.
+type namedObjective struct {
+	lits    []Lit
+	weights []int // nil if all weights are 1
+}
+
+// DefineObjective registers an alternative cost function with the given name, to be minimized by SolveObjective.
+// The cost of a model w.r.t the objective is the sum of the weights of its lits that are true in the model.
+// If weights is nil, all weights are 1; otherwise, they must be positive and there must be as many weights as lits,
+// or DefineObjective panics.
+// Defining an objective with the name of an existing one replaces it.
+func (pb *Problem) DefineObjective(name string, lits []Lit, weights []int) {
+	if weights != nil && len(weights) != len(lits) {
+		panic(fmt.Sprintf("cannot define objective %q with %d lits and %d weights", name, len(lits), len(weights)))
+	}
+	if pb.objectives == nil {
+		pb.objectives = make(map[string]namedObjective)
+	}
+	obj := namedObjective{lits: append([]Lit(nil), lits...)}
+	if weights != nil {
+		obj.weights = append([]int(nil), weights...)
+	}
+	pb.objectives[name] = obj
+}
+
+// SolveObjective returns a model of the hard constraints minimizing the objective with the given name,
+// its cost w.r.t that objective, and the indices of the soft constraints it violates, sorted in increasing order.
+// Soft constraints, and the bound set by SetCostUpperBound, if any, are ignored while minimizing,
+// and lits whose var does not appear in the problem are ignored, since they can be made false at no cost.
+// The underlying solver is reused, so all objectives share the clauses it learned.
+// If the hard constraints cannot be satisfied, it returns nil, -1 and nil.
+// It panics if no objective with such a name was defined.
+func (pb *Problem) SolveObjective(name string) (Model, int, []int) {
+	obj, ok := pb.objectives[name]
+	if !ok {
+		panic(fmt.Sprintf("no objective named %q", name))
+	}
+	lits, weights := pb.objective(obj.lits, obj.weights)
+	if pb.assume(nil) == solver.Unsat { // Also rebuilds the solver if needed
+		pb.lastModel = nil
+		return nil, -1, nil
+	}
+	defer pb.updateCostFunc()
+	pb.solver.SetCostFunc(toLits(lits), weights)
+	cost := pb.solver.Minimize()
+	if cost == -1 {
+		pb.lastModel = nil
+		return nil, -1, nil
+	}
+	pb.lastModel = pb.solver.Model()
+	return pb.decode(pb.lastModel), cost, pb.broken(pb.lastModel)
+}
@@ -0,0 +1,361 @@
+package maxsat
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SetVarLabel associates a custom label with the given variable.
+// Labels are only used to make the outputs of WriteOPB and WriteWCNF human-readable:
+// they appear in comments, next to the numeric id of the variable.
+// By default, the label of a variable is its name.
+// If the variable does not appear in the problem, nothing happens.
+func (pb *Problem) SetVarLabel(name, label string) {
+	if _, ok := pb.intVars[name]; !ok {
+		return
+	}
+	if pb.labels == nil {
+		pb.labels = make(map[string]string)
+	}
+	pb.labels[name] = label
+}
+
+// label returns the label of the variable with the given numeric id.
+// Blocking literals are labelled "soft_<index>", where index is the index of the associated constraint,
+// and other auxiliary vars have no label, unless other prefixes were given to NewWithOptions.
+func (pb *Problem) label(v int) string {
+	name := pb.varInts[v-1]
+	if name == "" {
+		for i, bl := range pb.blocks {
+			if bl == v {
+				prefix := pb.opts.BlockPrefix
+				if prefix == "" {
+					prefix = "soft_"
+				}
+				return fmt.Sprintf("%s%d", prefix, i)
+			}
+		}
+		if pb.opts.AuxPrefix != "" {
+			return fmt.Sprintf("%s%d", pb.opts.AuxPrefix, v)
+		}
+	}
+	if label, ok := pb.labels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// costTerms returns the lits and weights of the cost function, as written by WriteOPB:
+// the blocking literals of all soft constraints, or the linear objective set by SetLinearObjective, if any.
+func (pb *Problem) costTerms() (lits, weights []int) {
+	if pb.linear != nil {
+		return pb.linearObjective()
+	}
+	for i, bl := range pb.blocks {
+		if bl != 0 {
+			for _, l := range pb.softLits(i) {
+				lits = append(lits, l)
+				weights = append(weights, pb.blockWeights[l])
+			}
+		}
+	}
+	return lits, weights
+}
+
+// pbTerms returns the OPB representation of the given lits and weights.
+func pbTerms(lits, weights []int) string {
+	terms := make([]string, len(lits))
+	for i, val := range lits {
+		weight := 1
+		if weights != nil {
+			weight = weights[i]
+		}
+		sign := ""
+		if val < 0 {
+			val = -val
+			sign = "~"
+		}
+		terms[i] = fmt.Sprintf("%d %sx%d", weight, sign, val)
+	}
+	return strings.Join(terms, " +")
+}
+
+// WriteOPB writes the problem to w in the OPB format.
+// Soft constraints are written with their blocking literal, and the cost function is the weighted sum
+// of all blocking literals, or the linear objective set by SetLinearObjective, if any.
+// The label of each variable is written as a comment line, such as "* a=x1".
+func (pb *Problem) WriteOPB(w io.Writer) error {
+	var b strings.Builder
+	nbConstrs := 0
+	for _, cs := range pb.constrs {
+		nbConstrs += len(cs)
+	}
+	fmt.Fprintf(&b, "* #variable= %d #constraint= %d\n", len(pb.varInts), nbConstrs)
+	for v := 1; v <= len(pb.varInts); v++ {
+		if label := pb.label(v); label != "" { // Vars created by the solver itself have no label
+			fmt.Fprintf(&b, "* %s=x%d\n", label, v)
+		}
+	}
+	if minLits, minWeights := pb.costTerms(); minLits != nil {
+		fmt.Fprintf(&b, "min: %s ;\n", pbTerms(minLits, minWeights))
+	}
+	for _, cs := range pb.constrs {
+		for _, c := range cs {
+			fmt.Fprintf(&b, "%s >= %d ;\n", pbTerms(c.Lits, c.Weights), c.AtLeast)
+		}
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write OPB output: %v", err)
+	}
+	return nil
+}
+
+// WriteWCNF writes the problem to w in the WCNF format.
+// Hard clauses are given a weight strictly greater than the sum of the weights of all soft clauses.
+// Constraints that are always satisfied are not written.
+// The label of each variable is written as a comment line, such as "c a=1".
+// Blocking literals do not appear in the output, but their ids are not reused,
+// so the numeric ids are the same as in the output of WriteOPB.
+// An error is returned if one of the constraints is not a clause, since it cannot be expressed in WCNF.
+func (pb *Problem) WriteWCNF(w io.Writer) error {
+	var clauses []string
+	top := pb.maxWeight + 1
+	for i, cs := range pb.constrs {
+		for _, c := range cs {
+			if c.AtLeast <= 0 {
+				continue
+			}
+			if c.AtLeast != 1 {
+				return fmt.Errorf("could not write WCNF output: constraint #%d is not a clause", i)
+			}
+			weight := top
+			if bl := pb.blocks[i]; bl != 0 {
+				weight = pb.blockWeights[bl]
+			}
+			terms := make([]string, 0, len(c.Lits)+2)
+			terms = append(terms, fmt.Sprintf("%d", weight))
+			for _, lit := range c.Lits {
+				if lit != pb.blocks[i] { // Blocking lits are positive, and never 0
+					terms = append(terms, fmt.Sprintf("%d", lit))
+				}
+			}
+			clauses = append(clauses, strings.Join(append(terms, "0"), " "))
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "p wcnf %d %d %d\n", len(pb.varInts), len(clauses), top)
+	for v, name := range pb.varInts {
+		if name != "" {
+			fmt.Fprintf(&b, "c %s=%d\n", pb.label(v+1), v+1)
+		}
+	}
+	for _, clause := range clauses {
+		fmt.Fprintln(&b, clause)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write WCNF output: %v", err)
+	}
+	return nil
+}
+
+// WriteSMTLIB writes the problem to w in the SMT-LIB 2 format, e.g to cross-check its optimum with an optimizing SMT solver.
+// Each var is declared as an integer that is either 0 or 1, and each constraint is written as a linear assertion
+// over these integers, where a negated lit x is written (- 1 x). As in WriteOPB, soft constraints are written with
+// their blocking literal, and the objective, i.e the weighted sum of all blocking literals, or the linear objective
+// set by SetLinearObjective, if any, is given to a minimize command, as supported by z3 and OptiMathSAT.
+// Vars are named after their label, or "x<id>" for auxiliary vars without one, as SMT-LIB symbols:
+// names that are not valid simple symbols are quoted, and invalid chars are replaced by "_".
+// An error is returned for problems made by Wrap, since their constraints are not known, or if the problem cannot be written to w.
+func (pb *Problem) WriteSMTLIB(w io.Writer) error {
+	if pb.wrapUsed != nil {
+		return fmt.Errorf("cannot write a problem made by Wrap in the SMT-LIB format")
+	}
+	symbols := make([]string, len(pb.varInts)+1) // Symbol of each var, by id
+	used := make(map[string]bool)
+	var b strings.Builder
+	fmt.Fprintf(&b, "(set-logic QF_LIA)\n")
+	for v := 1; v <= len(pb.varInts); v++ {
+		label := pb.label(v)
+		if label == "" {
+			label = fmt.Sprintf("x%d", v)
+		}
+		sym := smtSymbol(label)
+		if used[sym] {
+			sym = smtSymbol(fmt.Sprintf("%s_%d", label, v))
+		}
+		used[sym] = true
+		symbols[v] = sym
+		fmt.Fprintf(&b, "(declare-fun %s () Int)\n(assert (and (>= %s 0) (<= %s 1)))\n", sym, sym, sym)
+	}
+	for _, cs := range pb.constrs {
+		for _, c := range cs {
+			fmt.Fprintf(&b, "(assert (>= %s %d))\n", smtTerms(c.Lits, c.Weights, symbols), c.AtLeast)
+		}
+	}
+	if minLits, minWeights := pb.costTerms(); minLits != nil {
+		fmt.Fprintf(&b, "(minimize %s)\n", smtTerms(minLits, minWeights, symbols))
+	}
+	fmt.Fprintf(&b, "(check-sat)\n(get-model)\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write SMT-LIB output: %v", err)
+	}
+	return nil
+}
+
+// smtTerms returns the SMT-LIB representation of the weighted sum of the given lits, whose vars are named after symbols.
+func smtTerms(lits, weights []int, symbols []string) string {
+	terms := make([]string, len(lits))
+	for i, lit := range lits {
+		term := symbols[abs(lit)]
+		if lit < 0 {
+			term = fmt.Sprintf("(- 1 %s)", term)
+		}
+		if weights != nil && weights[i] != 1 {
+			if weights[i] < 0 {
+				term = fmt.Sprintf("(* (- %d) %s)", -weights[i], term)
+			} else {
+				term = fmt.Sprintf("(* %d %s)", weights[i], term)
+			}
+		}
+		terms[i] = term
+	}
+	switch len(terms) {
+	case 0:
+		return "0"
+	case 1:
+		return terms[0]
+	default:
+		return fmt.Sprintf("(+ %s)", strings.Join(terms, " "))
+	}
+}
+
+// smtSymbol returns name as a valid SMT-LIB symbol: name itself if it is a valid simple symbol,
+// or name between pipes otherwise, where pipes and backslashes, which cannot appear in quoted symbols, are replaced by "_".
+func smtSymbol(name string) string {
+	simple := name != "" && (name[0] < '0' || name[0] > '9')
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("~!@$%^&*_-+=<>.?/", r)) {
+			simple = false
+		}
+	}
+	if simple {
+		return name
+	}
+	return "|" + strings.NewReplacer("|", "_", "\\", "_").Replace(name) + "|"
+}
+
+// WriteLP writes the problem to w in the CPLEX LP format, e.g to cross-check its optimum with a MILP solver
+// such as CPLEX or Gurobi. As in WriteOPB, soft constraints are written with their blocking literal, and the objective,
+// i.e the weighted sum of all blocking literals, or the linear objective set by SetLinearObjective, if any,
+// is given in the Minimize section. Each constraint is written as a linear inequality in the Subject To section,
+// where a negated lit x is written 1 - x, the constant being moved to the right-hand side,
+// and all vars are declared in the Binary section. Negated lits in the objective give a constant offset,
+// that is written as a comment line, such as "\ offset: 3", since not all solvers support constants in the objective.
+// Vars are named after their label, or "x<id>" for auxiliary vars without one, where chars that are not allowed in LP names
+// are replaced by "_", and names that could be mistaken for a number are prefixed by "x_".
+// An error is returned for problems made by Wrap, since their constraints are not known, or if the problem cannot be written to w.
+func (pb *Problem) WriteLP(w io.Writer) error {
+	if pb.wrapUsed != nil {
+		return fmt.Errorf("cannot write a problem made by Wrap in the LP format")
+	}
+	names := make([]string, len(pb.varInts)+1) // LP name of each var, by id
+	used := make(map[string]bool)
+	for v := 1; v <= len(pb.varInts); v++ {
+		label := pb.label(v)
+		if label == "" {
+			label = fmt.Sprintf("x%d", v)
+		}
+		name := lpName(label)
+		if used[name] {
+			name = lpName(fmt.Sprintf("%s_%d", label, v))
+		}
+		used[name] = true
+		names[v] = name
+	}
+	var b strings.Builder
+	minLits, minWeights := pb.costTerms()
+	terms, offset := lpTerms(minLits, minWeights, names)
+	if terms == "" {
+		terms = "0"
+	}
+	fmt.Fprintf(&b, "Minimize\n obj: %s\n", terms)
+	if offset != 0 {
+		fmt.Fprintf(&b, "\\ offset: %d\n", offset)
+	}
+	fmt.Fprintf(&b, "Subject To\n")
+	nb := 0
+	for i, cs := range pb.constrs {
+		for _, c := range cs {
+			terms, offset := lpTerms(c.Lits, c.Weights, names)
+			if terms == "" { // Nothing to constrain: the constraint is either always or never satisfied
+				if c.AtLeast <= 0 {
+					continue
+				}
+				return fmt.Errorf("cannot write constraint #%d in the LP format: it has no lits and cannot be satisfied", i)
+			}
+			nb++
+			fmt.Fprintf(&b, " c%d: %s >= %d\n", nb, terms, c.AtLeast-offset)
+		}
+	}
+	fmt.Fprintf(&b, "Binary\n")
+	for v := 1; v <= len(pb.varInts); v++ {
+		fmt.Fprintf(&b, " %s\n", names[v])
+	}
+	fmt.Fprintf(&b, "End\n")
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write LP output: %v", err)
+	}
+	return nil
+}
+
+// lpTerms returns the LP representation of the weighted sum of the given lits, whose vars are named after names,
+// along with the constant offset given by the negated lits, that must be added to the sum.
+// Terms are written on several lines, so that lines are not too long for LP readers.
+func lpTerms(lits, weights []int, names []string) (string, int) {
+	var b strings.Builder
+	offset := 0
+	for i, lit := range lits {
+		weight := 1
+		if weights != nil {
+			weight = weights[i]
+		}
+		if weight == 0 {
+			continue
+		}
+		if lit < 0 { // w * ~x = w - w * x
+			offset += weight
+			weight = -weight
+			lit = -lit
+		}
+		if b.Len() > 0 && i%10 == 0 {
+			b.WriteString("\n  ")
+		}
+		switch {
+		case b.Len() == 0 && weight < 0:
+			fmt.Fprintf(&b, "- %d %s", -weight, names[lit])
+		case b.Len() == 0:
+			fmt.Fprintf(&b, "%d %s", weight, names[lit])
+		case weight < 0:
+			fmt.Fprintf(&b, " - %d %s", -weight, names[lit])
+		default:
+			fmt.Fprintf(&b, " + %d %s", weight, names[lit])
+		}
+	}
+	return b.String(), offset
+}
+
+// lpName returns name as a valid LP name: chars that are not allowed are replaced by "_",
+// and names starting with a digit, a period or an "e", that could be mistaken for a number, are prefixed by "x_".
+func lpName(name string) string {
+	res := []rune(name)
+	for i, r := range res {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!\"#$%&()/,.;?@_`'{}|~", r)) {
+			res[i] = '_'
+		}
+	}
+	if len(res) == 0 || strings.ContainsRune("0123456789.eE", res[0]) {
+		return "x_" + string(res)
+	}
+	return string(res)
+}
@@ -0,0 +1,127 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// A ParetoPoint is a non-dominated solution of a problem with two objectives.
+type ParetoPoint struct {
+	CostA int   // Cost of Model w.r.t the first objective
+	CostB int   // Cost of Model w.r.t the second objective
+	Model Model // A model with such costs
+}
+
+// ParetoFront returns the Pareto front of the problem for the two given objectives, i.e all pairs of costs (costA, costB)
+// such that there is a model with such costs, but no model whose costs are both lower or equal, with at least one of them strictly lower.
+// Each pair comes with a representative model.
+// The cost of a model w.r.t an objective is the sum of the weights of the objective's lits that are true in the model.
+// If weights are nil, all weights are supposed to be 1; otherwise, they must be positive and there must be as many weights as lits.
+// Lits whose var does not appear in the problem are ignored, since they can be made false at no cost.
+// Only hard constraints are taken into account: the cost function of the problem is ignored.
+// Points are sorted by increasing costA, and thus by decreasing costB.
+// If the hard constraints cannot be satisfied, it returns nil.
+//
+// Points are found by minimizing the first objective, then minimizing the second one while the first one is bounded
+// by its optimal cost, and starting again while requiring the second objective to be strictly lower than its last cost,
+// until no model can be found. Bounds are only enforced through assumptions, so clauses learned during the search are kept.
+func (pb *Problem) ParetoFront(objA, objB []Lit, weightsA, weightsB []int) []ParetoPoint {
+	litsA, wA := pb.objective(objA, weightsA)
+	litsB, wB := pb.objective(objB, weightsB)
+	costA, costB := toLits(litsA), toLits(litsB)
+	if pb.assume(nil) == solver.Unsat { // Also rebuilds the solver if needed
+		return nil
+	}
+	defer pb.updateCostFunc()
+	var acts []int
+	defer func() { // Bounds are not needed anymore: disable them forever
+		pb.assume(nil)
+		for _, act := range acts {
+			pb.solver.AppendClause(solver.NewClause([]solver.Lit{solver.IntToLit(int32(-act))}))
+		}
+	}()
+	var res []ParetoPoint
+	var boundB []solver.Lit // Bound on the second objective, if any
+	for {
+		pb.solver.SetCostFunc(costA, wA)
+		if pb.assume(boundB) == solver.Unsat {
+			break
+		}
+		a := pb.solver.Minimize()
+		if a == -1 {
+			break
+		}
+		boundA := boundB
+		if act := pb.boundObjective(litsA, wA, a); act != 0 {
+			acts = append(acts, act)
+			boundA = append(boundA[:len(boundA):len(boundA)], solver.IntToLit(int32(act)))
+		}
+		pb.solver.SetCostFunc(costB, wB)
+		pb.assume(boundA)
+		b := pb.solver.Minimize() // Cannot be -1, the last model still satisfies all constraints
+		res = append(res, ParetoPoint{CostA: a, CostB: b, Model: pb.decode(pb.solver.Model())})
+		if b == 0 {
+			break
+		}
+		act := pb.boundObjective(litsB, wB, b-1)
+		acts = append(acts, act)
+		boundB = []solver.Lit{solver.IntToLit(int32(act))}
+	}
+	return res
+}
+
+// objective returns the integer counterparts of the given lits, along with their weights.
+// Lits whose var is unknown are ignored.
+func (pb *Problem) objective(lits []Lit, weights []int) (ints []int, ws []int) {
+	if weights != nil && len(weights) != len(lits) {
+		panic(fmt.Sprintf("cannot use objective with %d lits and %d weights", len(lits), len(weights)))
+	}
+	for i, lit := range lits {
+		v, ok := pb.intVars[lit.Var]
+		if !ok {
+			continue
+		}
+		if lit.Negated {
+			v = -v
+		}
+		ints = append(ints, v)
+		if weights != nil {
+			ws = append(ws, weights[i])
+		}
+	}
+	return ints, ws
+}
+
+// toLits returns the solver lits corresponding to the given integer values.
+func toLits(ints []int) []solver.Lit {
+	res := make([]solver.Lit, len(ints))
+	for i, val := range ints {
+		res[i] = solver.IntToLit(int32(val))
+	}
+	return res
+}
+
+// boundObjective adds a constraint stating that the weighted sum of the given lits is at most bound,
+// only enforced when the returned control var is assumed.
+// If the constraint is trivially satisfied, nothing is added and 0 is returned.
+func (pb *Problem) boundObjective(lits, weights []int, bound int) int {
+	lits2 := make([]int, len(lits))
+	copy(lits2, lits)
+	var weights2 []int
+	if weights != nil {
+		weights2 = make([]int, len(weights))
+		copy(weights2, weights)
+	}
+	c := lessEq(lits2, weights2, bound)
+	if c.AtLeast <= 0 {
+		return 0
+	}
+	for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+		pb.varInts = append(pb.varInts, "")
+	}
+	pb.varInts = append(pb.varInts, "")
+	act := len(pb.varInts)
+	pb.solver.AppendClause(relax(c, -act).Clause())
+	return act
+}
@@ -45,6 +45,7 @@ func (s *Solver) Enumerate(models chan []bool, stop chan struct{}) int {
 }
 
 // ParseWCNF parses a CNF file and returns the corresponding solver.Interface.
+// Syntax errors are reported as *solver.ParseError, and errors from f are wrapped in the returned error.
 func ParseWCNF(f io.Reader) (solver.Interface, error) {
 	scanner := bufio.NewScanner(f)
 	var (
@@ -55,8 +56,10 @@ func ParseWCNF(f io.Reader) (solver.Interface, error) {
 		weights   []int
 		maxWeight int
 		relaxLit  int // index of current relax lit
+		lineNb    int
 	)
 	for scanner.Scan() {
+		lineNb++
 		line := scanner.Text()
 		if line == "" {
 			continue
@@ -64,16 +67,16 @@ func ParseWCNF(f io.Reader) (solver.Interface, error) {
 		if line[0] == 'p' {
 			fields := strings.Fields(line)
 			if len(fields) < 4 || fields[1] != "wcnf" {
-				return nil, fmt.Errorf("invalid syntax %q in WCNF file", line)
+				return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("invalid syntax %q in WCNF file", line)}
 			}
 			var err error
 			nbVars, err = strconv.Atoi(fields[2])
 			if err != nil {
-				return nil, fmt.Errorf("nbvars not an int: %q", fields[2])
+				return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("nbvars not an int: %q", fields[2])}
 			}
 			nbClauses, err = strconv.Atoi(fields[3])
 			if err != nil {
-				return nil, fmt.Errorf("nbClauses not an int: %q", fields[3])
+				return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("nbClauses not an int: %q", fields[3])}
 			}
 			relaxLit = nbVars + 1
 			clauses = make([][]int, 0, nbClauses)
@@ -81,13 +84,13 @@ func ParseWCNF(f io.Reader) (solver.Interface, error) {
 			if len(fields) == 5 {
 				topWeight, err = strconv.Atoi(fields[4])
 				if err != nil {
-					return nil, fmt.Errorf("top weight not an int: %q", fields[4])
+					return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("top weight not an int: %q", fields[4])}
 				}
 			}
 		} else if line[0] != 'c' { // Not a header, not a comment : a clause
 			clause, weight, err := parseWCNFClause(line, topWeight, relaxLit)
 			if err != nil {
-				return nil, err
+				return nil, &solver.ParseError{Line: lineNb, Msg: err.Error()}
 			}
 			clauses = append(clauses, clause)
 			if topWeight == 0 || weight < topWeight {
@@ -97,6 +100,9 @@ func ParseWCNF(f io.Reader) (solver.Interface, error) {
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not parse WCNF: %w", err)
+	}
 	relaxLits := make([]solver.Lit, relaxLit-nbVars-1)
 	for i := range relaxLits {
 		relaxLits[i] = solver.IntToLit(int32(nbVars + i + 1))
@@ -0,0 +1,125 @@
+package maxsat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// ParseWBO parses a file in the WBO format, i.e an OPB file where constraints can be soft, and returns the corresponding problem.
+// Soft constraints are prefixed by their weight between brackets, as in "[3] +1 x1 +2 ~x2 >= 2 ;",
+// and can be any pseudo-boolean constraint; other constraints are hard.
+// The optional "soft: top ;" line gives the top cost: models whose cost is top or more are not solutions,
+// so the cost of models is bounded through SetCostUpperBound.
+// Vars are named as in the file, e.g "x1".
+// Syntax errors are reported as *solver.ParseError, and errors from f are wrapped in the returned error.
+func ParseWBO(f io.Reader) (*Problem, error) {
+	scanner := bufio.NewScanner(f)
+	var (
+		constrs []Constr
+		top     int // Top cost, or 0 if none
+		lineNb  int
+	)
+	for scanner.Scan() {
+		lineNb++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '*' {
+			continue
+		}
+		if line[len(line)-1] != ';' {
+			return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("line %q does not end with semicolon", line)}
+		}
+		fields := strings.Fields(line[:len(line)-1])
+		if len(fields) == 0 {
+			return nil, &solver.ParseError{Line: lineNb, Msg: "empty constraint"}
+		}
+		if fields[0] == "soft:" {
+			if len(fields) > 2 {
+				return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("invalid syntax %q", line)}
+			}
+			if len(fields) == 2 {
+				var err error
+				if top, err = strconv.Atoi(fields[1]); err != nil || top <= 0 {
+					return nil, &solver.ParseError{Line: lineNb, Msg: fmt.Sprintf("invalid top cost %q", fields[1])}
+				}
+			}
+			continue
+		}
+		constr, err := parseWBOConstr(fields, line)
+		if err != nil {
+			return nil, &solver.ParseError{Line: lineNb, Msg: err.Error()}
+		}
+		constrs = append(constrs, constr)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not parse WBO: %w", err)
+	}
+	pb := New(constrs...)
+	if top != 0 {
+		pb.SetCostUpperBound(top - 1)
+	}
+	return pb, nil
+}
+
+// parseWBOConstr parses the given fields of a WBO line, without the final semicolon, as a hard or soft constraint.
+func parseWBOConstr(fields []string, line string) (Constr, error) {
+	var constr Constr
+	if fields[0][0] == '[' { // Soft constraint
+		if fields[0][len(fields[0])-1] != ']' {
+			return constr, fmt.Errorf("invalid weight %q in %q", fields[0], line)
+		}
+		w, err := strconv.Atoi(fields[0][1 : len(fields[0])-1])
+		if err != nil || w <= 0 {
+			return constr, fmt.Errorf("invalid weight %q in %q", fields[0], line)
+		}
+		constr.Weight = w
+		fields = fields[1:]
+	}
+	if len(fields) < 3 {
+		return constr, fmt.Errorf("invalid syntax %q", line)
+	}
+	switch op := fields[len(fields)-2]; op {
+	case ">=":
+		constr.Comparator = GE
+	case "<=":
+		constr.Comparator = LE
+	case "=":
+		constr.Comparator = EQ
+	default:
+		return constr, fmt.Errorf("invalid operator %q in %q: expected \">=\", \"<=\" or \"=\"", op, line)
+	}
+	rhs, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return constr, fmt.Errorf("invalid value %q in %q", fields[len(fields)-1], line)
+	}
+	constr.AtLeast = rhs
+	terms := fields[:len(fields)-2]
+	for i := 0; i < len(terms); i++ {
+		coeff := 1
+		if c, err := strconv.Atoi(terms[i]); err == nil { // Otherwise, this is a weightless lit, i.e a lit with coeff 1
+			if i == len(terms)-1 {
+				return constr, fmt.Errorf("missing variable after coefficient %q in %q", terms[i], line)
+			}
+			coeff = c
+			i++
+		}
+		name := terms[i]
+		negated := strings.HasPrefix(name, "~")
+		if negated {
+			name = name[1:]
+		}
+		if len(name) < 2 || name[0] != 'x' {
+			return constr, fmt.Errorf("invalid variable name %q in %q", terms[i], line)
+		}
+		if _, err := strconv.Atoi(name[1:]); err != nil {
+			return constr, fmt.Errorf("invalid variable name %q in %q", terms[i], line)
+		}
+		constr.Lits = append(constr.Lits, Lit{Var: name, Negated: negated})
+		constr.Coeffs = append(constr.Coeffs, coeff)
+	}
+	return constr, nil
+}
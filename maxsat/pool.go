@@ -0,0 +1,45 @@
+package maxsat
+
+// A Pool solves a stream of related problems, that share a base of constraints and each have a few additional
+// hard constraints, on a single underlying solver, so that the clauses learned about the shared base while solving
+// an instance are kept for the next ones.
+type Pool struct {
+	pb *Problem
+}
+
+// NewPool returns a new pool whose instances all share the given constraints, hard or soft,
+// as if they were given to New.
+func NewPool(base ...Constr) *Pool {
+	return &Pool{pb: New(base...)}
+}
+
+// Solve solves the instance made of the shared constraints of the pool along with the given extra ones,
+// that are always hard, whatever their weight. It returns an optimal model, its cost, and the indices
+// of the soft constraints it violates, sorted in increasing order, or nil, -1 and nil if the instance is not satisfiable.
+// Extra constraints are added as in AddRetractable, and retracted before Solve returns, so they do not impact
+// later instances, while the clauses learned by the solver are kept.
+// Extra constraints can contain vars that are not part of the base: they are then part of the models of
+// the later instances too, with an arbitrary value, unless other extra constraints mention them.
+// Each instance leaves a few unit clauses in the solver, one per extra constraint, to disable them forever.
+func (p *Pool) Solve(extra ...Constr) (Model, int, []int) {
+	tokens := make([]int, len(extra))
+	for i, c := range extra {
+		tokens[i] = p.pb.AddRetractable(c)
+	}
+	defer func() {
+		for _, token := range tokens {
+			p.pb.Retract(token)
+		}
+	}()
+	model, cost := p.pb.Solve()
+	if model == nil {
+		return nil, -1, nil
+	}
+	return model, cost, p.pb.Broken()
+}
+
+// Problem returns the problem holding the shared constraints of the pool, e.g to change the weights
+// of its soft constraints between two instances. Extra constraints are not part of it outside of calls to Solve.
+func (p *Pool) Problem() *Problem {
+	return p.pb
+}
@@ -0,0 +1,72 @@
+package maxsat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// SolvePrioritized minimizes the violated soft constraints lexicographically, by priority rather than by weight:
+// priorities[i] is the priority of the constraint with index i, the lower the more important,
+// so that no number of violated constraints with a given priority is worth violating a single constraint with a lower one.
+// Within a priority, all constraints are worth the same, regardless of their weight: the number of violated ones is minimized.
+// It returns an optimal model, and, for each priority, in increasing order, the number of soft constraints with that priority
+// it violates. Priorities of hard constraints, and of soft ones disabled by DisableSoft, are ignored.
+// The cost function of the problem, and the bound set by SetCostUpperBound, if any, are ignored while minimizing.
+// Optimal counts are only enforced through assumptions, so clauses learned during the search are kept.
+// If the hard constraints cannot be satisfied, it returns nil, nil.
+// It panics if there are not as many priorities as constraints.
+func (pb *Problem) SolvePrioritized(priorities []int) (Model, []int) {
+	if len(priorities) != len(pb.blocks) {
+		panic(fmt.Sprintf("cannot solve with %d priorities for %d constraints", len(priorities), len(pb.blocks)))
+	}
+	tiers := make(map[int][]int) // For each priority, the blocking lits of its soft constraints
+	for i, prio := range priorities {
+		if pb.soft(i) {
+			tiers[prio] = append(tiers[prio], pb.softLits(i)...)
+		}
+	}
+	prios := make([]int, 0, len(tiers))
+	for prio := range tiers {
+		prios = append(prios, prio)
+	}
+	sort.Ints(prios)
+	pb.lastModel = nil
+	if pb.assume(nil) == solver.Unsat { // Also rebuilds the solver if needed
+		return nil, nil
+	}
+	defer pb.updateCostFunc()
+	var acts []int
+	defer func() { // Bounds are not needed anymore: disable them forever
+		pb.assume(nil)
+		for _, act := range acts {
+			pb.solver.AppendClause(solver.NewClause([]solver.Lit{solver.IntToLit(int32(-act))}))
+		}
+	}()
+	var bounds []solver.Lit // Optimal counts of the previous priorities
+	if len(prios) == 0 {    // Nothing to minimize: any model is optimal
+		if pb.solver.Solve() != solver.Sat {
+			return nil, nil
+		}
+		pb.lastModel = pb.solver.Model()
+		return pb.decode(pb.lastModel), []int{}
+	}
+	counts := make([]int, len(prios))
+	for i, prio := range prios {
+		lits := tiers[prio]
+		pb.solver.SetCostFunc(toLits(lits), nil)
+		pb.assume(bounds)
+		count := pb.solver.Minimize() // Cannot be -1 after the first priority, the last model still satisfies all bounds
+		if count == -1 {
+			return nil, nil
+		}
+		counts[i] = count
+		pb.lastModel = pb.solver.Model()
+		if act := pb.boundObjective(lits, nil, count); act != 0 {
+			acts = append(acts, act)
+			bounds = append(bounds, solver.IntToLit(int32(act)))
+		}
+	}
+	return pb.decode(pb.lastModel), counts
+}
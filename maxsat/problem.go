@@ -2,6 +2,9 @@ package maxsat
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/crillab/gophersat/solver"
 )
@@ -9,67 +12,961 @@ import (
 // A Model associates variable names with a binding.
 type Model map[string]bool
 
+// DecodeModel returns the Model equivalent to the given model, as returned by solver.Solver.Model.
+// names associates the 1-based ids of the vars, as in the DIMACS format, with their names, as given to Wrap:
+// the binding of the var with id i is model[i-1].
+// Ids that are not in names, or that are out of the bounds of model, do not appear in the result.
+func DecodeModel(model []bool, names map[int]string) Model {
+	res := make(Model, len(names))
+	for id, name := range names {
+		if id >= 1 && id <= len(model) {
+			res[name] = model[id-1]
+		}
+	}
+	return res
+}
+
 // A Problem is a set of constraints.
 type Problem struct {
-	solver       *solver.Solver
-	intVars      map[string]int // for each var, its integer counterpart
-	varInts      []string       // for each int value, the associated variable
-	blockWeights map[int]int    // for each blocking literal, the weight of the associated constraint
-	maxWeight    int            // sum of all blockWeights
+	solver        *solver.Solver
+	intVars       map[string]int            // for each var, its integer counterpart
+	varInts       []string                  // for each int value, the associated variable
+	blockWeights  map[int]int               // for each blocking literal, the weight of the associated constraint
+	maxWeight     int                       // sum of all blockWeights
+	blocks        []int                     // for each constraint, its blocking literal, or 0 if it is a hard constraint
+	lastModel     []bool                    // last model found by Solve, as returned by the solver
+	constrs       [][]solver.PBConstr       // for each constraint, its translation, as given to the solver
+	labels        map[string]string         // for each var, its custom label in OPB and WCNF outputs
+	parseDur      time.Duration             // time spent building the problem
+	ctrls         map[int]int               // for each tagged constraint, its control var
+	tags          map[int]string            // for each tagged constraint, its tag
+	disabled      map[string]bool           // tags whose constraints are currently disabled
+	dirty         bool                      // whether the solver must be rebuilt to take new control vars into account
+	costBound     int                       // bound set by SetCostUpperBound, if hasCostBound is true
+	hasCostBound  bool                      // whether SetCostUpperBound was called since the last call to ClearCostUpperBound
+	wrapUsed      map[int]bool              // for a problem made by Wrap, the vars appearing in the constraints of the wrapped problem
+	disabledSoft  map[int]int               // for each soft constraint disabled by DisableSoft, its original weight
+	optima        []optimum                 // optimal costs found by MinimizeUnder, used as lower bounds by later calls
+	step          *stepState                // state of the search performed by Step, if any
+	opts          Options                   // options given to NewWithOptions
+	stats         chan Stats                // channel returned by StatsStream, if any
+	order         []int                     // order in which constraints are given to the solver, as set by Shuffle, or nil for their natural order
+	coreBound     int                       // lower bound on the optimal cost given by the cores seeded by SeedCores
+	objectives    map[string]namedObjective // alternative cost functions registered by DefineObjective
+	retractables  map[int][]solver.PBConstr // for each activation var of a constraint added by AddRetractable, its relaxed translation
+	capped        map[int]capped            // for each soft constraint with a WeightCap, its additional blocking lits
+	boundHint     bool                      // whether the cost bound was set by SetUpperBoundHint, so that blocking lits heavier than it are assumed false
+	enums         map[string][]string       // for each finite-domain var declared by AddEnum, its domain
+	linear        map[string]int            // linear objective set by SetLinearObjective, if any, replacing the soft constraints as cost function
+	decisionVars  map[string]bool           // vars the solver can make decisions on, as set by SetDecisionVars, or nil if all vars can be decided
+	weightFuncs   map[int]weightFunc        // for each soft constraint given to SetWeightFunction, how its weight depends on the parameter of SolveAt
+	hardModel     []bool                    // last known model of the hard constraints, used as a starting point by Solve, or nil if unknown
+	hardUnsat     bool                      // whether the hard constraints are known to be unsatisfiable
+	contradiction []int                     // indices of the hard constraints found to contradict each other when building the problem, if any
+	interrupted   bool                      // whether the last call to Solve was stopped by Stop
+	solverMu      sync.Mutex                // guards solver when it is rebuilt, since Stop can be called concurrently
+}
+
+// A capped holds the additional blocking lits of a soft constraint with a WeightCap.
+// Each of them absorbs one unit of violation of the constraint, at the cost of weight.
+type capped struct {
+	lits   []int
+	weight int
+}
+
+// A weightFunc is the weight of a soft constraint as a function of a parameter λ, i.e base + λ·slope.
+type weightFunc struct {
+	base, slope int
+}
+
+// An optimum is the optimal cost found by MinimizeUnder under some assumptions.
+type optimum struct {
+	assumptions map[string]bool
+	cost        int
+}
+
+// Timings describes how much time was spent on the different steps of solving a problem.
+type Timings struct {
+	Parse time.Duration // Time spent translating the constraints and parsing them in the solver, when calling New
+	Solve time.Duration // Total time spent searching for models
+}
+
+// Timings returns the time spent building and solving the problem so far.
+func (pb *Problem) Timings() Timings {
+	return Timings{Parse: pb.parseDur, Solve: pb.solver.SolveDuration()}
+}
+
+// Options customizes how NewWithOptions builds a problem.
+// The zero value gives the same problem as New.
+type Options struct {
+	// BlockPrefix is the prefix of the labels of blocking literals in the outputs of WriteOPB:
+	// the blocking literal of a soft constraint is labelled with BlockPrefix followed by the index of the constraint.
+	// If empty, "soft_" is used.
+	BlockPrefix string
+	// AuxPrefix is the prefix of the labels of other auxiliary vars in the outputs of WriteOPB,
+	// such as the vars reifying EQ constraints or controlling tagged constraints:
+	// they are labelled with AuxPrefix followed by their numeric id.
+	// If empty, auxiliary vars are not labelled.
+	AuxPrefix string
 }
 
 // New returns a new problem associated with the given constraints.
+// Constraints that are trivially satisfied, i.e GE constraints with a bound of 0 or less and no negative coefficient,
+// are not given to the solver: they are never broken, and their weight is not part of MaxWeight.
+// Their vars are still part of the problem. Likewise, soft LE and EQ constraints that always hold, e.g a LE constraint
+// whose bound is at least the sum of its coefficients, are never broken and get no weight.
+// A constraint whose weight is 0 or Hard is hard; see NewPartial for the WCNF convention, where a weight of 0
+// means a soft constraint that does not matter.
 func New(constrs ...Constr) *Problem {
-	pb := &Problem{intVars: make(map[string]int), blockWeights: make(map[int]int)}
-	clauses := make([]solver.PBConstr, len(constrs))
+	return NewWithOptions(Options{}, constrs...)
+}
+
+// NewPartial is like New, but the weights of the constraints follow the WCNF convention:
+// constraints whose weight is top or more are hard, and the other ones are soft, including those whose weight is 0,
+// which can be broken for free and thus never appear in the result of Broken.
+// Reified constraints are never soft, whatever their weight.
+func NewPartial(top int, constrs ...Constr) *Problem {
+	cs := make([]Constr, len(constrs))
+	for i, c := range constrs {
+		switch {
+		case c.Reified != "":
+			cs[i] = c
+		case c.Weight >= top:
+			cs[i] = c
+			cs[i].Weight = Hard
+		case c.Weight == 0: // Never broken: keep its vars, but make it trivial
+			cs[i] = Constr{Lits: c.Lits}
+		default:
+			cs[i] = c
+		}
+	}
+	return New(cs...)
+}
+
+// NewWithOptions is like New, but the problem is customized by opts.
+func NewWithOptions(opts Options, constrs ...Constr) *Problem {
+	start := time.Now()
+	pb := &Problem{intVars: make(map[string]int), blockWeights: make(map[int]int), blocks: make([]int, len(constrs)), opts: opts}
+	pb.constrs = make([][]solver.PBConstr, len(constrs))
 	for i, constr := range constrs {
-		lits := make([]int, len(constr.Lits))
-		for j, lit := range constr.Lits {
-			v := lit.Var
-			if _, ok := pb.intVars[v]; !ok {
-				pb.varInts = append(pb.varInts, v)
-				pb.intVars[v] = len(pb.varInts)
-			}
-			lits[j] = pb.intVars[v]
-			if lit.Negated {
-				lits[j] = -lits[j]
-			}
+		lits := pb.intLits(constr.Lits)
+		if constr.trivial() { // Always satisfied: no need for a blocking literal nor for clauses
+			continue
 		}
 		var coeffs []int
 		if len(constr.Coeffs) != 0 {
 			coeffs = make([]int, len(constr.Coeffs))
 			copy(coeffs, constr.Coeffs)
 		}
-		if constr.Weight != 0 { // Soft constraint: add blocking literal
+		parts := pb.translate(constr, lits, coeffs)
+		bl := 0
+		if constr.soft() && !alwaysSat(parts) { // Soft constraint that can be violated: add blocking literal
 			pb.varInts = append(pb.varInts, "") // Create new blocking lit
-			bl := len(pb.varInts)
+			bl = len(pb.varInts)
 			pb.blockWeights[bl] = constr.Weight
+			pb.blocks[i] = bl
 			pb.maxWeight += constr.Weight
-			lits = append(lits, bl)
-			if coeffs != nil { // If this is a clause, there is no explicit coeff
-				// TODO: deal with card constraints: AtLeast !=1 but coeffs == nil!
-				coeffs = append(coeffs, constr.AtLeast)
+		}
+		if bl != 0 && constr.WeightCap > 0 && len(parts) == 1 {
+			pb.constrs[i] = []solver.PBConstr{pb.relaxCapped(i, parts[0], constr.Weight, constr.WeightCap)}
+			continue
+		}
+		for _, c := range parts {
+			pb.constrs[i] = append(pb.constrs[i], relax(c, bl))
+		}
+	}
+	pb.build()
+	pb.checkContradiction()
+	pb.parseDur = time.Since(start)
+	return pb
+}
+
+// build creates the underlying solver from the translations of the constraints.
+// Tagged constraints are relaxed by the negation of their control var, so that they are only enforced
+// when it is assumed.
+func (pb *Problem) build() {
+	prob := solver.ParsePBConstrs(pb.Clauses())
+	prob.SetCostFunc(pb.costFunc())
+	verbose := pb.solver != nil && pb.solver.Verbose
+	pb.solverMu.Lock()
+	pb.solver = solver.New(prob)
+	pb.solverMu.Unlock()
+	pb.solver.EnsureNbVars(len(pb.varInts)) // Vars that only appear in trivial constraints are not part of prob
+	pb.solver.Verbose = verbose
+	if pb.hasCostBound {
+		pb.solver.SetCostBound(pb.costBound)
+	}
+	pb.solver.SetCostLowerBound(pb.coreBound)
+	for _, act := range pb.activations() { // Retractable constraints are not part of Clauses
+		for _, c := range pb.retractables[act] {
+			if c.AtLeast > 0 {
+				pb.solver.AppendClause(copyPBConstr(c).Clause())
 			}
 		}
-		clauses[i] = solver.GtEq(lits, coeffs, constr.AtLeast)
 	}
-	optLits := make([]solver.Lit, 0, len(pb.blockWeights))
-	optWeights := make([]int, 0, len(pb.blockWeights))
-	for v, w := range pb.blockWeights {
-		optLits = append(optLits, solver.IntToLit(int32(v)))
-		optWeights = append(optWeights, w)
+	pb.dirty = false
+}
+
+// Clauses returns the translation of the constraints of the problem, as they are given to the solver:
+// soft constraints are relaxed by their blocking literal, and tagged constraints by the negation of their control var.
+// Vars are identified by their integer counterpart, as in the OPB output of the problem.
+// Clauses added to the solver while solving, such as bounds on the cost, are not returned,
+// and neither are the constraints of the solver.Problem given to Wrap.
+// The returned constraints are copies, and can be freely modified.
+func (pb *Problem) Clauses() []solver.PBConstr {
+	var clauses []solver.PBConstr
+	for j := range pb.constrs {
+		i := j
+		if pb.order != nil {
+			i = pb.order[j]
+		}
+		for _, c := range pb.constrs[i] {
+			c = copyPBConstr(c)
+			if ctrl := pb.ctrls[i]; ctrl != 0 {
+				c = relax(c, -ctrl)
+			}
+			clauses = append(clauses, c)
+		}
+	}
+	return clauses
+}
+
+// Wrap returns a new problem built from a problem that was already given to the solver package.
+// names associates the id of the vars of prob with their names; ids that are not vars of prob are ignored.
+// Vars that are not in names are considered auxiliary vars and do not appear in the models returned by Solve.
+// Each lit of the cost function of prob is considered as the blocking literal of a soft constraint,
+// whose weight is the weight of the lit: the ith soft constraint is broken iff the ith lit of the cost function is true,
+// and its index is i in the result of Broken.
+// All lits in the cost function must be positive, or Wrap will panic.
+// The constraints of prob are not known to the returned problem, so they cannot be written by WriteOPB or WriteWCNF,
+// nor tagged with TagConstr.
+func Wrap(prob *solver.Problem, names map[int]string) *Problem {
+	start := time.Now()
+	lits, weights := prob.CostFunc()
+	pb := &Problem{intVars: make(map[string]int), blockWeights: make(map[int]int), blocks: make([]int, len(lits))}
+	pb.varInts = make([]string, prob.NbVars)
+	for v, name := range names {
+		if v < 1 || v > prob.NbVars {
+			continue
+		}
+		pb.varInts[v-1] = name
+		pb.intVars[name] = v
+	}
+	for i, lit := range lits {
+		bl := int(lit.Int())
+		if bl < 0 {
+			panic("cost function contains a negative literal")
+		}
+		w := 1
+		if weights != nil {
+			w = weights[i]
+		}
+		pb.blocks[i] = bl
+		pb.blockWeights[bl] += w
+		pb.maxWeight += w
+	}
+	pb.wrapUsed = make(map[int]bool)
+	for _, unit := range prob.Units {
+		pb.wrapUsed[int(unit.Var().Int())] = true
+	}
+	for _, c := range prob.Clauses {
+		for i := 0; i < c.Len(); i++ {
+			if c.Weight(i) != 0 {
+				pb.wrapUsed[int(c.Get(i).Var().Int())] = true
+			}
+		}
 	}
-	prob := solver.ParsePBConstrs(clauses)
-	prob.SetCostFunc(optLits, optWeights)
 	pb.solver = solver.New(prob)
+	pb.parseDur = time.Since(start)
 	return pb
 }
 
+// UnusedVars returns the names of the vars of the problem that appear in no constraint,
+// or only with a coefficient of 0, sorted in alphabetical order.
+// Such vars can take any value in the models returned by Solve, and usually denote a bug in the code
+// that generated the constraints.
+// For a problem made by Wrap, constraints are the ones of the wrapped problem, once simplified by the solver package.
+func (pb *Problem) UnusedVars() []string {
+	used := make(map[int]bool, len(pb.intVars))
+	for v := range pb.wrapUsed {
+		used[v] = true
+	}
+	for _, cs := range pb.constrs {
+		for _, c := range cs {
+			for i, lit := range c.Lits {
+				if weight(c, i) != 0 {
+					used[abs(lit)] = true
+				}
+			}
+		}
+	}
+	var res []string
+	for name, v := range pb.intVars {
+		if !used[v] {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// FreeVars returns the names of the vars whose value does not matter in the model m, sorted in alphabetical order.
+// A var is free iff changing its value alone in m does not change whether any enabled constraint is satisfied:
+// the result is then still a model of the problem, with the same cost.
+// This includes vars that appear in no constraint, or only in disabled ones.
+// Vars that appear in a constraint along with auxiliary vars, such as the ones created for reified equalities,
+// are never considered free, and neither are the vars that appear in the constraints of a problem made by Wrap.
+func (pb *Problem) FreeVars(m Model) []string {
+	bound := make(map[int]bool) // Vars whose value matters
+	for v := range pb.wrapUsed {
+		bound[v] = true
+	}
+	for i, cs := range pb.constrs {
+		if pb.ctrls[i] != 0 && pb.disabled[pb.tags[i]] {
+			continue
+		}
+		for _, c := range cs {
+			pb.markBound(c, pb.blocks[i], m, bound)
+		}
+	}
+	var res []string
+	for name, v := range pb.intVars {
+		if !bound[v] {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// markBound marks, in bound, the vars of c whose value matters in m, i.e the vars whose value,
+// if changed, would change whether c is satisfied or not.
+// The blocking literal bl of c, if any, is ignored, so that the original constraint is considered.
+func (pb *Problem) markBound(c solver.PBConstr, bl int, m Model, bound map[int]bool) {
+	sum := 0
+	for i, lit := range c.Lits {
+		if lit == bl {
+			continue
+		}
+		name := pb.varInts[abs(lit)-1]
+		if name == "" { // Auxiliary var: its value is unknown, so all vars are considered bound
+			for _, lit2 := range c.Lits {
+				if lit2 != bl {
+					bound[abs(lit2)] = true
+				}
+			}
+			return
+		}
+		if m[name] == (lit > 0) {
+			sum += weight(c, i)
+		}
+	}
+	sat := sum >= c.AtLeast
+	for i, lit := range c.Lits {
+		if lit == bl {
+			continue
+		}
+		w := weight(c, i)
+		newSum := sum + w
+		if m[pb.varInts[abs(lit)-1]] == (lit > 0) {
+			newSum = sum - w
+		}
+		if (newSum >= c.AtLeast) != sat {
+			bound[abs(lit)] = true
+		}
+	}
+}
+
+// Verify checks, independently of the solver, whether the model m satisfies all the hard constraints of the problem.
+// It returns true iff it does, and the indices of the violated hard constraints, in increasing order, as in Broken.
+// Vars that do not appear in m are considered false. Constraints whose tag is disabled are ignored,
+// and so are the constraints added by AddRetractable and the ones of a problem made by Wrap, which have no index.
+// A constraint whose translation involves auxiliary vars, such as a reified equality, is satisfied
+// iff some values of these vars satisfy its translation.
+func (pb *Problem) Verify(m Model) (bool, []int) {
+	var violated []int
+	for i, cs := range pb.constrs {
+		if pb.blocks[i] != 0 || (pb.ctrls[i] != 0 && pb.disabled[pb.tags[i]]) {
+			continue
+		}
+		if !pb.holds(cs, m) {
+			violated = append(violated, i)
+		}
+	}
+	return len(violated) == 0, violated
+}
+
+// CostOf returns the cost of the model m, as if it was returned by Solve, e.g to check the cost of a model found by another solver,
+// along with whether m satisfies all the hard constraints, as reported by Verify, which also gives the violated ones.
+// The cost is the sum of the costs of the soft constraints m violates, or its value w.r.t the linear objective
+// set by SetLinearObjective, if any. Vars that do not appear in m are considered false.
+// Soft constraints that are disabled, by DisableSoft or by their tag, do not contribute to the cost.
+// For a problem made by Wrap, whose constraints are not known, a soft constraint is violated iff its lit in the cost function
+// is a named var that is true in m.
+func (pb *Problem) CostOf(m Model) (int, bool) {
+	ok, _ := pb.Verify(m)
+	cost := 0
+	if pb.linear != nil {
+		for name, coeff := range pb.linear {
+			if lit := namedLit(name); m[lit.Var] != lit.Negated {
+				cost += coeff
+			}
+		}
+		return cost, ok
+	}
+	for i := range pb.blocks {
+		if pb.soft(i) && (pb.ctrls[i] == 0 || !pb.disabled[pb.tags[i]]) {
+			cost += pb.softCostOf(i, m)
+		}
+	}
+	return cost, ok
+}
+
+// softCostOf returns the cost of the soft constraint with the given index in m.
+func (pb *Problem) softCostOf(i int, m Model) int {
+	bl := pb.blocks[i]
+	if pb.wrapUsed != nil {
+		if m[pb.varInts[bl-1]] {
+			return pb.blockWeights[bl]
+		}
+		return 0
+	}
+	blocking := make(map[int]bool)
+	for _, l := range pb.softLits(i) {
+		blocking[l] = true
+	}
+	missing := 0 // Max number of units by which a part of the constraint is not satisfied
+	for _, c := range pb.constrs[i] {
+		sum := 0
+		for j, lit := range c.Lits {
+			if !blocking[lit] && m[pb.varInts[abs(lit)-1]] == (lit > 0) {
+				sum += weight(c, j)
+			}
+		}
+		if c.AtLeast-sum > missing {
+			missing = c.AtLeast - sum
+		}
+	}
+	if missing == 0 {
+		return 0
+	}
+	if c, ok := pb.capped[i]; ok && missing*c.weight < pb.blockWeights[bl] {
+		return missing * c.weight
+	}
+	return pb.blockWeights[bl]
+}
+
+// holds returns true iff the constraints cs, the translation of a hard constraint, are all satisfied by m,
+// for some values of the auxiliary vars they contain.
+func (pb *Problem) holds(cs []solver.PBConstr, m Model) bool {
+	aux := make(map[int]uint) // For each auxiliary var, its position in vals below
+	for _, c := range cs {
+		for _, lit := range c.Lits {
+			if v := abs(lit); pb.varInts[v-1] == "" {
+				if _, ok := aux[v]; !ok {
+					aux[v] = uint(len(aux))
+				}
+			}
+		}
+	}
+	value := func(v int, vals uint) bool {
+		if pos, ok := aux[v]; ok {
+			return vals&(1<<pos) != 0
+		}
+		return m[pb.varInts[v-1]]
+	}
+	for vals := uint(0); vals < 1<<uint(len(aux)); vals++ {
+		sat := true
+		for _, c := range cs {
+			sum := 0
+			for i, lit := range c.Lits {
+				if value(abs(lit), vals) == (lit > 0) {
+					sum += weight(c, i)
+				}
+			}
+			if sum < c.AtLeast {
+				sat = false
+				break
+			}
+		}
+		if sat {
+			return true
+		}
+	}
+	return false
+}
+
+// weight returns the weight of the ith lit of c.
+func weight(c solver.PBConstr, i int) int {
+	if c.Weights == nil {
+		return 1
+	}
+	return c.Weights[i]
+}
+
+// abs returns the absolute value of the given lit.
+func abs(lit int) int {
+	if lit < 0 {
+		return -lit
+	}
+	return lit
+}
+
+// costFunc returns the cost function of the problem, i.e the blocking literals of the soft constraints
+// whose weight is not 0, along with their weights, or the linear objective set by SetLinearObjective, if any.
+func (pb *Problem) costFunc() ([]solver.Lit, []int) {
+	if pb.linear != nil {
+		lits, weights := pb.linearObjective()
+		return toLits(lits), weights
+	}
+	lits := make([]solver.Lit, 0, len(pb.blockWeights))
+	weights := make([]int, 0, len(pb.blockWeights))
+	for v, w := range pb.blockWeights {
+		if w != 0 {
+			lits = append(lits, solver.IntToLit(int32(v)))
+			weights = append(weights, w)
+		}
+	}
+	return lits, weights
+}
+
+// soft returns true iff the constraint with the given index is a soft constraint that is not disabled.
+func (pb *Problem) soft(i int) bool {
+	bl := pb.blocks[i]
+	return bl != 0 && pb.blockWeights[bl] != 0
+}
+
+// softLits returns the blocking lits of the soft constraint with the given index: its main blocking lit,
+// followed by the additional ones of a constraint with a WeightCap, if any.
+func (pb *Problem) softLits(i int) []int {
+	return append([]int{pb.blocks[i]}, pb.capped[i].lits...)
+}
+
+// softCost returns the cost of the soft constraint with the given index in the given solver model.
+func (pb *Problem) softCost(i int, model []bool) int {
+	cost := 0
+	for _, bl := range pb.softLits(i) {
+		if model[bl-1] {
+			cost += pb.blockWeights[bl]
+		}
+	}
+	return cost
+}
+
+// minCost returns the lowest cost of a violation of the soft constraint with the given index,
+// i.e its weight, or the cost of a single unit of violation for a constraint with a WeightCap.
+func (pb *Problem) minCost(i int) int {
+	w := pb.blockWeights[pb.blocks[i]]
+	if c, ok := pb.capped[i]; ok && c.weight < w {
+		return c.weight
+	}
+	return w
+}
+
+// negSoftLits returns the negations of the blocking lits of the soft constraints with the given indices,
+// so that assuming them enforces those constraints.
+func (pb *Problem) negSoftLits(idx []int) []solver.Lit {
+	var lits []solver.Lit
+	for _, i := range idx {
+		for _, bl := range pb.softLits(i) {
+			lits = append(lits, solver.IntToLit(int32(-bl)))
+		}
+	}
+	return lits
+}
+
+// DisableSoft removes the soft constraint with the given index from the cost function,
+// so that it is ignored by later solves, until EnableSoft is called.
+// Its weight is not part of MaxWeight anymore, and it does not appear in the result of Broken.
+// The clauses learned by the solver are kept, but the bound set by SetCostUpperBound, if any,
+// is recomputed for the new cost function.
+// If the constraint is hard or already disabled, nothing happens.
+func (pb *Problem) DisableSoft(constrIndex int) {
+	if constrIndex < 0 || constrIndex >= len(pb.blocks) || !pb.soft(constrIndex) {
+		return
+	}
+	pb.disableSoft(constrIndex)
+	pb.updateCostFunc()
+}
+
+// disableSoft is like DisableSoft, but it does not check the index, nor update the cost function of the solver.
+func (pb *Problem) disableSoft(constrIndex int) {
+	bl := pb.blocks[constrIndex]
+	if pb.disabledSoft == nil {
+		pb.disabledSoft = make(map[int]int)
+	}
+	pb.disabledSoft[constrIndex] = pb.blockWeights[bl]
+	pb.maxWeight -= pb.blockWeights[bl]
+	pb.blockWeights[bl] = 0
+	for _, l := range pb.capped[constrIndex].lits {
+		pb.blockWeights[l] = 0
+	}
+}
+
+// EnableSoft restores the weight of a soft constraint that was disabled by DisableSoft.
+// If the constraint was not disabled, nothing happens.
+func (pb *Problem) EnableSoft(constrIndex int) {
+	w, ok := pb.disabledSoft[constrIndex]
+	if !ok {
+		return
+	}
+	delete(pb.disabledSoft, constrIndex)
+	pb.blockWeights[pb.blocks[constrIndex]] = w
+	for _, l := range pb.capped[constrIndex].lits {
+		pb.blockWeights[l] = pb.capped[constrIndex].weight
+	}
+	pb.maxWeight += w
+	pb.updateCostFunc()
+}
+
+// SetSoftWeight changes the weight of the soft constraint with the given index, e.g to tune the relative importance
+// of soft constraints interactively. The solver is reused, so the clauses it learned are kept for the next solve,
+// and MaxWeight is updated accordingly. Setting a weight of 0 disables the constraint, as with DisableSoft;
+// setting a strictly positive weight enables it again if it was disabled.
+// It panics if there is no soft constraint with such an index, if weight is negative or Hard, or if the weight
+// of a constraint with a WeightCap is set to a value other than 0, since its encoding depends on its weight.
+func (pb *Problem) SetSoftWeight(constrIndex, weight int) {
+	if constrIndex < 0 || constrIndex >= len(pb.blocks) || pb.blocks[constrIndex] == 0 {
+		panic(fmt.Sprintf("cannot set weight of constraint #%d: no such soft constraint", constrIndex))
+	}
+	if weight < 0 || weight == Hard {
+		panic(fmt.Sprintf("cannot set weight of constraint #%d: invalid weight %d", constrIndex, weight))
+	}
+	if weight == 0 {
+		pb.DisableSoft(constrIndex)
+		return
+	}
+	if _, ok := pb.capped[constrIndex]; ok {
+		panic(fmt.Sprintf("cannot set weight of constraint #%d: it has a WeightCap", constrIndex))
+	}
+	pb.setSoftWeight(constrIndex, weight)
+	pb.updateCostFunc()
+}
+
+// setSoftWeight is like SetSoftWeight for a strictly positive weight, but it does not check its arguments,
+// nor update the cost function of the solver.
+func (pb *Problem) setSoftWeight(constrIndex, weight int) {
+	bl := pb.blocks[constrIndex]
+	delete(pb.disabledSoft, constrIndex)
+	pb.maxWeight += weight - pb.blockWeights[bl]
+	pb.blockWeights[bl] = weight
+}
+
+// SetWeightFunction makes the weight of the soft constraint with the given index depend on the parameter given to SolveAt,
+// as base + lambda·slope, e.g for parametric analysis of a family of problems that only differ by their weights.
+// It panics if there is no soft constraint with such an index, or if it has a WeightCap.
+func (pb *Problem) SetWeightFunction(constrIndex int, base, slope int) {
+	if constrIndex < 0 || constrIndex >= len(pb.blocks) || pb.blocks[constrIndex] == 0 {
+		panic(fmt.Sprintf("cannot set weight function of constraint #%d: no such soft constraint", constrIndex))
+	}
+	if _, ok := pb.capped[constrIndex]; ok {
+		panic(fmt.Sprintf("cannot set weight function of constraint #%d: it has a WeightCap", constrIndex))
+	}
+	if pb.weightFuncs == nil {
+		pb.weightFuncs = make(map[int]weightFunc)
+	}
+	pb.weightFuncs[constrIndex] = weightFunc{base: base, slope: slope}
+}
+
+// SolveAt sets the weight of each soft constraint given to SetWeightFunction to its value for the given parameter,
+// as SetSoftWeight would, and then solves the problem, returning an optimal model, its cost, and the indices
+// of the soft constraints it violates, sorted in increasing order.
+// A constraint whose weight would be negative gets a weight of 0, i.e it is disabled until a later call gives it a positive weight.
+// The solver is reused from one parameter to the next, so the clauses it learned are kept, and the weights remain set after the call.
+// If the problem is not satisfiable, it returns nil, -1 and nil.
+func (pb *Problem) SolveAt(lambda int) (Model, int, []int) {
+	idx := make([]int, 0, len(pb.weightFuncs))
+	for i := range pb.weightFuncs {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	for _, i := range idx {
+		f := pb.weightFuncs[i]
+		weight := f.base + lambda*f.slope
+		if weight > 0 {
+			pb.setSoftWeight(i, weight)
+		} else if pb.soft(i) {
+			pb.disableSoft(i)
+		}
+	}
+	pb.updateCostFunc()
+	model, cost := pb.Solve()
+	if model == nil {
+		return nil, -1, nil
+	}
+	return model, cost, pb.Broken()
+}
+
+// updateCostFunc gives the current cost function to the solver, and enforces the cost bound again, if any.
+// Optimal costs remembered by MinimizeUnder, and the lower bound given by SeedCores, are forgotten,
+// since they might not be lower bounds anymore.
+func (pb *Problem) updateCostFunc() {
+	pb.optima = nil
+	pb.coreBound = 0
+	pb.solver.SetCostFunc(pb.costFunc())
+	if pb.hasCostBound {
+		pb.solver.SetCostBound(pb.costBound)
+	}
+}
+
+// intLits returns the integer counterparts of the given lits.
+// Vars that were not known yet are associated with a new integer value.
+func (pb *Problem) intLits(lits []Lit) []int {
+	res := make([]int, len(lits))
+	for i, lit := range lits {
+		v := lit.Var
+		if _, ok := pb.intVars[v]; !ok {
+			pb.varInts = append(pb.varInts, v)
+			pb.intVars[v] = len(pb.varInts)
+		}
+		res[i] = pb.intVars[v]
+		if lit.Negated {
+			res[i] = -res[i]
+		}
+	}
+	return res
+}
+
+// AddConstr adds a new hard constraint to the problem.
+// The underlying solver is reused, so the clauses it learned are kept, and the next call to Solve
+// will return a model satisfying the new constraint, if any, along with its updated cost.
+// The constraint can contain vars that were not part of the problem yet.
+// Soft constraints cannot be added to an existing problem: an error is returned if constr.Weight is neither 0 nor Hard.
+func (pb *Problem) AddConstr(constr Constr) error {
+	if constr.soft() {
+		return fmt.Errorf("cannot add soft constraint to existing problem")
+	}
+	for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+		pb.varInts = append(pb.varInts, "")
+	}
+	lits := pb.intLits(constr.Lits)
+	var coeffs []int
+	if len(constr.Coeffs) != 0 {
+		coeffs = make([]int, len(constr.Coeffs))
+		copy(coeffs, constr.Coeffs)
+	}
+	pb.addHard(pb.translate(constr, lits, coeffs))
+	return nil
+}
+
+// addHard adds a new hard constraint to the problem, whose translation is parts, and gives it to the solver.
+func (pb *Problem) addHard(parts []solver.PBConstr) {
+	pb.blocks = append(pb.blocks, 0)
+	var cs []solver.PBConstr
+	for _, c := range parts {
+		cs = append(cs, copyPBConstr(c))
+		if c.AtLeast > 0 { // Otherwise, c is trivially satisfied
+			pb.solver.AppendClause(c.Clause())
+		}
+	}
+	if pb.order != nil {
+		pb.order = append(pb.order, len(pb.constrs))
+	}
+	pb.constrs = append(pb.constrs, cs)
+	pb.solver.EnsureNbVars(len(pb.varInts)) // Its vars might not appear in the clauses given to the solver
+	pb.lastModel = nil
+	pb.InvalidateHardCache()
+	if pb.linear != nil { // The constraint can contain vars of the linear objective that were ignored so far
+		pb.updateCostFunc()
+	}
+}
+
+// translate returns the solver constraints equivalent to constr, whose lits and coeffs were translated to lits and coeffs.
+// If constr is reified, its reification var is created if needed.
+func (pb *Problem) translate(constr Constr, lits, coeffs []int) []solver.PBConstr {
+	if constr.Reified == "" {
+		return pbConstrs(lits, coeffs, constr.AtLeast, constr.Comparator)
+	}
+	b := pb.intLits([]Lit{Var(constr.Reified)})[0]
+	parts := pbConstrs(lits, coeffs, constr.AtLeast, constr.Comparator)
+	if len(parts) == 1 {
+		return solver.Reify(b, parts[0].Lits, parts[0].Weights, parts[0].AtLeast)
+	}
+	// EQ constraint: b is true iff both the GE and the LE parts, each reified by an auxiliary var, are true
+	var res []solver.PBConstr
+	aux := make([]int, len(parts))
+	for i, part := range parts {
+		pb.varInts = append(pb.varInts, "")
+		aux[i] = len(pb.varInts)
+		res = append(res, solver.Reify(aux[i], part.Lits, part.Weights, part.AtLeast)...)
+		res = append(res, solver.PropClause(-b, aux[i]))
+	}
+	return append(res, solver.PropClause(b, -aux[0], -aux[1]))
+}
+
+// pbConstrs returns the solver constraints equivalent to a constraint whose lits were translated to lits.
+// A LE constraint is translated as a GtEq over the negated lits, and an EQ constraint as both a GtEq and a LE constraint.
+func pbConstrs(lits, coeffs []int, bound int, cmp Comparator) []solver.PBConstr {
+	switch cmp {
+	case LE:
+		return []solver.PBConstr{lessEq(lits, coeffs, bound)}
+	case EQ:
+		lits2 := make([]int, len(lits))
+		copy(lits2, lits)
+		var coeffs2 []int
+		if coeffs != nil {
+			coeffs2 = make([]int, len(coeffs))
+			copy(coeffs2, coeffs)
+		}
+		return []solver.PBConstr{solver.GtEq(lits, coeffs, bound), lessEq(lits2, coeffs2, bound)}
+	default:
+		return []solver.PBConstr{solver.GtEq(lits, coeffs, bound)}
+	}
+}
+
+// alwaysSat returns true iff all the given parts of the translation of a constraint are trivially satisfied,
+// e.g for a LE constraint whose bound is at least the sum of its coeffs, since their bound is 0 or less once translated.
+func alwaysSat(parts []solver.PBConstr) bool {
+	for _, c := range parts {
+		if c.AtLeast > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// lessEq is the same as solver.LtEq, except coeffs can be nil, in which case all coeffs are supposed to be 1.
+func lessEq(lits, coeffs []int, bound int) solver.PBConstr {
+	if coeffs == nil {
+		coeffs = make([]int, len(lits))
+		for i := range coeffs {
+			coeffs[i] = 1
+		}
+	}
+	return solver.LtEq(lits, coeffs, bound)
+}
+
+// relax adds the blocking literal bl to c, with a coefficient big enough for c to be satisfied as soon as bl is true.
+// If bl is 0, c is a hard constraint and is returned as is.
+func relax(c solver.PBConstr, bl int) solver.PBConstr {
+	if bl == 0 || c.AtLeast <= 0 { // Hard or trivially satisfied constraint: nothing to relax
+		return c
+	}
+	c.Lits = append(c.Lits, bl)
+	if c.Weights == nil && c.AtLeast == 1 { // This is a clause, there is no explicit coeff
+		return c
+	}
+	if c.Weights == nil { // Cardinality constraint: make coeffs explicit
+		c.Weights = make([]int, len(c.Lits)-1)
+		for i := range c.Weights {
+			c.Weights[i] = 1
+		}
+	}
+	c.Weights = append(c.Weights, c.AtLeast)
+	return c
+}
+
+// relaxCapped relaxes c, the translation of the soft constraint with index i, so that it costs weight
+// for each unit of violation, up to weightCap.
+// The first units of violation are each absorbed by an additional blocking lit with the given weight,
+// and the main blocking lit of the constraint, whose weight becomes the maximal cost, absorbs any violation.
+func (pb *Problem) relaxCapped(i int, c solver.PBConstr, weight, weightCap int) solver.PBConstr {
+	bl := pb.blocks[i]
+	if c.AtLeast <= 0 { // Trivially satisfied constraint
+		return c
+	}
+	maxCost := weightCap
+	if weightCap/weight >= c.AtLeast { // The constraint cannot be violated by more than AtLeast units
+		maxCost = c.AtLeast * weight
+	}
+	pb.maxWeight += maxCost - pb.blockWeights[bl]
+	pb.blockWeights[bl] = maxCost
+	nb := (maxCost+weight-1)/weight - 1 // Units of violation that cost less than maxCost
+	if nb == 0 {
+		return relax(c, bl)
+	}
+	if c.Weights == nil {
+		c.Weights = make([]int, len(c.Lits))
+		for j := range c.Weights {
+			c.Weights[j] = 1
+		}
+	}
+	lits := make([]int, nb)
+	for j := range lits {
+		pb.varInts = append(pb.varInts, "")
+		lits[j] = len(pb.varInts)
+		pb.blockWeights[lits[j]] = weight
+		c.Lits = append(c.Lits, lits[j])
+		c.Weights = append(c.Weights, 1)
+	}
+	if pb.capped == nil {
+		pb.capped = make(map[int]capped)
+	}
+	pb.capped[i] = capped{lits: lits, weight: weight}
+	c.Lits = append(c.Lits, bl)
+	c.Weights = append(c.Weights, c.AtLeast)
+	return c
+}
+
+// copyPBConstr returns a deep copy of c.
+// The solver takes ownership of the constraints it is given and can modify them, so a copy must be kept to output the problem.
+func copyPBConstr(c solver.PBConstr) solver.PBConstr {
+	res := solver.PBConstr{Lits: make([]int, len(c.Lits)), AtLeast: c.AtLeast}
+	copy(res.Lits, c.Lits)
+	if c.Weights != nil {
+		res.Weights = make([]int, len(c.Weights))
+		copy(res.Weights, c.Weights)
+	}
+	return res
+}
+
+// SetDecisionVars restricts the decisions of the underlying solver to the vars with the given names,
+// so that all other vars, including auxiliary ones such as blocking literals, are only bound by propagation,
+// which can speed up the search on problems with many auxiliary vars.
+// Decisions are still made on other vars if needed to complete a model, so the results do not change, only the search does.
+// Vars created afterwards, e.g by AddConstr, are excluded from decisions too, unless their name was given.
+// If names is nil, decisions can be made on all vars again.
+func (pb *Problem) SetDecisionVars(names []string) {
+	if names == nil {
+		pb.decisionVars = nil
+		for v := 0; v < pb.solver.NbVars(); v++ {
+			pb.solver.UnfreezeVar(solver.Var(v))
+		}
+		return
+	}
+	pb.decisionVars = make(map[string]bool, len(names))
+	for _, name := range names {
+		pb.decisionVars[name] = true
+	}
+	pb.freezeVars()
+}
+
+// freezeVars excludes from the decisions of the solver all vars that were not given to SetDecisionVars.
+func (pb *Problem) freezeVars() {
+	for v := 0; v < pb.solver.NbVars(); v++ {
+		if v < len(pb.varInts) && pb.decisionVars[pb.varInts[v]] {
+			pb.solver.UnfreezeVar(solver.Var(v))
+		} else {
+			pb.solver.FreezeVar(solver.Var(v))
+		}
+	}
+}
+
 // SetVerbose makes the underlying solver verbose, or not.
 func (pb *Problem) SetVerbose(verbose bool) {
 	pb.solver.Verbose = verbose
 }
 
+// NumVars returns the number of vars in the underlying solver.
+// This includes the vars of the constraints, but also the blocking literals of soft constraints
+// and all auxiliary vars created when translating the constraints.
+func (pb *Problem) NumVars() int {
+	if pb.dirty {
+		pb.build()
+	}
+	return pb.solver.NbVars()
+}
+
+// NumClauses returns the number of clauses and PB constraints in the underlying solver,
+// as they were produced by the translation of the constraints.
+// Unit clauses are not counted, since the solver directly turns them into bindings.
+func (pb *Problem) NumClauses() int {
+	if pb.dirty {
+		pb.build()
+	}
+	return pb.solver.NbClauses()
+}
+
 // Output output the problem to stdout in the OPB format.
 func (pb *Problem) Output() {
 	fmt.Println(pb.solver.PBString())
@@ -82,19 +979,852 @@ func (pb *Problem) Solver() *solver.Solver {
 	return pb.solver
 }
 
+// SetCostUpperBound constrains the problem so that only models whose cost is at most b are acceptable.
+// Contrary to adding a hard constraint, the bound can be changed or removed cheaply between two calls to Solve,
+// which is useful e.g when performing a binary search on the cost.
+// If no model has a cost of at most b, Solve will return a nil model.
+func (pb *Problem) SetCostUpperBound(b int) {
+	pb.costBound, pb.hasCostBound = b, true
+	pb.boundHint = false
+	pb.solver.SetCostBound(b)
+}
+
+// SetUpperBoundHint tells the problem that models whose cost is at most ub are expected to exist,
+// e.g because a heuristic found one, so that the search can focus on them.
+// The bound is enforced as with SetCostUpperBound, and, in addition, the soft constraints whose weight is greater than ub
+// are hardened, since no model meeting the bound can violate them.
+// For a constraint with a WeightCap, each unit of violation that would cost more than ub is forbidden.
+// Hardened constraints are enforced through assumptions, so that they are relaxed again when the bound is changed
+// or removed by ClearCostUpperBound, and a constraint disabled by DisableSoft is never hardened.
+// If the hint is wrong, i.e if no model has a cost of at most ub, Solve returns a nil model.
+func (pb *Problem) SetUpperBoundHint(ub int) {
+	pb.SetCostUpperBound(ub)
+	pb.boundHint = true
+}
+
+// hardened returns the negations of the blocking lits whose weight is greater than the bound set by SetUpperBoundHint,
+// sorted by increasing var, or nil if no such bound was set.
+func (pb *Problem) hardened() []solver.Lit {
+	if !pb.hasCostBound || !pb.boundHint || pb.linear != nil {
+		return nil
+	}
+	var vars []int
+	for v, w := range pb.blockWeights {
+		if w > pb.costBound {
+			vars = append(vars, v)
+		}
+	}
+	sort.Ints(vars)
+	lits := make([]solver.Lit, len(vars))
+	for i, v := range vars {
+		lits[i] = solver.IntToLit(int32(-v))
+	}
+	return lits
+}
+
+// ClearCostUpperBound removes the bound set by SetCostUpperBound or SetUpperBoundHint, if any.
+func (pb *Problem) ClearCostUpperBound() {
+	pb.hasCostBound = false
+	pb.boundHint = false
+	pb.solver.ClearCostBound()
+}
+
+// restoreCostBound enforces the bound set by SetCostUpperBound in the solver again, or removes any bound if there is none.
+// It is called after the solver's bound was used for other purposes.
+func (pb *Problem) restoreCostBound() {
+	if pb.hasCostBound {
+		pb.solver.SetCostBound(pb.costBound)
+	} else {
+		pb.solver.ClearCostBound()
+	}
+}
+
+// TestCostBound returns true iff there is a model whose cost is at most bound.
+// The bound is only enforced for this call, through an assumption, so clauses learned while testing it are kept.
+// A bound set by SetCostUpperBound is still enforced.
+// It can be used to implement custom search strategies for the optimal cost, such as OptimalByBinarySearch.
+func (pb *Problem) TestCostBound(bound int) bool {
+	return pb.testCostBound(bound) != nil
+}
+
+// testCostBound returns a model, as returned by the solver, whose cost is at most bound, or nil if there is none.
+func (pb *Problem) testCostBound(bound int) []bool {
+	if pb.hasCostBound && pb.costBound < bound {
+		bound = pb.costBound
+	}
+	if bound < 0 || pb.assume(nil) == solver.Unsat {
+		return nil
+	}
+	pb.solver.SetCostBound(bound)
+	defer pb.restoreCostBound()
+	if pb.solver.Solve() != solver.Sat {
+		return nil
+	}
+	return pb.solver.Model()
+}
+
+// OptimalByBinarySearch is like Solve, but the optimal cost is searched through a binary search,
+// with successive calls to TestCostBound, rather than by improving the cost of models one at a time.
+// The solver is reused between probes, so clauses learned by a probe help the next ones.
+// Depending on the problem, it can be faster or slower than Solve.
+func (pb *Problem) OptimalByBinarySearch() (Model, int) {
+	best := pb.testCostBound(pb.maxWeight)
+	if best == nil {
+		pb.lastModel = nil
+		return nil, -1
+	}
+	lo, hi := 0, pb.cost(best)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if model := pb.testCostBound(mid); model != nil {
+			best, hi = model, pb.cost(model)
+		} else {
+			lo = mid + 1
+		}
+	}
+	pb.lastModel = best
+	return pb.decode(best), hi
+}
+
 // Solve returns an optimal Model for the problem and the associated cost.
 // If the model is nil, the problem was not satisfiable (i.e hard clauses could not be satisfied).
 func (pb *Problem) Solve() (Model, int) {
+	cost := pb.solve()
+	if cost == -1 {
+		return nil, -1
+	}
+	return pb.decode(pb.lastModel), cost
+}
+
+// SolveCost is like Solve, but it only returns the optimal cost, or -1 if the problem is not satisfiable.
+// The optimal model is not decoded, which saves time and memory on large problems when only the cost matters,
+// but it is still the last model of the problem, e.g for Broken.
+func (pb *Problem) SolveCost() int {
+	return pb.solve()
+}
+
+// solve searches for an optimal model, stores it as the last model of the problem, and returns its cost,
+// or -1 if the problem is not satisfiable.
+//
+// The feasibility of the hard constraints is cached: once they are known to be unsatisfiable, -1 is returned
+// without searching, and once a model of them is known, it is used as the first incumbent of the search,
+// which starts right away with a bound on the cost, rather than searching for a first model again.
+// The cache is kept when only the cost function changes, e.g through SetSoftWeight or SolveAt,
+// so that solving the same problem with different weights is cheaper. See InvalidateHardCache.
+// Solve, SolveCost, SolveBits and SolveWithBudget all go through the cache.
+func (pb *Problem) solve() int {
+	return pb.solveWithin(pb.costBound, pb.hasCostBound)
+}
+
+// solveWithin is like solve, but if bounded is true, only models whose cost is at most bound are acceptable.
+// When no such model exists, -1 is returned, but the hard constraints are not deemed unsatisfiable.
+func (pb *Problem) solveWithin(bound int, bounded bool) int {
+	defer pb.closeStats()
+	pb.lastModel = nil
+	pb.interrupted = false
+	if pb.hardUnsat || (pb.hardModel == nil && !pb.checkHard()) {
+		return -1
+	}
+	if !pb.HasObjective() { // Nothing to optimize: any model is optimal
+		pb.useHardModel()
+		return 0
+	}
+	cost := pb.cost(pb.hardModel)
+	if bounded && cost > bound { // The known model is not acceptable: search from scratch
+		pb.solver.SetCostBound(bound)
+		defer pb.restoreCostBound()
+		pb.assume(nil)
+		cost = pb.solver.Minimize()
+		pb.interrupted = pb.solver.Err() == solver.ErrStopped
+		if cost == -1 {
+			return -1
+		}
+		pb.lastModel = pb.solver.Model()
+		pb.hardModel = pb.lastModel
+		return cost
+	}
+	if cost == 0 || cost <= pb.coreBound { // Known to be optimal
+		pb.useHardModel()
+		return cost
+	}
+	pb.solver.SetCostBound(cost - 1) // Tighter than the bound set by SetCostUpperBound, if any
+	defer pb.restoreCostBound()
+	pb.assume(nil)
+	better := pb.solver.Minimize()
+	pb.interrupted = pb.solver.Err() == solver.ErrStopped
+	if better == -1 { // The known model is optimal, or the search was stopped before a better one was found
+		pb.restoreCostBound()
+		pb.useHardModel()
+		return cost
+	}
+	pb.hardModel = pb.solver.Model()
+	pb.lastModel = pb.hardModel
+	return better
+}
+
+// useHardModel makes the cached model of the hard constraints the last model of the problem.
+// The underlying solver is made to find it again, with all its lits assumed, so that only unit propagation is needed:
+// its state, e.g its Model, then agrees with the result of Solve, as if the model had been searched for.
+func (pb *Problem) useHardModel() {
+	n := len(pb.hardModel)
+	if nbVars := pb.solver.NbVars(); nbVars < n {
+		n = nbVars
+	}
+	lits := make([]solver.Lit, n)
+	for i := range lits {
+		v := int32(i + 1)
+		if !pb.hardModel[i] {
+			v = -v
+		}
+		lits[i] = solver.IntToLit(v)
+	}
+	pb.assume(lits)
+	pb.solver.Solve()
+	pb.assume(nil)
+	pb.lastModel = pb.hardModel
+}
+
+// checkHard searches for a model of the hard constraints, ignoring the cost function, and caches the result,
+// as described in solve. It returns true iff such a model was found.
+// Infeasibility is only cached when it does not depend on the blocking lits hardened by SetUpperBoundHint.
+func (pb *Problem) checkHard() bool {
+	pb.assume(nil) // Rebuilds the solver, if needed
+	pb.solver.ClearCostBound()
+	defer pb.restoreCostBound()
+	hardened := len(pb.hardened()) != 0
+	status := pb.solver.Solve()
+	pb.interrupted = pb.solver.Err() == solver.ErrStopped
+	switch {
+	case status == solver.Sat:
+		pb.hardModel = pb.solver.Model()
+		return true
+	case status == solver.Unsat && !hardened:
+		pb.hardUnsat = true
+	}
+	return false
+}
+
+// InvalidateHardCache forgets the cached feasibility of the hard constraints, as used by Solve,
+// so that the next call to Solve establishes it again.
+// The cache is already invalidated when the hard constraints are changed through the methods of the problem,
+// such as AddConstr, AddRetractable, Retract, TagConstr or SetTagEnabled, so it is only needed when
+// constraints are added to the underlying solver directly, through Solver.
+func (pb *Problem) InvalidateHardCache() {
+	pb.hardModel = nil
+	pb.hardUnsat = pb.contradiction != nil // Contradictions found by TriviallyInfeasible cannot be undone by such changes
+}
+
+// SolveFirstThenOptimal is like Solve, but it also returns the first model found while minimizing and its cost,
+// e.g to show a valid model to the user while the optimal one is searched.
+// The first model is the first incumbent of the search, so it does not require any additional call to the solver:
+// each model found is then required to be improved upon, until no better model exists.
+// It returns the first model, its cost, the optimal model, its cost and the indices of the soft constraints
+// the optimal model violates, sorted in increasing order. Both models are the same if the first one is optimal.
+// If the problem is not satisfiable, it returns nil, -1, nil, -1 and nil.
+func (pb *Problem) SolveFirstThenOptimal() (first Model, firstCost int, optimal Model, optCost int, broken []int) {
+	defer pb.closeStats()
+	pb.lastModel = nil
+	if pb.assume(nil) == solver.Unsat || pb.solver.Solve() != solver.Sat {
+		return nil, -1, nil, -1, nil
+	}
+	model := pb.solver.Model()
+	firstCost = pb.cost(model)
+	first = pb.decode(model)
+	cost := firstCost
+	if pb.HasObjective() {
+		defer pb.restoreCostBound()
+		for cost > pb.coreBound { // Otherwise, the model is known to be optimal
+			pb.solver.SetCostBound(cost - 1)
+			if pb.solver.Solve() != solver.Sat {
+				break
+			}
+			model = pb.solver.Model()
+			cost = pb.cost(model)
+		}
+	}
+	pb.lastModel = model
+	return first, firstCost, pb.decode(model), cost, pb.broken(model)
+}
+
+// SolveWithBudget is like Solve, but only models whose cost is at most budget are acceptable:
+// it returns the optimal model among them, its cost, the indices of the soft constraints it violates,
+// sorted in increasing order, and true, or nil, -1, nil and false if no model fits in the budget.
+// The budget is only enforced for this call, so it does not impact later calls to Solve,
+// and clauses learned while solving are kept. A bound set by SetCostUpperBound is still enforced.
+func (pb *Problem) SolveWithBudget(budget int) (Model, int, []int, bool) {
+	if pb.hasCostBound && pb.costBound < budget {
+		budget = pb.costBound
+	}
+	pb.lastModel = nil
+	if budget < 0 {
+		return nil, -1, nil, false
+	}
+	cost := pb.solveWithin(budget, true)
+	if cost == -1 {
+		return nil, -1, nil, false
+	}
+	return pb.decode(pb.lastModel), cost, pb.broken(pb.lastModel), true
+}
+
+// SolveMax is like Solve, but it returns the total weight of the soft constraints satisfied by the optimal model,
+// i.e MaxWeight() minus its cost, instead of the cost itself.
+// If the model is nil, the problem was not satisfiable and the returned weight is -1.
+func (pb *Problem) SolveMax() (Model, int) {
+	model, cost := pb.Solve()
+	if model == nil {
+		return nil, -1
+	}
+	return model, pb.maxWeight - cost
+}
+
+// HasObjective returns true iff the problem has a soft constraint whose weight is not 0, i.e iff there is a cost to minimize.
+// If it returns false, all models have a cost of 0, and Solve only looks for a model of the hard constraints.
+// Soft constraints that are trivially satisfied, or disabled by DisableSoft, are not taken into account.
+// If a linear objective was set by SetLinearObjective, it returns true iff it has a term with a coefficient that is not 0.
+func (pb *Problem) HasObjective() bool {
+	if pb.linear != nil {
+		lits, _ := pb.linearObjective()
+		return len(lits) != 0
+	}
+	return pb.maxWeight > 0
+}
+
+// MaxWeight returns the sum of the weights of all soft constraints in the problem.
+// This is the cost of a model violating all of them, and the weight returned by SolveMax
+// for a model satisfying all of them.
+func (pb *Problem) MaxWeight() int {
+	return pb.maxWeight
+}
+
+// SolveFixing is like Solve, but the given vars are fixed to the given values.
+// The cost is then minimized over the remaining vars, and the indices of the soft constraints violated
+// by the returned model are also returned, sorted in increasing order.
+// Fixed values are assumed for this call only: they do not impact later calls to Solve.
+// Vars that do not appear in the problem are simply given their fixed value in the returned model.
+// If the hard constraints cannot be satisfied with the given fixed values, it returns nil, -1 and nil.
+func (pb *Problem) SolveFixing(fixed map[string]bool) (Model, int, []int) {
+	defer pb.assume(nil)
+	names := make([]string, 0, len(fixed))
+	for name := range fixed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lits := make([]solver.Lit, 0, len(fixed))
+	for _, name := range names {
+		v, ok := pb.intVars[name]
+		if !ok {
+			continue
+		}
+		if !fixed[name] {
+			v = -v
+		}
+		lits = append(lits, solver.IntToLit(int32(v)))
+	}
+	model, cost, broken := pb.solveUnder(lits)
+	for _, name := range names {
+		if _, ok := pb.intVars[name]; !ok && model != nil {
+			model[name] = fixed[name]
+		}
+	}
+	return model, cost, broken
+}
+
+// solveUnder minimizes the cost under the given assumptions, as described in SolveFixing.
+// The caller is responsible for assuming nil afterwards.
+func (pb *Problem) solveUnder(lits []solver.Lit) (Model, int, []int) {
+	if pb.assume(lits) == solver.Unsat {
+		pb.lastModel = nil
+		return nil, -1, nil
+	}
 	cost := pb.solver.Minimize()
 	if cost == -1 {
+		pb.lastModel = nil
+		return nil, -1, nil
+	}
+	pb.lastModel = pb.solver.Model()
+	return pb.decode(pb.lastModel), cost, pb.broken(pb.lastModel)
+}
+
+// MinimizeUnder is like SolveFixing, but it is meant to be called repeatedly with different assumptions,
+// with as little work as possible for each call.
+// Clauses learned during a call are kept for the next ones, and optimal costs are remembered:
+// if the given assumptions include all the assumptions of a previous call, the cost found by that call
+// is a lower bound on the new optimal cost, so the search stops as soon as a model with such a cost is found,
+// instead of proving no better model exists.
+// Remembered costs are forgotten when a soft constraint is disabled or enabled, when a tag is disabled,
+// or when a constraint is retracted.
+func (pb *Problem) MinimizeUnder(assumptions map[string]bool) (Model, int, []int) {
+	lb := pb.coreBound
+	for _, opt := range pb.optima {
+		if opt.cost > lb && includes(assumptions, opt.assumptions) {
+			lb = opt.cost
+		}
+	}
+	pb.solver.SetCostLowerBound(lb)
+	defer pb.solver.SetCostLowerBound(pb.coreBound)
+	model, cost, broken := pb.SolveFixing(assumptions)
+	if model != nil {
+		fixed := make(map[string]bool, len(assumptions))
+		for name, val := range assumptions {
+			fixed[name] = val
+		}
+		pb.optima = append(pb.optima, optimum{assumptions: fixed, cost: cost})
+	}
+	return model, cost, broken
+}
+
+// includes returns true iff all the bindings in sub also appear in m.
+func includes(m, sub map[string]bool) bool {
+	for name, val := range sub {
+		if val2, ok := m[name]; !ok || val2 != val {
+			return false
+		}
+	}
+	return true
+}
+
+// SolveSat returns a model satisfying all hard constraints, ignoring the cost function, and true,
+// or nil and false if the hard constraints cannot be satisfied.
+// It only needs one call to the underlying SAT solver, so it is much cheaper than Solve
+// and can be used as a quick feasibility check before optimizing.
+// The returned model is not necessarily optimal, and soft constraints may be violated, although
+// the solver tries to satisfy them.
+// A bound on the cost set with SetCostUpperBound is still enforced.
+func (pb *Problem) SolveSat() (Model, bool) {
+	if pb.assume(nil) == solver.Unsat || pb.solver.Solve() != solver.Sat {
+		return nil, false
+	}
+	return pb.decode(pb.solver.Model()), true
+}
+
+// Entails returns true iff the hard constraints imply the clause made of the given lits,
+// i.e iff no model of the hard constraints falsifies all the lits.
+// This is checked by assuming the negation of the lits, so the problem is not modified,
+// and the clauses learned by the solver are kept for later calls.
+// Soft constraints, and the bound set by SetCostUpperBound, if any, are ignored.
+// Lits on vars that do not appear in the problem can always be falsified, so they are ignored.
+// If the hard constraints cannot be satisfied, all clauses are entailed.
+func (pb *Problem) Entails(lits []Lit) bool {
+	defer pb.assume(nil)
+	pb.assume(nil) // Rebuilds the solver, if needed
+	pb.solver.ClearCostBound()
+	defer pb.restoreCostBound()
+	negs := make([]solver.Lit, 0, len(lits))
+	for _, lit := range lits {
+		v, ok := pb.intVars[lit.Var]
+		if !ok {
+			continue
+		}
+		if !lit.Negated {
+			v = -v
+		}
+		negs = append(negs, solver.IntToLit(int32(v)))
+	}
+	return pb.assume(negs) == solver.Unsat || pb.solver.Solve() == solver.Unsat
+}
+
+// WhyVar explains the binding of the var with the given name in the last model found, e.g by Solve.
+// All other vars of the problem are assumed to have their binding in that model, and if the binding of the var
+// is then forced by propagation, WhyVar returns the lits of the constraint that forced it, as in the translation
+// of the constraint given to the solver, and true. Lits are given as their var's label, prefixed by "¬" if negated;
+// auxiliary vars without a label, such as control vars, are given as "x" followed by their id, as in WriteOPB.
+// It returns nil and false if no model was found yet, if the var does not appear in the problem,
+// or if its binding is not forced by the bindings of the other vars.
+// The bound set by SetCostUpperBound, if any, is ignored.
+func (pb *Problem) WhyVar(name string) ([]string, bool) {
+	v, ok := pb.intVars[name]
+	if !ok || pb.lastModel == nil {
+		return nil, false
+	}
+	model := pb.lastModel
+	defer pb.assume(nil)
+	pb.assume(nil) // Rebuilds the solver, if needed
+	pb.solver.ClearCostBound()
+	defer pb.restoreCostBound()
+	var lits []solver.Lit
+	for v2, name2 := range pb.varInts {
+		if name2 != "" && v2+1 != v && v2 < len(model) {
+			lits = append(lits, solver.Var(v2).SignedLit(!model[v2]))
+		}
+	}
+	if pb.assume(lits) == solver.Unsat {
+		return nil, false
+	}
+	reason, ok := pb.solver.ReasonFor(solver.Var(v - 1))
+	if !ok {
+		return nil, false
+	}
+	res := make([]string, len(reason))
+	for i, lit := range reason {
+		id := int(lit.Var()) + 1
+		label := ""
+		if id <= len(pb.varInts) {
+			label = pb.label(id)
+		}
+		if label == "" {
+			label = fmt.Sprintf("x%d", id)
+		}
+		if !lit.IsPositive() {
+			label = "¬" + label
+		}
+		res[i] = label
+	}
+	return res, true
+}
+
+// SolveMinTrue returns a model of the hard constraints where as few of the given vars as possible are true,
+// along with the number of such true vars, or nil and -1 if the hard constraints cannot be satisfied.
+// Soft constraints, and the bound set by SetCostUpperBound, if any, are ignored, and so are unknown vars.
+// The cost function of the problem is only replaced for this call, so later calls to Solve are not impacted.
+func (pb *Problem) SolveMinTrue(vars []string) (Model, int) {
+	if pb.assume(nil) == solver.Unsat {
 		return nil, -1
 	}
+	defer pb.solver.SetCostLowerBound(pb.coreBound)
+	defer pb.restoreCostBound()
+	weights := make([]int, pb.solver.NbVars())
+	for _, name := range vars {
+		if v, ok := pb.intVars[name]; ok {
+			weights[v-1] = 1
+		}
+	}
+	model, nb := pb.solver.SolveMinWeight(weights)
+	if model == nil {
+		return nil, -1
+	}
+	return pb.decode(model), nb
+}
+
+// HotVars returns the names of the n vars with the highest activity in the underlying solver, by decreasing activity,
+// as given by solver.Solver.TopActivityVars. Auxiliary vars, such as blocking literals, are ignored.
+// It must not be called while the problem is being solved.
+func (pb *Problem) HotVars(n int) []string {
+	var res []string
+	for _, v := range pb.solver.TopActivityVars(pb.solver.NbVars()) {
+		if len(res) >= n {
+			break
+		}
+		if i := int(v); i < len(pb.varInts) && pb.varInts[i] != "" {
+			res = append(res, pb.varInts[i])
+		}
+	}
+	return res
+}
+
+// Broken returns the indices of the soft constraints violated by the model last returned by Solve or SolveFixing.
+// Indices are the positions of the constraints in the list given to New, and are sorted in increasing order,
+// no matter how variables were numbered internally.
+// If no model was searched yet or no model was found, it returns nil.
+func (pb *Problem) Broken() []int {
+	if pb.lastModel == nil {
+		return nil
+	}
+	return pb.broken(pb.lastModel)
+}
+
+// CostBreakdown returns, for each soft constraint violated by the model last returned by Solve or SolveFixing,
+// its contribution to the cost of the model, i.e its weight or, for a constraint with a WeightCap, the cost of its violation, indexed by the position of the constraint,
+// as in Broken. The sum of all values is the cost of the model.
+// If no model was searched yet or no model was found, it returns nil.
+func (pb *Problem) CostBreakdown() map[int]int {
+	if pb.lastModel == nil {
+		return nil
+	}
+	res := make(map[int]int)
+	for _, i := range pb.broken(pb.lastModel) {
+		res[i] = pb.softCost(i, pb.lastModel)
+	}
+	return res
+}
+
+// cost returns the cost of the given solver model, i.e the sum of the costs of the soft constraints it violates,
+// or its value w.r.t the linear objective set by SetLinearObjective, if any.
+func (pb *Problem) cost(model []bool) int {
+	cost := 0
+	if pb.linear != nil {
+		lits, weights := pb.linearObjective()
+		for i, lit := range lits {
+			if model[abs(lit)-1] == (lit > 0) {
+				cost += weights[i]
+			}
+		}
+		return cost
+	}
+	for _, i := range pb.broken(model) {
+		cost += pb.softCost(i, model)
+	}
+	return cost
+}
+
+// broken returns the indices of the soft constraints one of whose blocking literals is true in the given solver model,
+// in increasing order.
+func (pb *Problem) broken(model []bool) []int {
+	var res []int
+	for i := range pb.blocks {
+		if pb.soft(i) && pb.softCost(i, model) != 0 {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+// decode returns the Model associated with the given solver model.
+// Blocking literals and any other auxiliary variable are ignored.
+func (pb *Problem) decode(model []bool) Model {
 	res := make(Model)
-	for i, binding := range pb.solver.Model() {
-		name := pb.varInts[i]
+	for i, name := range pb.varInts {
 		if name != "" { // Ignore blocking lits
-			res[name] = binding
+			res[name] = model[i]
+		}
+	}
+	return res
+}
+
+// AllSatisfiable returns true iff all constraints, hard and soft, can be satisfied together,
+// i.e iff a model with a cost of 0 exists.
+// It is cheaper than Solve, as it only needs one call to the underlying SAT solver, which is reused:
+// the soft constraints are enforced through assumptions, so later calls to Solve are not impacted.
+func (pb *Problem) AllSatisfiable() bool {
+	defer pb.assume(nil)
+	var idx []int
+	for i := range pb.blocks {
+		if pb.soft(i) {
+			idx = append(idx, i)
+		}
+	}
+	return pb.assume(pb.negSoftLits(idx)) != solver.Unsat && pb.solver.Solve() == solver.Sat
+}
+
+// MaximalSatisfiableSubset returns the indices of the soft constraints in a maximum satisfiable subset (MSS),
+// along with their total weight.
+// A MSS is a set of soft constraints that can be satisfied together with the hard constraints,
+// and the returned one has the greatest total weight among them, so it is the complement of the constraints
+// violated by an optimal model. Since weights are positive, it is also maximal: adding any other soft constraint
+// to it would make it unsatisfiable.
+// Unlike the cost minimized by Solve, the weight of a constraint with a WeightCap is its maximal cost,
+// whatever its violation, and the linear objective set by SetLinearObjective, if any, is ignored.
+// Bounds set by SetCostUpperBound or SetUpperBoundHint are ignored too.
+// Indices are sorted in increasing order.
+// If the hard constraints cannot be satisfied, it returns nil and -1.
+func (pb *Problem) MaximalSatisfiableSubset() ([]int, int) {
+	defer func(hint bool) { pb.boundHint = hint }(pb.boundHint)
+	pb.boundHint = false // Constraints hardened by the hint are soft ones
+	defer pb.assume(nil)
+	if pb.assume(nil) == solver.Unsat { // Also rebuilds the solver if needed
+		return nil, -1
+	}
+	var lits []solver.Lit
+	var weights []int
+	for i := range pb.blocks {
+		if !pb.soft(i) {
+			continue
+		}
+		// The main blocking lit alone can absorb any violation, so charging the whole weight for each blocking lit
+		// of a capped constraint does not change which constraints are satisfied by an optimal model
+		for _, bl := range pb.softLits(i) {
+			lits = append(lits, solver.IntToLit(int32(bl)))
+			weights = append(weights, pb.blockWeights[pb.blocks[i]])
+		}
+	}
+	defer pb.updateCostFunc()
+	pb.solver.SetCostFunc(lits, weights)
+	if pb.solver.Minimize() == -1 {
+		return nil, -1
+	}
+	model := pb.solver.Model()
+	var mss []int
+	weight := 0
+	for i := range pb.blocks {
+		if pb.soft(i) && pb.softCost(i, model) == 0 {
+			mss = append(mss, i)
+			weight += pb.blockWeights[pb.blocks[i]]
+		}
+	}
+	return mss, weight
+}
+
+// DisjointCores returns up to limit disjoint cores of the problem.
+// A core is a set of soft constraints that cannot be satisfied together with the hard constraints.
+// Each core is given as the sorted list of the indices of its constraints, and is minimal:
+// removing any constraint from it makes it satisfiable.
+// Once a core has been found, its constraints are relaxed and another core is searched among the remaining
+// soft constraints, until limit cores are found or the remaining soft constraints are satisfiable.
+// Cores are thus pairwise disjoint, but other, overlapping, cores might exist.
+// If all constraints can be satisfied together, or if the hard constraints cannot be satisfied, it returns nil.
+func (pb *Problem) DisjointCores(limit int) [][]int {
+	defer pb.assume(nil)
+	if pb.assume(nil) == solver.Unsat || pb.solver.Solve() != solver.Sat {
+		return nil
+	}
+	var active []int // Soft constraints that are not part of any core yet
+	for i := range pb.blocks {
+		if pb.soft(i) {
+			active = append(active, i)
+		}
+	}
+	var cores [][]int
+	for len(cores) < limit && pb.unsatSoft(active) {
+		core := pb.minimalCore(active)
+		cores = append(cores, core)
+		inCore := make(map[int]bool, len(core))
+		for _, c := range core {
+			inCore[c] = true
+		}
+		remaining := active[:0]
+		for _, c := range active {
+			if !inCore[c] {
+				remaining = append(remaining, c)
+			}
+		}
+		active = remaining
+	}
+	return cores
+}
+
+// OptimalityCertificate returns a lower bound on the cost of the models of the problem, along with the cores
+// that establish it.
+// A core is a set of soft constraints that cannot be satisfied together with the hard constraints,
+// given as the sorted list of the indices of its constraints.
+// Cores are found one after the other, and each of them is given a weight, which is the minimum, among its constraints,
+// of their weight minus the weights of the previous cores containing them. That weight is then charged to each
+// of its constraints, and constraints whose weight is fully charged are not part of the following cores.
+// The weight of a constraint with a WeightCap is the cost of a single unit of violation.
+// Since any model violates at least one constraint in each core, its cost is at least the sum of the weights
+// of the cores, which is the returned lower bound.
+// When the lower bound is equal to the cost returned by Solve, the cores and the model returned by Solve
+// thus form a certificate of the optimality of the model, that can be checked independently.
+// Otherwise, the lower bound is lower than the optimal cost, and the optimality of the model cannot be proven that way.
+// If the hard constraints cannot be satisfied, it returns -1 and nil.
+func (pb *Problem) OptimalityCertificate() (lowerBound int, cores [][]int) {
+	defer pb.assume(nil)
+	if pb.assume(nil) == solver.Unsat || pb.solver.Solve() != solver.Sat {
+		return -1, nil
+	}
+	weights := make(map[int]int) // For each soft constraint, its weight that was not charged to a core yet
+	for i := range pb.blocks {
+		if pb.soft(i) {
+			weights[i] = pb.minCost(i)
+		}
+	}
+	for {
+		var active []int
+		for i := range pb.blocks {
+			if pb.soft(i) && weights[i] > 0 {
+				active = append(active, i)
+			}
+		}
+		if !pb.unsatSoft(active) {
+			return lowerBound, cores
+		}
+		core := pb.minimalCore(active)
+		w := weights[core[0]]
+		for _, c := range core[1:] {
+			if weights[c] < w {
+				w = weights[c]
+			}
+		}
+		for _, c := range core {
+			weights[c] -= w
+		}
+		lowerBound += w
+		cores = append(cores, core)
+	}
+}
+
+// SeedCores gives the solver cores found while solving a previous version of the problem,
+// e.g by OptimalityCertificate, so that they do not have to be found again.
+// Each core is a list of indices of soft constraints that cannot be satisfied together with the hard constraints.
+// Cores that are not valid anymore, because they are satisfiable or refer to constraints that are not soft,
+// are dropped. Each valid core is added to the solver as a lemma stating that one of its constraints is broken,
+// and the cores are used to compute a lower bound on the optimal cost, so that Solve can stop as soon as
+// it finds a model with such a cost. The lower bound is forgotten when the weights of soft constraints change,
+// when a tag is disabled, or when a constraint is retracted.
+// It returns the lower bound, or 0 if a linear objective was set by SetLinearObjective, in which case cores are ignored.
+func (pb *Problem) SeedCores(cores [][]int) int {
+	if pb.linear != nil {
+		return 0
+	}
+	defer pb.assume(nil)
+	defer func(hint bool) { pb.boundHint = hint }(pb.boundHint)
+	pb.boundHint = false // Cores must not depend on hardened constraints, since lemmas outlive the hint
+	if pb.assume(nil) == solver.Unsat {
+		return 0
+	}
+	var guards []int // Negations of the assumed control and activation vars, so that lemmas stay valid when they are not assumed anymore
+	for i, ctrl := range pb.ctrls {
+		if !pb.disabled[pb.tags[i]] {
+			guards = append(guards, -ctrl)
+		}
+	}
+	for _, act := range pb.activations() {
+		guards = append(guards, -act)
+	}
+	weights := make(map[int]int) // For each soft constraint, its weight that was not charged to a core yet
+	for i := range pb.blocks {
+		if pb.soft(i) {
+			weights[i] = pb.minCost(i)
+		}
+	}
+	lb := 0
+	for _, core := range cores {
+		if !pb.validCore(core) {
+			continue
+		}
+		lemma := append([]int(nil), guards...)
+		w := -1
+		for _, c := range core {
+			lemma = append(lemma, pb.softLits(c)...)
+			if w == -1 || weights[c] < w {
+				w = weights[c]
+			}
+		}
+		if err := pb.solver.AddLemma(lemma); err != nil {
+			panic(err) // Cannot happen: blocking lits and control vars are never null
+		}
+		for _, c := range core {
+			weights[c] -= w
+		}
+		lb += w
+	}
+	pb.coreBound = lb
+	pb.solver.SetCostLowerBound(lb)
+	return lb
+}
+
+// validCore returns true iff the given indices are the distinct indices of soft constraints that cannot be satisfied together
+// with the hard constraints.
+func (pb *Problem) validCore(core []int) bool {
+	if len(core) == 0 {
+		return false
+	}
+	seen := make(map[int]bool, len(core))
+	for _, c := range core {
+		if c < 0 || c >= len(pb.blocks) || !pb.soft(c) || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return pb.unsatSoft(core)
+}
+
+// unsatSoft returns true iff the soft constraints with the given indices cannot be satisfied together
+// with the hard constraints.
+func (pb *Problem) unsatSoft(idx []int) bool {
+	return pb.assume(pb.negSoftLits(idx)) == solver.Unsat || pb.solver.Solve() == solver.Unsat
+}
+
+// minimalCore returns a minimal subset of the given soft constraints that cannot be satisfied together
+// with the hard constraints. The given constraints must not be satisfiable together.
+// Constraints are removed one after the other, as long as the remaining ones are still unsatisfiable.
+func (pb *Problem) minimalCore(idx []int) []int {
+	core := append([]int(nil), idx...)
+	for i := 0; i < len(core); {
+		candidate := append(append([]int(nil), core[:i]...), core[i+1:]...)
+		if pb.unsatSoft(candidate) {
+			core = candidate
+		} else {
+			i++
 		}
 	}
-	return res, cost
+	return core
 }
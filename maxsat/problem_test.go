@@ -1,9 +1,16 @@
 package maxsat
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/crillab/gophersat/bf"
+	"github.com/crillab/gophersat/solver"
 )
 
 func TestUnsat(t *testing.T) {
@@ -124,6 +131,161 @@ func TestTSP(t *testing.T) {
 	}
 }
 
+func TestStep(t *testing.T) {
+	constrs := generateTSP(7)
+	_, optimum := New(constrs...).Solve()
+	pb := New(constrs...)
+	nbSteps := 0
+	lastCost := -1
+	for {
+		nbSteps++
+		done, model, cost := pb.Step(20)
+		if model == nil != (cost == -1) {
+			t.Fatalf("invalid step result: model %v with cost %d", model, cost)
+		}
+		if lastCost != -1 && (cost == -1 || cost > lastCost) {
+			t.Fatalf("cost went from %d to %d", lastCost, cost)
+		}
+		lastCost = cost
+		if done {
+			break
+		}
+	}
+	if lastCost != optimum {
+		t.Errorf("invalid final cost: expected %d, got %d", optimum, lastCost)
+	}
+	if nbSteps < 2 {
+		t.Errorf("expected several steps, got %d", nbSteps)
+	}
+	if _, cost := pb.Solve(); cost != optimum {
+		t.Errorf("invalid cost after stepping: expected %d, got %d", optimum, cost)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	constrs := generateTSP(7)
+	_, optimum := New(constrs...).Solve()
+	pb := New(constrs...)
+	pb.SetVarLabel(constrs[0].Lits[0].Var, "first")
+	var cost int
+	for {
+		var done bool
+		done, _, cost = pb.Step(20)
+		if done {
+			t.Skip("optimum found too fast to test resuming")
+		}
+		if cost != -1 {
+			break
+		}
+	}
+	var buf bytes.Buffer
+	if err := pb.Checkpoint(&buf); err != nil {
+		t.Fatalf("could not checkpoint: %v", err)
+	}
+	pb2, err := Resume(&buf)
+	if err != nil {
+		t.Fatalf("could not resume: %v", err)
+	}
+	if pb2.MaxWeight() != pb.MaxWeight() {
+		t.Errorf("resumed problem has max weight %d, expected %d", pb2.MaxWeight(), pb.MaxWeight())
+	}
+	sum := 0
+	for _, w := range pb2.CostBreakdown() {
+		sum += w
+	}
+	if sum != cost {
+		t.Errorf("incumbent of resumed problem has cost %d, expected %d", sum, cost)
+	}
+	for {
+		done, model, cost2 := pb2.Step(20)
+		if model == nil || cost2 > cost {
+			t.Fatalf("resumed search should improve on incumbent with cost %d, got %v with cost %d", cost, model, cost2)
+		}
+		if done {
+			cost = cost2
+			break
+		}
+	}
+	if cost != optimum {
+		t.Errorf("invalid cost after resuming: expected %d, got %d", optimum, cost)
+	}
+	if _, cost := pb2.Solve(); cost != optimum {
+		t.Errorf("invalid cost when solving resumed problem: expected %d, got %d", optimum, cost)
+	}
+	var out1, out2 bytes.Buffer
+	pb.WriteOPB(&out1)
+	pb2.WriteOPB(&out2)
+	if out1.String() != out2.String() {
+		t.Errorf("resumed problem should have the same OPB output")
+	}
+	if _, err := Resume(strings.NewReader("garbage")); err == nil {
+		t.Errorf("expected an error for invalid checkpoint")
+	}
+	if err := Wrap(solver.ParseSlice([][]int{{1}}), nil).Checkpoint(&buf); err == nil {
+		t.Errorf("expected an error for wrapped problem")
+	}
+}
+
+func TestCheckpointSettings(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("b")),
+		WeightedClause([]Lit{Var("a")}, 1),
+		WeightedClause([]Lit{Var("b")}, 1),
+		WeightedClause([]Lit{Var("c")}, 5),
+	)
+	pb.SetWeightFunction(2, 5, -1)
+	pb.SetUpperBoundHint(3)
+	pb.SetDecisionVars([]string{})
+	var buf bytes.Buffer
+	if err := pb.Checkpoint(&buf); err != nil {
+		t.Fatalf("could not checkpoint: %v", err)
+	}
+	pb2, err := Resume(&buf)
+	if err != nil {
+		t.Fatalf("could not resume: %v", err)
+	}
+	if hardened := fmt.Sprint(pb2.hardened()); hardened != fmt.Sprint(pb.hardened()) || hardened == "[]" {
+		t.Errorf("invalid hardened lits after resuming: expected %v, got %s", pb.hardened(), hardened)
+	}
+	if pb2.decisionVars == nil || len(pb2.decisionVars) != 0 {
+		t.Errorf("invalid decision vars after resuming: expected an empty set, got %v", pb2.decisionVars)
+	}
+	if funcs := fmt.Sprint(pb2.weightFuncs); funcs != fmt.Sprint(pb.weightFuncs) {
+		t.Errorf("invalid weight functions after resuming: expected %v, got %s", pb.weightFuncs, funcs)
+	}
+	model, cost, broken := pb.SolveAt(3)
+	model2, cost2, broken2 := pb2.SolveAt(3)
+	if fmt.Sprint(model2) != fmt.Sprint(model) || cost2 != cost || fmt.Sprint(broken2) != fmt.Sprint(broken) {
+		t.Errorf("invalid results after resuming: expected %v, %d and %v, got %v, %d and %v", model, cost, broken, model2, cost2, broken2)
+	}
+}
+
+func TestStatsStream(t *testing.T) {
+	pb := New(generateTSP(9)...)
+	stream := pb.StatsStream(0)
+	done := make(chan int)
+	go func() {
+		_, cost := pb.Solve()
+		done <- cost
+	}()
+	nb := 0
+	lastConflicts := 0
+	for st := range stream {
+		nb++
+		if st.NbConflicts < lastConflicts {
+			t.Errorf("number of conflicts decreased from %d to %d", lastConflicts, st.NbConflicts)
+		}
+		lastConflicts = st.NbConflicts
+	}
+	if cost := <-done; cost <= 0 {
+		t.Errorf("invalid cost %d", cost)
+	}
+	if nb == 0 {
+		t.Errorf("expected at least one snapshot")
+	}
+}
+
 func BenchmarkTSP(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		New(generateTSP(9)...).Solve()
@@ -135,3 +297,2182 @@ func BenchmarkTSP10(b *testing.B) {
 		New(generateTSP(10)...).Solve()
 	}
 }
+
+func TestMaximalSatisfiableSubset(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 3),
+		SoftClause(Var("a")),
+	)
+	for i := 0; i < 2; i++ { // Computing the MSS must not prevent from solving the problem, and vice-versa
+		if mss, weight := pb.MaximalSatisfiableSubset(); len(mss) != 2 || mss[0] != 2 || mss[1] != 3 || weight != 4 {
+			t.Errorf("invalid MSS, expected [2 3] with weight 4, got %v with weight %d", mss, weight)
+		}
+		if model, cost := pb.Solve(); model == nil {
+			t.Errorf("expected sat, got unsat")
+		} else if !model["a"] || model["b"] || cost != 2 {
+			t.Errorf("invalid model, expected a and ¬b with cost 2, got %v with cost %d", model, cost)
+		}
+	}
+}
+
+func TestMaximumSatisfiableSubset(t *testing.T) {
+	// Greedily keeping the heaviest constraint gives the maximal subset {0}, whose weight is not maximum
+	pb := New(
+		WeightedClause([]Lit{Var("x")}, 3),
+		WeightedClause([]Lit{Var("y")}, 2),
+		WeightedClause([]Lit{Var("z")}, 2),
+		HardClause(Not("x"), Not("y")),
+		HardClause(Not("x"), Not("z")),
+	)
+	pb.SetUpperBoundHint(2) // Hardens constraint 0, but must be ignored
+	if mss, weight := pb.MaximalSatisfiableSubset(); fmt.Sprint(mss) != "[1 2]" || weight != 4 {
+		t.Errorf("invalid MSS, expected [1 2] with weight 4, got %v with weight %d", mss, weight)
+	}
+	if model, cost := pb.Solve(); model != nil {
+		t.Errorf("the bound should still be enforced, got %v with cost %d", model, cost)
+	}
+	pb.ClearCostUpperBound()
+	pb.SetLinearObjective(map[string]int{"y": 1, "z": 1}) // Must be ignored too
+	if mss, weight := pb.MaximalSatisfiableSubset(); fmt.Sprint(mss) != "[1 2]" || weight != 4 {
+		t.Errorf("invalid MSS with a linear objective, expected [1 2] with weight 4, got %v with weight %d", mss, weight)
+	}
+	if _, cost := pb.Solve(); cost != 0 {
+		t.Errorf("the linear objective should still be minimized, expected cost 0, got %d", cost)
+	}
+	// Violating the capped constraint by a single unit costs 1, but it weighs 3 in the MSS
+	pb = New(
+		Constr{Lits: []Lit{Var("a"), Var("b"), Var("c")}, AtLeast: 3, Weight: 1, WeightCap: 3},
+		WeightedClause([]Lit{Not("a")}, 2),
+	)
+	if mss, weight := pb.MaximalSatisfiableSubset(); fmt.Sprint(mss) != "[0]" || weight != 3 {
+		t.Errorf("invalid MSS with a capped constraint, expected [0] with weight 3, got %v with weight %d", mss, weight)
+	}
+}
+
+func TestMaximalSatisfiableSubsetUnsat(t *testing.T) {
+	pb := New(
+		HardClause(Var("a")),
+		HardClause(Not("a")),
+		SoftClause(Var("b")),
+	)
+	if mss, weight := pb.MaximalSatisfiableSubset(); mss != nil || weight != -1 {
+		t.Errorf("expected no MSS, got %v with weight %d", mss, weight)
+	}
+}
+
+func TestCostUpperBound(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b"), Var("c")),
+		HardPBConstr([]Lit{Not("a"), Not("b"), Not("c")}, []int{1, 1, 1}, 2),
+		SoftPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, []int{1, 1, 1}, 2),
+		WeightedClause([]Lit{Not("a"), Var("d")}, 2),
+		WeightedPBConstr([]Lit{Var("b"), Var("c"), Var("d")}, []int{1, 1, 1}, 2, 3),
+		SoftClause(Not("c"), Not("d")),
+	)
+	pb.SetCostUpperBound(0)
+	if model, cost := pb.Solve(); model != nil {
+		t.Errorf("expected no model with cost 0, got %v with cost %d", model, cost)
+	}
+	pb.SetCostUpperBound(3)
+	if model, cost := pb.Solve(); model == nil {
+		t.Errorf("expected sat, got unsat")
+	} else if cost != 1 {
+		t.Errorf("invalid cost, expected 1, got %d", cost)
+	}
+	pb.SetCostUpperBound(0)
+	pb.ClearCostUpperBound()
+	if model, cost := pb.Solve(); model == nil {
+		t.Errorf("expected sat after clearing bound, got unsat")
+	} else if cost != 1 {
+		t.Errorf("invalid cost, expected 1, got %d", cost)
+	}
+}
+
+func TestBrokenSorted(t *testing.T) {
+	pb := New(
+		WeightedClause([]Lit{Not("c")}, 1),
+		HardClause(Var("a")),
+		WeightedClause([]Lit{Var("b")}, 5),
+		WeightedClause([]Lit{Not("a")}, 3),
+		HardClause(Var("c"), Not("b")),
+		HardClause(Not("b"), Not("d")),
+		WeightedClause([]Lit{Var("d")}, 2),
+	)
+	model, cost := pb.Solve()
+	if model == nil {
+		t.Fatalf("expected sat, got unsat")
+	}
+	if cost != 6 {
+		t.Errorf("invalid cost, expected 6, got %d", cost)
+	}
+	expected := []int{0, 3, 6}
+	broken := pb.Broken()
+	if len(broken) != len(expected) {
+		t.Fatalf("invalid broken constraints, expected %v, got %v", expected, broken)
+	}
+	for i := range expected {
+		if broken[i] != expected[i] {
+			t.Fatalf("invalid broken constraints, expected %v, got %v", expected, broken)
+		}
+	}
+}
+
+func TestCostBreakdown(t *testing.T) {
+	pb := New(
+		WeightedClause([]Lit{Not("c")}, 1),
+		HardClause(Var("a")),
+		WeightedClause([]Lit{Var("b")}, 5),
+		WeightedClause([]Lit{Not("a")}, 3),
+		HardClause(Var("c"), Not("b")),
+		HardClause(Not("b"), Not("d")),
+		WeightedClause([]Lit{Var("d")}, 2),
+	)
+	if breakdown := pb.CostBreakdown(); breakdown != nil {
+		t.Errorf("expected nil breakdown before solving, got %v", breakdown)
+	}
+	_, cost := pb.Solve()
+	breakdown := pb.CostBreakdown()
+	if got := fmt.Sprint(breakdown); got != "map[0:1 3:3 6:2]" {
+		t.Errorf("invalid breakdown %s", got)
+	}
+	sum := 0
+	for _, w := range breakdown {
+		sum += w
+	}
+	if sum != cost {
+		t.Errorf("breakdown sums to %d, but cost is %d", sum, cost)
+	}
+}
+
+func TestTrivialConstrs(t *testing.T) {
+	constrs := []Constr{
+		WeightedClause([]Lit{Not("c")}, 1),
+		HardClause(Var("a")),
+		WeightedClause([]Lit{Var("b")}, 5),
+		WeightedClause([]Lit{Not("a")}, 3),
+		HardClause(Var("c"), Not("b")),
+	}
+	pb := New(constrs...)
+	model, cost := pb.Solve()
+	trivial := append(constrs,
+		WeightedPBConstr([]Lit{Var("a"), Var("b")}, []int{1, 2}, 0, 7),
+		HardPBConstr([]Lit{Var("c")}, []int{3}, -1),
+		WeightedClause(nil, 2), // Clauses have an AtLeast of 1, so the empty one is always broken
+	)
+	pb2 := New(trivial...)
+	model2, cost2 := pb2.Solve()
+	if cost2 != cost+2 {
+		t.Errorf("invalid cost with trivial constraints: expected %d, got %d", cost+2, cost2)
+	}
+	if fmt.Sprint(model2) != fmt.Sprint(model) {
+		t.Errorf("invalid model with trivial constraints: expected %v, got %v", model, model2)
+	}
+	if pb2.MaxWeight() != pb.MaxWeight()+2 {
+		t.Errorf("invalid max weight with trivial constraints: expected %d, got %d", pb.MaxWeight()+2, pb2.MaxWeight())
+	}
+	if broken := fmt.Sprint(pb2.Broken()); broken != fmt.Sprint(append(pb.Broken(), 7)) {
+		t.Errorf("invalid broken constraints %s", broken)
+	}
+}
+
+func TestTrivialSoftLEEQ(t *testing.T) {
+	trivial := []Constr{
+		{Lits: []Lit{Var("a")}, Coeffs: []int{2}, AtLeast: 3, Comparator: LE, Weight: 2},
+		{Lits: []Lit{Var("a"), Var("b")}, Coeffs: []int{0, 0}, AtLeast: 0, Comparator: EQ, Weight: 3},
+	}
+	for _, c := range trivial { // The vars of c have the highest ids of the problem
+		pb := New(HardClause(Var("c")), WeightedClause([]Lit{Not("c")}, 1), c)
+		if pb.MaxWeight() != 1 {
+			t.Errorf("invalid max weight with trivial constraint %v: expected 1, got %d", c, pb.MaxWeight())
+		}
+		model, cost := pb.Solve()
+		if cost != 1 || !model["c"] {
+			t.Errorf("expected cost 1 with c, got %d with %v", cost, model)
+		}
+		if broken := fmt.Sprint(pb.Broken()); broken != "[1]" {
+			t.Errorf("invalid broken constraints: expected [1], got %s", broken)
+		}
+	}
+}
+
+func TestVarInTrivialConstrOnly(t *testing.T) {
+	pb := New(HardClause(Var("a")), Constr{Lits: []Lit{Var("c")}, AtLeast: 0})
+	model, cost := pb.Solve()
+	if cost != 0 || !model["a"] {
+		t.Errorf("expected cost 0 with a, got %d with %v", cost, model)
+	}
+	if _, ok := model["c"]; !ok {
+		t.Errorf("expected c to be part of the model, got %v", model)
+	}
+	pb = New(
+		HardClause(Var("a")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		Constr{Lits: []Lit{Var("c")}, AtLeast: 0},
+	)
+	pb.SetCostUpperBound(1) // Must not reuse the id of c for its activation var
+	for _, val := range []bool{false, true} {
+		model, cost, _ := pb.SolveFixing(map[string]bool{"c": val})
+		if cost != 1 || model["c"] != val {
+			t.Errorf("expected cost 1 with c=%t, got %d with %v", val, cost, model)
+		}
+	}
+	if err := pb.AddConstr(Constr{Lits: []Lit{Var("d")}, AtLeast: -1}); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	pb.SetCostUpperBound(2)
+	for _, val := range []bool{false, true} {
+		model, cost, _ := pb.SolveFixing(map[string]bool{"d": val})
+		if cost != 1 || model["d"] != val {
+			t.Errorf("expected cost 1 with d=%t, got %d with %v", val, cost, model)
+		}
+	}
+}
+
+func TestEntails(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Var("c")),
+		HardClause(Not("b"), Var("c")),
+		SoftClause(Not("c")),
+	)
+	pb.SetCostUpperBound(0)
+	tests := []struct {
+		lits     []Lit
+		expected bool
+	}{
+		{[]Lit{Var("c")}, true},
+		{[]Lit{Var("a"), Var("b")}, true},
+		{[]Lit{Var("a")}, false},
+		{[]Lit{Not("c")}, false},
+		{[]Lit{Var("a"), Var("unknown")}, false},
+		{[]Lit{Var("c"), Not("unknown")}, true},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := pb.Entails(test.lits); got != test.expected {
+			t.Errorf("Entails(%v): expected %t, got %t", test.lits, test.expected, got)
+		}
+	}
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("cost bound should still be enforced, got model %v", model)
+	}
+	pb.ClearCostUpperBound()
+	if model, cost := pb.Solve(); model == nil || cost != 1 {
+		t.Errorf("expected a model with cost 1, got %v with cost %d", model, cost)
+	}
+}
+
+func TestSolveWithBudget(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 3),
+		WeightedClause([]Lit{Not("b")}, 2),
+		WeightedClause([]Lit{Var("c")}, 4),
+		HardClause(Not("c"), Var("a")),
+	)
+	if model, cost, broken, ok := pb.SolveWithBudget(4); !ok || cost != 3 || fmt.Sprint(broken) != "[1]" || !model["a"] || !model["c"] {
+		t.Errorf("expected optimal model with cost 3, got %v with cost %d (broken %v, ok %t)", model, cost, broken, ok)
+	}
+	if model, cost, broken, ok := pb.SolveWithBudget(2); ok || model != nil || cost != -1 || broken != nil {
+		t.Errorf("expected no model within budget, got %v with cost %d (broken %v, ok %t)", model, cost, broken, ok)
+	}
+	if _, cost := pb.Solve(); cost != 3 {
+		t.Errorf("budget should not impact later solves, got cost %d", cost)
+	}
+	pb.SetCostUpperBound(2)
+	if _, _, _, ok := pb.SolveWithBudget(10); ok {
+		t.Errorf("cost upper bound should be enforced")
+	}
+}
+
+func TestDecodeModel(t *testing.T) {
+	model := []bool{true, false, true}
+	names := map[int]string{1: "a", 2: "b", 4: "d", 0: "zero"}
+	if got := fmt.Sprint(DecodeModel(model, names)); got != "map[a:true b:false]" {
+		t.Errorf("invalid decoded model %s", got)
+	}
+	prob := solver.ParseSlice([][]int{{1}, {-2}, {2, 3}})
+	s := solver.New(prob)
+	if s.Solve() != solver.Sat {
+		t.Fatalf("should be sat")
+	}
+	names = map[int]string{1: "a", 3: "c"}
+	if got := fmt.Sprint(DecodeModel(s.Model(), names)); got != "map[a:true c:true]" {
+		t.Errorf("invalid decoded model %s", got)
+	}
+}
+
+func TestNewPartial(t *testing.T) {
+	pb := NewPartial(10,
+		WeightedClause([]Lit{Var("a"), Var("b")}, 10),
+		WeightedClause([]Lit{Not("a")}, 3),
+		WeightedClause([]Lit{Not("b")}, 0),
+		WeightedClause([]Lit{Not("b"), Var("c")}, 12),
+		WeightedClause([]Lit{Not("c")}, 1),
+	)
+	if pb.MaxWeight() != 4 {
+		t.Errorf("expected max weight 4, got %d", pb.MaxWeight())
+	}
+	model, cost := pb.Solve()
+	if cost != 1 || model["a"] || !model["b"] || !model["c"] {
+		t.Errorf("expected model with cost 1, got %v with cost %d", model, cost)
+	}
+	if broken := fmt.Sprint(pb.Broken()); broken != "[4]" {
+		t.Errorf("invalid broken constraints %s", broken)
+	}
+}
+
+func TestSolveMinTrue(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b"), Var("c")),
+		HardClause(Not("a"), Var("d")),
+		HardClause(Var("a"), Var("e")),
+		WeightedClause([]Lit{Var("b")}, 5),
+	)
+	pb.SetCostUpperBound(0)
+	model, nb := pb.SolveMinTrue([]string{"a", "b", "c", "d", "e", "unknown"})
+	if nb != 2 || model == nil {
+		t.Fatalf("expected a model with 2 true vars, got %v with %d true vars", model, nb)
+	}
+	nbTrue := 0
+	for _, val := range model {
+		if val {
+			nbTrue++
+		}
+	}
+	if nbTrue != 2 {
+		t.Errorf("invalid model %v", model)
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 0 || !model["b"] {
+		t.Errorf("expected a model with cost 0, got %v with cost %d", model, cost)
+	}
+	pb.ClearCostUpperBound()
+	if model, nb := pb.SolveMinTrue([]string{"e", "d"}); nb != 1 || model["e"] == model["d"] {
+		t.Errorf("expected a model with exactly one of d and e true, got %v with %d true vars", model, nb)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	constrs := generateTSP(6)
+	pb := New(constrs...)
+	model, cost := pb.Solve()
+	for seed := int64(0); seed < 5; seed++ {
+		broken := pb.Broken()
+		pb.Shuffle(seed)
+		if got := fmt.Sprint(pb.Broken()); got != fmt.Sprint(broken) {
+			t.Errorf("seed %d: broken constraints of last model changed from %v to %s", seed, broken, got)
+		}
+		model2, cost2 := pb.Solve()
+		if cost2 != cost || len(model2) != len(model) {
+			t.Errorf("seed %d: expected model with cost %d, got %v with cost %d", seed, cost, model2, cost2)
+		}
+		cost3 := 0
+		for _, i := range pb.Broken() {
+			if constrs[i].Weight == 0 {
+				t.Errorf("seed %d: hard constraint #%d reported as broken", seed, i)
+			}
+			cost3 += constrs[i].Weight
+		}
+		if cost3 != cost2 {
+			t.Errorf("seed %d: broken constraints have a weight of %d, expected %d", seed, cost3, cost2)
+		}
+	}
+}
+
+func TestSeedCores(t *testing.T) {
+	constrs := []Constr{
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 3),
+		WeightedClause([]Lit{Not("b")}, 2),
+		WeightedClause([]Lit{Var("c")}, 4),
+		HardClause(Not("c"), Not("d")),
+		WeightedClause([]Lit{Var("d")}, 1),
+	}
+	lb, cores := New(constrs...).OptimalityCertificate()
+	if lb != 3 {
+		t.Fatalf("expected lower bound 3, got %d", lb)
+	}
+	pb := New(constrs...)
+	seeded := append(cores, []int{1}, []int{0, 2}, []int{1, 1, 2}, []int{3, 5})
+	if got := pb.SeedCores(seeded); got != lb {
+		t.Errorf("expected seeded lower bound %d, got %d", lb, got)
+	}
+	if _, cost := pb.Solve(); cost != lb {
+		t.Errorf("expected optimal cost %d, got %d", lb, cost)
+	}
+	pb = New(constrs...)
+	pb.AddConstr(HardClause(Not("b")))
+	if got := pb.SeedCores(cores); got != lb {
+		t.Errorf("cores should still be valid, got lower bound %d", got)
+	}
+	if _, cost := pb.Solve(); cost != 4 {
+		t.Errorf("expected optimal cost 4, got %d", cost)
+	}
+	pb = New(constrs...)
+	pb.TagConstr(0, "ab")
+	pb.SeedCores(cores)
+	pb.SetTagEnabled("ab", false)
+	if _, cost := pb.Solve(); cost != 1 {
+		t.Errorf("expected optimal cost 1 once cores are invalid, got %d", cost)
+	}
+}
+
+func TestSolveObjective(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b"), Var("c")),
+		HardClause(Not("a"), Not("b")),
+		SoftClause(Not("c")),
+	)
+	pb.DefineObjective("cost", []Lit{Var("a"), Var("b"), Var("c")}, []int{1, 2, 3})
+	pb.DefineObjective("risk", []Lit{Var("a"), Not("c")}, nil)
+	model, cost, broken := pb.SolveObjective("cost")
+	if cost != 1 || !model["a"] || model["b"] || model["c"] || len(broken) != 0 {
+		t.Errorf("invalid model for cost: %v with cost %d (broken %v)", model, cost, broken)
+	}
+	model, cost, broken = pb.SolveObjective("risk")
+	if cost != 0 || model["a"] || !model["c"] || fmt.Sprint(broken) != "[2]" {
+		t.Errorf("invalid model for risk: %v with cost %d (broken %v)", model, cost, broken)
+	}
+	if _, cost := pb.Solve(); cost != 0 {
+		t.Errorf("cost function of the problem should have been restored, got cost %d", cost)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for unknown objective")
+		}
+	}()
+	pb.SolveObjective("latency")
+}
+
+func TestRetractable(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 1),
+	)
+	if _, cost := pb.Solve(); cost != 1 {
+		t.Fatalf("expected cost 1, got %d", cost)
+	}
+	notB := pb.AddRetractable(HardClause(Not("b")))
+	notC := pb.AddRetractable(HardClause(Not("c"), Var("b")))
+	if model, cost := pb.Solve(); cost != 2 || model["b"] || model["c"] {
+		t.Errorf("expected model with cost 2, got %v with cost %d", model, cost)
+	}
+	pb.TagConstr(1, "not-a") // Forces the solver to be rebuilt
+	if model, cost := pb.Solve(); cost != 2 || model["b"] || model["c"] {
+		t.Errorf("retractable constraints should survive a rebuild, got %v with cost %d", model, cost)
+	}
+	pb.Retract(notB)
+	pb.Retract(notB)
+	if model, cost := pb.Solve(); cost != 1 || !model["b"] {
+		t.Errorf("expected model with cost 1 once constraint is retracted, got %v with cost %d", model, cost)
+	}
+	pb.Retract(notC)
+	pb.Retract(12345)
+	if !pb.Entails([]Lit{Var("a"), Var("b")}) {
+		t.Errorf("hard constraints should still be enforced")
+	}
+	if pb.Entails([]Lit{Not("c")}) {
+		t.Errorf("retracted constraint should not be enforced anymore")
+	}
+}
+
+func TestRetractForgetsBounds(t *testing.T) {
+	pb := New(
+		WeightedClause([]Lit{Not("a")}, 5),
+		SoftClause(Var("b")),
+		HardClause(Not("a"), Not("b")),
+	)
+	token := pb.AddRetractable(HardClause(Var("a")))
+	if _, cost, _ := pb.MinimizeUnder(nil); cost != 6 {
+		t.Errorf("expected cost 6, got %d", cost)
+	}
+	if lb := pb.SeedCores([][]int{{0}}); lb != 5 {
+		t.Errorf("expected lower bound 5, got %d", lb)
+	}
+	pb.Retract(token)
+	// Bounds found with the retracted constraint are not lower bounds anymore
+	if pb.optima != nil || pb.coreBound != 0 {
+		t.Errorf("expected no lower bound once the constraint is retracted, got optima %v and core bound %d", pb.optima, pb.coreBound)
+	}
+	if _, cost, _ := pb.MinimizeUnder(nil); cost != 0 {
+		t.Errorf("expected cost 0 once the constraint is retracted, got %d", cost)
+	}
+	if _, cost := pb.Solve(); cost != 0 {
+		t.Errorf("expected cost 0 when solving, got %d", cost)
+	}
+}
+
+func TestHasObjective(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a")),
+		WeightedPBConstr([]Lit{Var("a")}, []int{1}, 0, 3),
+	)
+	if pb.HasObjective() {
+		t.Errorf("problem should not have an objective")
+	}
+	if model, cost := pb.Solve(); cost != 0 || model["a"] || !model["b"] {
+		t.Errorf("expected model with cost 0, got %v with cost %d", model, cost)
+	}
+	pb.AddConstr(HardClause(Not("b")))
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model, got %v with cost %d", model, cost)
+	}
+	pb = New(HardClause(Var("a")), SoftClause(Not("a")))
+	if !pb.HasObjective() {
+		t.Errorf("problem should have an objective")
+	}
+	pb.DisableSoft(1)
+	if pb.HasObjective() {
+		t.Errorf("problem should not have an objective once its soft constraint is disabled")
+	}
+}
+
+func TestHotVars(t *testing.T) {
+	pb := New(generateTSP(6)...)
+	pb.Solve()
+	hot := pb.HotVars(5)
+	if len(hot) != 5 {
+		t.Fatalf("expected 5 vars, got %v", hot)
+	}
+	for _, name := range hot {
+		if _, ok := pb.ID(name); !ok {
+			t.Errorf("unknown var %q", name)
+		}
+	}
+}
+
+func TestWeightCap(t *testing.T) {
+	abcd := []Lit{Var("a"), Var("b"), Var("c"), Var("d")}
+	tests := []struct {
+		hard []string
+		cost int
+	}{
+		{nil, 0},
+		{[]string{"a"}, 2},
+		{[]string{"a", "b"}, 4},
+		{[]string{"a", "b", "c"}, 5},
+		{[]string{"a", "b", "c", "d"}, 5},
+	}
+	for _, test := range tests {
+		constrs := []Constr{{Lits: abcd, AtLeast: 4, Weight: 2, WeightCap: 5}}
+		for _, v := range test.hard {
+			constrs = append(constrs, HardClause(Not(v)))
+		}
+		pb := New(constrs...)
+		if pb.MaxWeight() != 5 {
+			t.Errorf("expected max weight 5, got %d", pb.MaxWeight())
+		}
+		if _, cost := pb.Solve(); cost != test.cost {
+			t.Errorf("with %v false: expected cost %d, got %d", test.hard, test.cost, cost)
+		}
+		if test.cost != 0 {
+			if breakdown := fmt.Sprint(pb.CostBreakdown()); breakdown != fmt.Sprintf("map[0:%d]", test.cost) {
+				t.Errorf("with %v false: invalid cost breakdown %s", test.hard, breakdown)
+			}
+		}
+	}
+	pb := New(
+		Constr{Lits: abcd, AtLeast: 1, Weight: 3, WeightCap: 100, Comparator: LE},
+		HardClause(Var("a")),
+		HardClause(Var("b")),
+	)
+	if pb.MaxWeight() != 9 {
+		t.Errorf("expected max weight 9, got %d", pb.MaxWeight())
+	}
+	if _, cost := pb.Solve(); cost != 3 {
+		t.Errorf("expected cost 3, got %d", cost)
+	}
+	if lb, cores := pb.OptimalityCertificate(); lb != 3 || len(cores) != 1 {
+		t.Errorf("expected lower bound 3 with 1 core, got %d with cores %v", lb, cores)
+	}
+}
+
+func TestUpperBoundHint(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("b")),
+		WeightedClause([]Lit{Var("a")}, 3),
+		WeightedClause([]Lit{Var("b")}, 5),
+		WeightedClause([]Lit{Var("c")}, 1),
+		WeightedClause([]Lit{Not("c")}, 2),
+	)
+	pb.SetUpperBoundHint(4)
+	if hardened := pb.hardened(); len(hardened) != 1 || hardened[0] != solver.IntToLit(int32(-pb.blocks[3])) {
+		t.Errorf("expected only the constraint with weight 5 to be hardened, got %v", hardened)
+	}
+	model, cost := pb.Solve()
+	if cost != 4 || model["a"] || !model["b"] || model["c"] {
+		t.Errorf("expected model with cost 4, got %v with cost %d", model, cost)
+	}
+	pb.SetUpperBoundHint(3)
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected no model under a wrong hint, got %v", model)
+	}
+	pb.DisableSoft(3)
+	if model, cost := pb.Solve(); model == nil || cost != 1 {
+		t.Errorf("expected a model with cost 1 once the constraint is disabled, got %v with cost %d", model, cost)
+	}
+	pb.EnableSoft(3)
+	pb.ClearCostUpperBound()
+	if hardened := pb.hardened(); hardened != nil {
+		t.Errorf("expected no hardened constraint, got %v", hardened)
+	}
+	if _, cost := pb.Solve(); cost != 4 {
+		t.Errorf("expected cost 4 without hint, got %d", cost)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := New(
+		HardClause(Var("x"), Var("y")),
+		WeightedClause([]Lit{Not("x")}, 3),
+		Constr{Lits: []Lit{Var("y"), Var("z")}, AtLeast: 2, Weight: 1, WeightCap: 5},
+	)
+	b := New(
+		HardClause(Not("y"), Var("t")),
+		WeightedClause([]Lit{Not("t")}, 4),
+		WeightedClause([]Lit{Var("z")}, 10),
+	)
+	b.TagConstr(2, "z")
+	pb, err := Merge(a, b)
+	if err != nil {
+		t.Fatalf("could not merge problems: %v", err)
+	}
+	if pb.MaxWeight() != a.MaxWeight()+b.MaxWeight() {
+		t.Errorf("expected max weight %d, got %d", a.MaxWeight()+b.MaxWeight(), pb.MaxWeight())
+	}
+	model, cost := pb.Solve()
+	if cost != 4 || !model["z"] {
+		t.Errorf("expected model with cost 4, got %v with cost %d", model, cost)
+	}
+	pb.SetTagEnabled("z", false)
+	pb.SetCostUpperBound(3)
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected no model with cost 3, got %v", model)
+	}
+	pb.ClearCostUpperBound()
+	if model, cost := pb.Solve(); cost != 4 {
+		t.Errorf("expected cost 4 with tag disabled, got %v with cost %d", model, cost)
+	}
+	b.SetTagEnabled("z", false)
+	c := New(WeightedClause([]Lit{Var("z")}, 1))
+	c.TagConstr(0, "z")
+	if _, err := Merge(b, c); err == nil {
+		t.Errorf("expected an error when merging problems with inconsistent tags")
+	}
+	a.SetVarLabel("x", "foo")
+	c = New(HardClause(Var("x")))
+	c.SetVarLabel("x", "bar")
+	if _, err := Merge(a, c); err == nil {
+		t.Errorf("expected an error when merging problems with inconsistent labels")
+	}
+}
+
+func TestWhyVar(t *testing.T) {
+	pb := New(
+		HardClause(Not("a"), Var("b")),
+		HardClause(Var("c"), Var("d")),
+		WeightedClause([]Lit{Var("a")}, 2),
+		WeightedClause([]Lit{Not("c")}, 1),
+	)
+	if reason, ok := pb.WhyVar("b"); ok {
+		t.Errorf("expected no reason before solving, got %v", reason)
+	}
+	if model, cost := pb.Solve(); cost != 0 || !model["a"] || !model["b"] || model["c"] || !model["d"] {
+		t.Fatalf("invalid model %v with cost %d", model, cost)
+	}
+	reason, ok := pb.WhyVar("b")
+	sort.Strings(reason)
+	if !ok || fmt.Sprint(reason) != "[b ¬a]" {
+		t.Errorf("expected b to be forced by ¬a ∨ b, got %v (%t)", reason, ok)
+	}
+	reason, ok = pb.WhyVar("d")
+	sort.Strings(reason)
+	if !ok || fmt.Sprint(reason) != "[c d]" {
+		t.Errorf("expected d to be forced by c ∨ d, got %v (%t)", reason, ok)
+	}
+	if reason, ok := pb.WhyVar("a"); ok {
+		t.Errorf("expected a not to be forced, got %v", reason)
+	}
+	if reason, ok := pb.WhyVar("unknown"); ok {
+		t.Errorf("expected no reason for unknown var, got %v", reason)
+	}
+}
+
+func TestAllDifferent(t *testing.T) {
+	colors := []string{"red", "green", "blue"}
+	pb := New(WeightedClause([]Lit{Var(EnumVar("a", "red")), Var(EnumVar("b", "red"))}, 1))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := pb.AddEnum(name, colors); err != nil {
+			t.Fatalf("could not declare enum %q: %v", name, err)
+		}
+	}
+	if err := pb.AddEnum("a", colors); err == nil {
+		t.Errorf("expected an error when declaring an enum twice")
+	}
+	if err := pb.AddEnum("e", []string{"red", "red"}); err == nil {
+		t.Errorf("expected an error for duplicate values")
+	}
+	if err := pb.AddEnum("e", []string{"red", "green"}); err != nil {
+		t.Fatalf("could not declare enum e: %v", err)
+	}
+	if err := pb.AddAllDifferent([]string{"a", "e"}); err == nil {
+		t.Errorf("expected an error for enums with different domains")
+	}
+	if err := pb.AddAllDifferent([]string{"a", "unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown enum")
+	}
+	if err := pb.AddAllDifferent([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("could not add all-different constraint: %v", err)
+	}
+	model, cost := pb.Solve()
+	if cost != 0 {
+		t.Fatalf("expected cost 0, got %d", cost)
+	}
+	used := make(map[string]bool)
+	for _, name := range []string{"a", "b", "c"} {
+		nb := 0
+		for _, color := range colors {
+			if model[EnumVar(name, color)] {
+				nb++
+				if used[color] {
+					t.Errorf("color %s used twice in %v", color, model)
+				}
+				used[color] = true
+			}
+		}
+		if nb != 1 {
+			t.Errorf("expected exactly one color for %s, got %d", name, nb)
+		}
+	}
+	if err := pb.AddAllDifferent([]string{"a", "b", "c", "d"}); err != nil {
+		t.Fatalf("could not add all-different constraint: %v", err)
+	}
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected no model for 4 vars with 3 values, got %v", model)
+	}
+}
+
+func TestSolveCost(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 3),
+	)
+	if cost := pb.SolveCost(); cost != 2 {
+		t.Errorf("expected cost 2, got %d", cost)
+	}
+	if broken := fmt.Sprint(pb.Broken()); broken != "[1]" {
+		t.Errorf("invalid broken constraints %s", broken)
+	}
+	if err := pb.AddConstr(HardClause(Not("a"), Not("b"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if err := pb.AddConstr(HardClause(Var("a"), Not("b"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if err := pb.AddConstr(HardClause(Not("a"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if cost := pb.SolveCost(); cost != -1 {
+		t.Errorf("expected -1 for unsat problem, got %d", cost)
+	}
+}
+
+func TestSetSoftWeight(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 3),
+	)
+	if model, cost := pb.Solve(); cost != 2 || !model["a"] {
+		t.Errorf("expected model with a and cost 2, got %v with cost %d", model, cost)
+	}
+	pb.SetSoftWeight(1, 5)
+	if pb.MaxWeight() != 8 {
+		t.Errorf("expected max weight 8, got %d", pb.MaxWeight())
+	}
+	if model, cost := pb.Solve(); cost != 3 || !model["b"] || model["a"] {
+		t.Errorf("expected model with b and cost 3, got %v with cost %d", model, cost)
+	}
+	pb.SetSoftWeight(2, 0)
+	if pb.MaxWeight() != 5 {
+		t.Errorf("expected max weight 5, got %d", pb.MaxWeight())
+	}
+	if _, cost := pb.Solve(); cost != 0 {
+		t.Errorf("expected cost 0 with constraint disabled, got %d", cost)
+	}
+	pb.SetSoftWeight(2, 1)
+	if _, cost := pb.Solve(); cost != 1 || pb.MaxWeight() != 6 {
+		t.Errorf("expected cost 1 and max weight 6, got %d and %d", cost, pb.MaxWeight())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when setting the weight of a hard constraint")
+		}
+	}()
+	pb.SetSoftWeight(0, 1)
+}
+
+func TestVerify(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, []int{3, 2, 1}, 4),
+		Constr{Lits: []Lit{Var("a"), Var("c")}, AtLeast: 1, Comparator: EQ, Reified: "r"},
+		WeightedClause([]Lit{Not("a")}, 1),
+	)
+	tests := []struct {
+		m        Model
+		ok       bool
+		violated []int
+	}{
+		{Model{"a": true, "c": true, "r": false}, true, nil},
+		{Model{"a": true, "c": true, "r": true}, false, []int{2}},
+		{Model{"b": true, "c": true, "r": true}, false, []int{1}},
+		{Model{"c": true}, false, []int{0, 1, 2}},
+		{Model{"a": true, "b": true, "r": true}, true, nil},
+	}
+	for i, test := range tests {
+		ok, violated := pb.Verify(test.m)
+		if ok != test.ok || fmt.Sprint(violated) != fmt.Sprint(test.violated) {
+			t.Errorf("test #%d: expected %t, %v, got %t, %v", i, test.ok, test.violated, ok, violated)
+		}
+	}
+	if model, _ := pb.Solve(); model == nil {
+		t.Fatalf("expected a model")
+	} else if ok, violated := pb.Verify(model); !ok {
+		t.Errorf("model %v returned by Solve violates constraints %v", model, violated)
+	}
+}
+
+func TestSetLinearObjective(t *testing.T) {
+	pb := New(
+		HardPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, nil, 2),
+		WeightedClause([]Lit{Not("b")}, 100),
+	)
+	pb.SetLinearObjective(map[string]int{"a": 3, "b": 2, "c": 4, "d": 1})
+	if model, cost := pb.Solve(); cost != 5 || !model["a"] || !model["b"] || model["c"] {
+		t.Errorf("expected model with a and b and cost 5, got %v with cost %d", model, cost)
+	}
+	pb.SetLinearObjective(map[string]int{"a": 3, "b": 2, "c": 4, "~c": 6})
+	if model, cost := pb.Solve(); cost != 6 || model["a"] || !model["b"] || !model["c"] {
+		t.Errorf("expected model with b and c and cost 6, got %v with cost %d", model, cost)
+	}
+	if err := pb.AddConstr(HardClause(Not("b"), Var("d"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	pb.SetLinearObjective(map[string]int{"a": 3, "b": 2, "c": 4, "d": 3})
+	if model, cost := pb.Solve(); cost != 7 || !model["a"] || model["b"] || !model["c"] {
+		t.Errorf("expected model with a and c and cost 7, got %v with cost %d", model, cost)
+	}
+	pb.SetLinearObjective(nil)
+	if model, cost := pb.Solve(); cost != 0 || model["b"] {
+		t.Errorf("expected model without b and cost 0, got %v with cost %d", model, cost)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic with a negative coefficient")
+		}
+	}()
+	pb.SetLinearObjective(map[string]int{"a": -1})
+}
+
+func TestSetDecisionVars(t *testing.T) {
+	newPb := func() *Problem {
+		return New(
+			HardPBConstr([]Lit{Var("a"), Var("b"), Var("c"), Var("d")}, []int{2, 3, 4, 5}, 7),
+			Constr{Lits: []Lit{Var("a"), Var("b")}, AtLeast: 1, Comparator: EQ, Reified: "e"},
+			WeightedClause([]Lit{Not("a")}, 2),
+			WeightedClause([]Lit{Not("c")}, 3),
+			WeightedClause([]Lit{Not("d")}, 4),
+			WeightedClause([]Lit{Var("e")}, 1),
+		)
+	}
+	_, expected := newPb().Solve()
+	pb := newPb()
+	pb.SetDecisionVars([]string{"a", "b"})
+	model, cost := pb.Solve()
+	if cost != expected {
+		t.Errorf("expected cost %d with restricted decisions, got %d", expected, cost)
+	}
+	if ok, violated := pb.Verify(model); !ok {
+		t.Errorf("model %v violates constraints %v", model, violated)
+	}
+	if err := pb.AddConstr(HardClause(Var("f"), Not("b"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if model, _ := pb.Solve(); model == nil || (model["b"] && !model["f"]) {
+		t.Errorf("expected model satisfying the new constraint, got %v", model)
+	}
+	pb.SetDecisionVars(nil)
+	if _, cost := pb.Solve(); cost < expected {
+		t.Errorf("expected cost at least %d, got %d", expected, cost)
+	}
+}
+
+func TestSolvePrioritized(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("b")),
+		WeightedClause([]Lit{Var("a")}, 1),
+		WeightedClause([]Lit{Var("b")}, 10),
+		WeightedClause([]Lit{Var("b"), Var("c")}, 10),
+		WeightedClause([]Lit{Not("c")}, 10),
+	)
+	if model, cost := pb.Solve(); cost != 1 || !model["b"] {
+		t.Errorf("expected model with b and cost 1, got %v with cost %d", model, cost)
+	}
+	model, counts := pb.SolvePrioritized([]int{0, 0, 1, 3, 3, 5})
+	if !model["a"] || !model["c"] || fmt.Sprint(counts) != "[0 1 1]" {
+		t.Errorf("expected model with a and c and counts [0 1 1], got %v with counts %v", model, counts)
+	}
+	if broken := fmt.Sprint(pb.Broken()); broken != "[3 5]" {
+		t.Errorf("expected broken constraints [3 5], got %s", broken)
+	}
+	if model, cost := pb.Solve(); cost != 1 || !model["b"] {
+		t.Errorf("expected model with b and cost 1 after SolvePrioritized, got %v with cost %d", model, cost)
+	}
+	pb2 := New(HardClause(Var("a")), HardClause(Not("a")))
+	if model, counts := pb2.SolvePrioritized([]int{0, 0}); model != nil || counts != nil {
+		t.Errorf("expected no model, got %v with counts %v", model, counts)
+	}
+}
+
+func TestCostOf(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, []int{1, 2, 3}, 5, 4),
+		Constr{Lits: []Lit{Var("a"), Var("b"), Var("c"), Var("d")}, AtLeast: 4, Weight: 3, WeightCap: 7},
+		Constr{Lits: []Lit{Var("c"), Var("d")}, AtLeast: 1, Weight: 5, Comparator: EQ},
+	)
+	tests := []struct {
+		m    Model
+		cost int
+		ok   bool
+	}{
+		{Model{"a": true, "b": true, "c": true, "d": true}, 2 + 5, true},
+		{Model{"b": true, "c": true}, 6 + 0, true},
+		{Model{"a": true}, 2 + 4 + 7 + 5, true},
+		{Model{"c": true, "d": true}, 4 + 6 + 5, false},
+		{Model{"b": true, "d": true}, 4 + 6, true},
+	}
+	for i, test := range tests {
+		if cost, ok := pb.CostOf(test.m); cost != test.cost || ok != test.ok {
+			t.Errorf("test #%d: expected cost %d and %t, got %d and %t", i, test.cost, test.ok, cost, ok)
+		}
+	}
+	model, cost := pb.Solve()
+	if got, ok := pb.CostOf(model); got != cost || !ok {
+		t.Errorf("expected cost %d for the optimal model %v, got %d and %t", cost, model, got, ok)
+	}
+	pb.SetLinearObjective(map[string]int{"a": 3, Neg("c"): 2})
+	if cost, ok := pb.CostOf(Model{"a": true}); cost != 5 || !ok {
+		t.Errorf("expected cost 5 w.r.t the linear objective, got %d and %t", cost, ok)
+	}
+}
+
+func TestWriteSMTLIB(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b c")),
+		HardPBConstr([]Lit{Var("a"), Not("2d")}, []int{2, 3}, 3),
+		WeightedClause([]Lit{Not("a")}, 4),
+	)
+	var b strings.Builder
+	if err := pb.WriteSMTLIB(&b); err != nil {
+		t.Fatalf("could not write SMT-LIB output: %v", err)
+	}
+	expected := `(set-logic QF_LIA)
+(declare-fun a () Int)
+(assert (and (>= a 0) (<= a 1)))
+(declare-fun |b c| () Int)
+(assert (and (>= |b c| 0) (<= |b c| 1)))
+(declare-fun |2d| () Int)
+(assert (and (>= |2d| 0) (<= |2d| 1)))
+(declare-fun soft_2 () Int)
+(assert (and (>= soft_2 0) (<= soft_2 1)))
+(assert (>= (+ a |b c|) 1))
+(assert (>= (+ (* 2 a) (* 3 (- 1 |2d|))) 3))
+(assert (>= (+ (- 1 a) soft_2) 1))
+(minimize (* 4 soft_2))
+(check-sat)
+(get-model)
+`
+	if b.String() != expected {
+		t.Errorf("invalid SMT-LIB output, expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+	if got := smtSymbol("a|b\\c"); got != "|a_b_c|" {
+		t.Errorf("expected symbol |a_b_c|, got %s", got)
+	}
+}
+
+func TestWriteLP(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b c")),
+		HardPBConstr([]Lit{Var("a"), Not("2d")}, []int{2, 3}, 3),
+		WeightedClause([]Lit{Not("a")}, 4),
+	)
+	var b strings.Builder
+	if err := pb.WriteLP(&b); err != nil {
+		t.Fatalf("could not write LP output: %v", err)
+	}
+	expected := `Minimize
+ obj: 4 soft_2
+Subject To
+ c1: 1 a + 1 b_c >= 1
+ c2: 2 a - 3 x_2d >= 0
+ c3: - 1 a + 1 soft_2 >= 0
+Binary
+ a
+ b_c
+ x_2d
+ soft_2
+End
+`
+	if b.String() != expected {
+		t.Errorf("invalid LP output, expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+	pb.SetLinearObjective(map[string]int{"a": 2, "~b c": 5})
+	b.Reset()
+	if err := pb.WriteLP(&b); err != nil {
+		t.Fatalf("could not write LP output: %v", err)
+	}
+	if !strings.HasPrefix(b.String(), "Minimize\n obj: 2 a - 5 b_c\n\\ offset: 5\n") {
+		t.Errorf("invalid LP objective, got:\n%s", b.String())
+	}
+	if got := lpName("e1+x"); got != "x_e1_x" {
+		t.Errorf("expected LP name x_e1_x, got %s", got)
+	}
+}
+
+func TestSolveFirstThenOptimal(t *testing.T) {
+	newPb := func() *Problem {
+		constrs := []Constr{HardPBConstr([]Lit{Var("a"), Var("b"), Var("c"), Var("d"), Var("e")}, nil, 3)}
+		for i, name := range []string{"a", "b", "c", "d", "e"} {
+			constrs = append(constrs, WeightedClause([]Lit{Not(name)}, i+1))
+		}
+		return New(constrs...)
+	}
+	_, expected := newPb().Solve()
+	pb := newPb()
+	first, firstCost, optimal, optCost, broken := pb.SolveFirstThenOptimal()
+	if optCost != expected || optCost != 6 {
+		t.Errorf("expected optimal cost %d, got %d", expected, optCost)
+	}
+	if firstCost < optCost {
+		t.Errorf("first cost %d is lower than optimal cost %d", firstCost, optCost)
+	}
+	if cost, ok := pb.CostOf(first); cost != firstCost || !ok {
+		t.Errorf("invalid first model %v: cost %d, expected %d", first, cost, firstCost)
+	}
+	if !optimal["a"] || !optimal["b"] || !optimal["c"] || fmt.Sprint(broken) != "[1 2 3]" {
+		t.Errorf("invalid optimal model %v, broken %v", optimal, broken)
+	}
+	if fmt.Sprint(pb.Broken()) != fmt.Sprint(broken) {
+		t.Errorf("expected last model to be the optimal one")
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	if first, firstCost, optimal, optCost, _ := pb.SolveFirstThenOptimal(); first != nil || optimal != nil || firstCost != -1 || optCost != -1 {
+		t.Errorf("expected no model")
+	}
+}
+
+func TestSolveAt(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("b")),
+		WeightedClause([]Lit{Var("a")}, 1),
+		WeightedClause([]Lit{Var("b")}, 1),
+	)
+	pb.SetWeightFunction(2, 5, -1)
+	pb.SetWeightFunction(3, 1, 1)
+	tests := []struct {
+		lambda int
+		a      bool
+		cost   int
+		broken string
+	}{
+		{0, true, 1, "[3]"},
+		{3, false, 2, "[2]"},
+		{6, false, 0, "[]"},
+		{0, true, 1, "[3]"},
+	}
+	for _, test := range tests {
+		model, cost, broken := pb.SolveAt(test.lambda)
+		if model["a"] != test.a || cost != test.cost || fmt.Sprint(broken) != test.broken {
+			t.Errorf("lambda=%d: expected a=%t, cost %d and broken %s, got %v, %d and %v", test.lambda, test.a, test.cost, test.broken, model, cost, broken)
+		}
+	}
+	if pb.MaxWeight() != 6 {
+		t.Errorf("expected max weight 6, got %d", pb.MaxWeight())
+	}
+}
+
+func TestEquivalenceClasses(t *testing.T) {
+	pb := New(
+		HardClause(Not("a"), Var("b")), // a => b => c => a, d = ^a, e is free, f and g are true
+		HardClause(Not("b"), Var("c")),
+		HardClause(Not("c"), Var("a")),
+		HardClause(Var("a"), Var("d")),
+		HardClause(Not("a"), Not("d")),
+		HardClause(Var("e"), Var("a"), Not("a")),
+		HardClause(Var("f")),
+		HardClause(Var("g"), Not("f")),
+		WeightedClause([]Lit{Not("a")}, 3),
+	)
+	classes := pb.EquivalenceClasses()
+	if got := fmt.Sprint(classes); got != "[[a b c ~d] [f g]]" {
+		t.Errorf("expected classes [[a b c ~d] [f g]], got %s", got)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	if classes := pb.EquivalenceClasses(); classes != nil {
+		t.Errorf("expected no class for an unsatisfiable problem, got %v", classes)
+	}
+}
+
+func TestSolveWithSet(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("c")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+		WeightedClause([]Lit{Var("c")}, 4),
+	)
+	as := pb.NewAssumptionSet()
+	as.Set("a", true)
+	as.Set("z", false)
+	model, cost, _ := pb.SolveWithSet(as)
+	if cost != 5 || !model["a"] || model["c"] || model["z"] {
+		t.Errorf("expected cost 5 with a and not c, got %d with %v", cost, model)
+	}
+	as.Set("a", false)
+	if _, cost, _ := pb.SolveWithSet(as); cost != 2 {
+		t.Errorf("expected cost 2 with not a, got %d", cost)
+	}
+	as.Set("c", true)
+	as.Unset("a")
+	if as.Len() != 2 {
+		t.Errorf("expected 2 assumptions, got %d", as.Len())
+	}
+	if model, cost, _ := pb.SolveWithSet(as); cost != 2 || model["a"] || !model["c"] {
+		t.Errorf("expected cost 2 with c and not a, got %d with %v", cost, model)
+	}
+	as.Set("a", true)
+	if model, cost, _ := pb.SolveWithSet(as); model != nil || cost != -1 {
+		t.Errorf("expected no model with a and c, got %v with cost %d", model, cost)
+	}
+	as.Clear()
+	if _, cost, _ := pb.SolveWithSet(as); cost != 2 || as.Len() != 0 {
+		t.Errorf("expected cost 2 without assumptions, got %d", cost)
+	}
+}
+
+func TestTopKSolutions(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+	)
+	models, costs := pb.TopKSolutions(5)
+	if got := fmt.Sprint(costs); got != "[1 2 3]" {
+		t.Errorf("expected costs [1 2 3], got %s", got)
+	}
+	if got := fmt.Sprint(models); got != "[map[a:true b:false] map[a:false b:true] map[a:true b:true]]" {
+		t.Errorf("invalid models, got %s", got)
+	}
+	if broken := pb.Broken(); fmt.Sprint(broken) != "[1]" {
+		t.Errorf("expected broken constraints [1], got %v", broken)
+	}
+	if models, costs := pb.TopKSolutions(2); len(models) != 2 || fmt.Sprint(costs) != "[1 2]" {
+		t.Errorf("expected 2 models with costs [1 2], got %v with costs %v", models, costs)
+	}
+	if _, cost := pb.Solve(); cost != 1 {
+		t.Errorf("expected optimal cost 1 after enumeration, got %d", cost)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	if models, costs := pb.TopKSolutions(3); models != nil || costs != nil {
+		t.Errorf("expected no model for an unsatisfiable problem, got %v", models)
+	}
+}
+
+func TestHardCache(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+	)
+	if model, cost := pb.Solve(); cost != 1 || !model["a"] || model["b"] {
+		t.Errorf("expected cost 1 with a and not b, got %d with %v", cost, model)
+	}
+	if pb.hardModel == nil {
+		t.Errorf("expected a model of the hard constraints to be cached")
+	}
+	pb.SetSoftWeight(1, 5)
+	if model, cost := pb.Solve(); cost != 2 || model["a"] || !model["b"] {
+		t.Errorf("expected cost 2 with b and not a, got %d with %v", cost, model)
+	}
+	if broken := pb.Broken(); fmt.Sprint(broken) != "[2]" {
+		t.Errorf("expected broken constraints [2], got %v", broken)
+	}
+	pb.SetCostUpperBound(1)
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model with a bound of 1, got %v with cost %d", model, cost)
+	}
+	if pb.hardUnsat {
+		t.Errorf("hard constraints should not be deemed unsatisfiable because of the cost bound")
+	}
+	pb.ClearCostUpperBound()
+	if err := pb.AddConstr(HardClause(Not("b"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if _, cost := pb.Solve(); cost != 5 {
+		t.Errorf("expected cost 5 after adding a constraint, got %d", cost)
+	}
+	if err := pb.AddConstr(HardClause(Not("a"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if model, cost := pb.Solve(); model != nil || cost != -1 {
+			t.Errorf("expected no model, got %v with cost %d", model, cost)
+		}
+		if !pb.hardUnsat {
+			t.Errorf("expected hard constraints to be known unsatisfiable")
+		}
+	}
+	pb.InvalidateHardCache()
+	if pb.hardUnsat || pb.hardModel != nil {
+		t.Errorf("expected cache to be invalidated")
+	}
+	if bits, cost, _ := pb.SolveBits(); bits != nil || cost != -1 || !pb.hardUnsat {
+		t.Errorf("expected SolveBits to find no model and cache it, got %v with cost %d", bits, cost)
+	}
+	if model, cost, _, ok := pb.SolveWithBudget(10); ok || model != nil || cost != -1 {
+		t.Errorf("expected no model within budget, got %v with cost %d", model, cost)
+	}
+}
+
+func TestHardCacheSolver(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 1),
+	)
+	if _, cost := pb.Solve(); cost != 1 {
+		t.Fatalf("expected cost 1, got %d", cost)
+	}
+	// Replace the cached model with the other optimal one, while the solver last found the first one:
+	// the cached model must be the one returned by all entry points, and the one the solver then knows of
+	first := pb.lastModel
+	if model, _, _ := pb.SolveFixing(map[string]bool{"a": !pb.decode(first)["a"]}); model == nil {
+		t.Fatalf("expected another model")
+	}
+	pb.hardModel = pb.lastModel
+	if model, _, _ := pb.SolveFixing(pb.decode(first)); model == nil {
+		t.Fatalf("expected the first model again")
+	}
+	expected := fmt.Sprint(pb.decode(pb.hardModel))
+	ia, _ := pb.ID("a")
+	ib, _ := pb.ID("b")
+	check := func(name string, model Model) {
+		t.Helper()
+		if fmt.Sprint(model) != expected {
+			t.Errorf("%s: expected cached model %s, got %v", name, expected, model)
+		}
+		if got := fmt.Sprint(pb.decode(pb.Solver().Model())); got != expected {
+			t.Errorf("%s: expected the solver's model to be %s, got %s", name, expected, got)
+		}
+	}
+	model, _ := pb.Solve()
+	check("Solve", model)
+	pb.SolveFixing(pb.decode(first))
+	bits, _, _ := pb.SolveBits()
+	check("SolveBits", Model{"a": bits.Get(ia), "b": bits.Get(ib)})
+	pb.SolveFixing(pb.decode(first))
+	model, _, _, _ = pb.SolveWithBudget(1)
+	check("SolveWithBudget", model)
+}
+
+func TestSolveJSON(t *testing.T) {
+	pb := NewWithOptions(Options{BlockPrefix: "pref_"},
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+	)
+	var b bytes.Buffer
+	if err := pb.SolveJSON(&b); err != nil {
+		t.Fatalf("could not write JSON output: %v", err)
+	}
+	prefix := `{"status":"OPTIMUM FOUND","cost":1,"model":{"a":true,"b":false},"broken":[1],"broken_labels":["pref_1"],"stats":{"conflicts":`
+	if !strings.HasPrefix(b.String(), prefix) {
+		t.Errorf("invalid JSON output, expected prefix %s, got %s", prefix, b.String())
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	b.Reset()
+	if err := pb.SolveJSON(&b); err != nil {
+		t.Fatalf("could not write JSON output: %v", err)
+	}
+	prefix = `{"status":"UNSATISFIABLE","cost":-1,"model":null,"broken":[],"stats":{`
+	if !strings.HasPrefix(b.String(), prefix) {
+		t.Errorf("invalid JSON output, expected prefix %s, got %s", prefix, b.String())
+	}
+}
+
+func TestTriviallyUnsatCostFunc(t *testing.T) {
+	pb := New(HardClause(Var("a")), HardClause(Not("a")), SoftClause(Var("b")), HardClause(Var("c")))
+	// All these methods give a new cost function to the solver, that has no var at all
+	pb.SetSoftWeight(2, 3)
+	pb.DisableSoft(2)
+	pb.EnableSoft(2)
+	pb.SetCostUpperBound(2)
+	pb.SetWeightFunction(2, 1, 1)
+	if model, cost, _ := pb.SolveAt(2); model != nil || cost != -1 {
+		t.Errorf("expected no model, got %v with cost %d", model, cost)
+	}
+	pb.SetLinearObjective(map[string]int{"b": 2})
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model, got %v with cost %d", model, cost)
+	}
+	pb.TagConstr(3, "t")
+	pb.SetTagEnabled("t", false) // The solver is rebuilt
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model after rebuilding, got %v with cost %d", model, cost)
+	}
+}
+
+func TestTriviallyInfeasible(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("c"), Var("d")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		HardClause(Var("c")),
+		HardClause(Not("d"), Not("a")),
+		HardClause(Var("e")),
+		HardClause(Not("d"), Var("a")),
+	)
+	if !pb.TriviallyInfeasible() {
+		t.Fatalf("expected problem to be trivially infeasible")
+	}
+	if got := fmt.Sprint(pb.ContradictingConstrs()); got != "[1 3 4 6]" {
+		t.Errorf("expected contradicting constraints [1 3 4 6], got %s", got)
+	}
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model, got %v with cost %d", model, cost)
+	}
+	pb.InvalidateHardCache()
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected no model after invalidating the cache, got %v", model)
+	}
+	pb.TagConstr(4, "opt")
+	if pb.TriviallyInfeasible() || pb.ContradictingConstrs() != nil {
+		t.Errorf("expected problem not to be trivially infeasible after tagging constraint #4")
+	}
+	pb.SetTagEnabled("opt", false)
+	if _, cost := pb.Solve(); cost != 1 {
+		t.Errorf("expected cost 1 with constraint #4 disabled, got %d", cost)
+	}
+	pb = New(HardPBConstr([]Lit{Var("a"), Var("b")}, []int{1, 2}, 4))
+	if got := fmt.Sprint(pb.ContradictingConstrs()); got != "[0]" {
+		t.Errorf("expected contradicting constraints [0], got %s", got)
+	}
+	pb = New(HardClause(Var("a"), Var("b")), HardClause(Not("a"), Not("b")))
+	if pb.TriviallyInfeasible() {
+		t.Errorf("expected satisfiable problem not to be trivially infeasible")
+	}
+}
+
+func TestPool(t *testing.T) {
+	pool := NewPool(
+		HardClause(Var("a"), Var("b"), Var("c")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+		WeightedClause([]Lit{Not("c")}, 3),
+	)
+	for i, test := range []struct {
+		extra  []Constr
+		cost   int
+		broken string
+	}{
+		{nil, 1, "[1]"},
+		{[]Constr{HardClause(Not("a"))}, 2, "[2]"},
+		{[]Constr{HardClause(Not("a")), HardClause(Not("b"))}, 3, "[3]"},
+		{[]Constr{HardClause(Var("b"), Var("d")), HardClause(Not("d"))}, 2, "[2]"},
+		{[]Constr{HardClause(Not("a")), HardClause(Not("b")), HardClause(Not("c"))}, -1, "[]"},
+		{nil, 1, "[1]"},
+	} {
+		model, cost, broken := pool.Solve(test.extra...)
+		if cost != test.cost || fmt.Sprint(broken) != test.broken {
+			t.Errorf("test #%d: expected cost %d with broken %s, got %d with broken %v", i, test.cost, test.broken, cost, broken)
+		}
+		if (model == nil) != (cost == -1) {
+			t.Errorf("test #%d: invalid model %v for cost %d", i, model, cost)
+		}
+	}
+	if len(pool.Problem().activations()) != 0 {
+		t.Errorf("expected all extra constraints to be retracted")
+	}
+}
+
+func TestAddGlobalCardinality(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	var constrs []Constr
+	for i, name := range names {
+		constrs = append(constrs, WeightedClause([]Lit{Var(name)}, i+1))
+	}
+	pb := New(constrs...)
+	if err := pb.AddGlobalAtMost(2, names); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	model, cost := pb.Solve()
+	if cost != 6 || !model["d"] || !model["e"] || model["a"] || model["b"] || model["c"] {
+		t.Errorf("expected cost 6 with only d and e, got %d with %v", cost, model)
+	}
+	if len(model) != len(names) {
+		t.Errorf("expected auxiliary vars not to be part of the model, got %v", model)
+	}
+	if err := pb.AddGlobalAtLeast(1, []string{"a", "b", "f"}); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if model, cost := pb.Solve(); cost != 6 || !model["f"] {
+		t.Errorf("expected cost 6 with f, got %d with %v", cost, model)
+	}
+	if err := pb.AddGlobalAtLeast(3, []string{"c", "d", "e"}); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected no model, got %v", model)
+	}
+	if err := pb.AddGlobalAtMost(1, []string{"a", "a"}); err == nil {
+		t.Errorf("expected an error for a duplicate var")
+	}
+	if n := len(pb.constrs); n != 8 {
+		t.Errorf("expected 8 constraints, got %d", n)
+	}
+}
+
+func TestAddGlobalCardinalitySize(t *testing.T) {
+	const n, k = 200, 3
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("x%d", i)
+	}
+	for _, atMost := range []bool{false, true} {
+		pb := New(HardClause(Var("y")))
+		nbClauses, nbVars := len(pb.Clauses()), len(pb.varInts)
+		add := pb.AddGlobalAtLeast
+		if atMost {
+			add = pb.AddGlobalAtMost
+		}
+		if err := add(k, names); err != nil {
+			t.Fatalf("could not add constraint: %v", err)
+		}
+		if nb := len(pb.Clauses()) - nbClauses; nb > 2*n*(k+1) {
+			t.Errorf("atMost=%t: expected at most %d clauses, got %d", atMost, 2*n*(k+1), nb)
+		}
+		if nb := len(pb.varInts) - nbVars - n; nb > n*k {
+			t.Errorf("atMost=%t: expected at most %d auxiliary vars, got %d", atMost, n*k, nb)
+		}
+		model, _ := pb.Solve()
+		nbTrue := 0
+		for _, name := range names {
+			if model[name] {
+				nbTrue++
+			}
+		}
+		if (atMost && nbTrue > k) || (!atMost && nbTrue < k) {
+			t.Errorf("atMost=%t: invalid model with %d true vars", atMost, nbTrue)
+		}
+	}
+}
+
+func TestStop(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+	)
+	pb.Stop()
+	if model, cost := pb.Solve(); model != nil || cost != -1 || !pb.Interrupted() {
+		t.Errorf("expected interrupted search without model, got %v with cost %d", model, cost)
+	}
+	if _, cost := pb.Solve(); cost != 1 || pb.Interrupted() {
+		t.Errorf("expected optimal cost 1 without interruption, got %d", cost)
+	}
+	pb.SetSoftWeight(1, 3)
+	pb.Stop()
+	if model, cost := pb.Solve(); model == nil || (cost != 2 && cost != 3) || (cost == 3) != pb.Interrupted() {
+		t.Errorf("expected best known model or optimal one, got %v with cost %d", model, cost)
+	}
+	if _, cost := pb.Solve(); cost != 2 || pb.Interrupted() {
+		t.Errorf("expected optimal cost 2 without interruption, got %d", cost)
+	}
+}
+
+func TestClauses(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		SoftClause(Not("a")),
+		HardPBConstr([]Lit{Var("a"), Var("b")}, []int{2, 1}, 2),
+	)
+	if got := fmt.Sprint(pb.Clauses()); got != "[{[1 2] [] 1} {[-1 3] [] 1} {[1 2] [2 1] 2}]" {
+		t.Errorf("invalid clauses %s", got)
+	}
+	pb.TagConstr(0, "t")
+	clauses := pb.Clauses()
+	if got := fmt.Sprint(clauses); got != "[{[1 2 -4] [] 1} {[-1 3] [] 1} {[1 2] [2 1] 2}]" {
+		t.Errorf("invalid clauses after tagging %s", got)
+	}
+	clauses[0].Lits[0] = 5
+	if got := fmt.Sprint(pb.Clauses()[0]); got != "{[1 2 -4] [] 1}" {
+		t.Errorf("returned clauses should be copies, got %s", got)
+	}
+}
+
+func TestAllSatisfiable(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		SoftClause(Not("a")),
+		SoftClause(Not("b")),
+	)
+	if pb.AllSatisfiable() {
+		t.Errorf("expected soft constraints to be unsatisfiable together")
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 1 {
+		t.Errorf("invalid result, expected cost 1, got model %v with cost %d", model, cost)
+	}
+	pb = New(
+		HardClause(Var("a"), Var("b")),
+		SoftClause(Not("a")),
+		SoftClause(Var("b")),
+	)
+	if !pb.AllSatisfiable() {
+		t.Errorf("expected all constraints to be satisfiable")
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	constrs := []Constr{
+		SoftClause(Var("a")),
+		{Lits: []Lit{Var("a"), Var("b")}, AtLeast: 1, Comparator: EQ, Reified: "r"},
+	}
+	pb := NewWithOptions(Options{BlockPrefix: "relax#", AuxPrefix: "aux#"}, constrs...)
+	var opb strings.Builder
+	if err := pb.WriteOPB(&opb); err != nil {
+		t.Fatalf("could not write OPB: %v", err)
+	}
+	for _, line := range []string{"* a=x1\n", "* relax#0=x2\n", "* r=x4\n", "* aux#5=x5\n", "* aux#6=x6\n"} {
+		if !strings.Contains(opb.String(), line) {
+			t.Errorf("expected line %q in OPB output, got\n%s", line, opb.String())
+		}
+	}
+	var opb2 strings.Builder
+	if err := New(constrs...).WriteOPB(&opb2); err != nil {
+		t.Fatalf("could not write OPB: %v", err)
+	}
+	if !strings.Contains(opb2.String(), "* soft_0=x2\n") || strings.Contains(opb2.String(), "=x5") {
+		t.Errorf("invalid default labels in OPB output:\n%s", opb2.String())
+	}
+}
+
+func TestWriteLabels(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Not("b")),
+		SoftClause(Var("b")),
+		WeightedClause([]Lit{Not("a")}, 3),
+	)
+	pb.SetVarLabel("a", "first")
+	var opb strings.Builder
+	if err := pb.WriteOPB(&opb); err != nil {
+		t.Fatalf("could not write OPB: %v", err)
+	}
+	const expectedOPB = `* #variable= 4 #constraint= 3
+* first=x1
+* b=x2
+* soft_1=x3
+* soft_2=x4
+min: 1 x3 +3 x4 ;
+1 x1 +1 ~x2 >= 1 ;
+1 x2 +1 x3 >= 1 ;
+1 ~x1 +1 x4 >= 1 ;
+`
+	if opb.String() != expectedOPB {
+		t.Errorf("invalid OPB output: expected\n%s\ngot\n%s", expectedOPB, opb.String())
+	}
+	var wcnf strings.Builder
+	if err := pb.WriteWCNF(&wcnf); err != nil {
+		t.Fatalf("could not write WCNF: %v", err)
+	}
+	const expectedWCNF = `p wcnf 4 3 5
+c first=1
+c b=2
+5 1 -2 0
+1 2 0
+3 -1 0
+`
+	if wcnf.String() != expectedWCNF {
+		t.Errorf("invalid WCNF output: expected\n%s\ngot\n%s", expectedWCNF, wcnf.String())
+	}
+	if err := New(HardPBConstr([]Lit{Var("a"), Var("b")}, nil, 2)).WriteWCNF(&wcnf); err == nil {
+		t.Errorf("expected an error when writing a cardinality constraint as WCNF")
+	}
+}
+
+func TestDisjointCores(t *testing.T) {
+	pb := New(
+		HardClause(Not("a"), Not("b")),
+		SoftClause(Var("a")),
+		SoftClause(Var("c")),
+		SoftClause(Var("b")),
+		SoftClause(Var("d")),
+		SoftClause(Not("d")),
+	)
+	cores := pb.DisjointCores(10)
+	if len(cores) != 2 || fmt.Sprint(cores) != "[[4 5] [1 3]]" {
+		t.Errorf("invalid cores: expected [[4 5] [1 3]], got %v", cores)
+	}
+	if cores := pb.DisjointCores(1); len(cores) != 1 {
+		t.Errorf("expected only 1 core, got %v", cores)
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 2 {
+		t.Errorf("invalid cost after extracting cores: expected 2, got %d", cost)
+	}
+}
+
+func TestComparator(t *testing.T) {
+	abc := []Lit{Var("a"), Var("b"), Var("c")}
+	pb := New(
+		HardPBConstr(abc, nil, 2),
+		Constr{Lits: abc, AtLeast: 1, Weight: 5, Comparator: LE},
+		Constr{Lits: []Lit{Var("a"), Var("b")}, AtLeast: 1, Weight: 1, Comparator: EQ},
+	)
+	if model, cost := pb.Solve(); model == nil || cost != 5 {
+		t.Errorf("invalid cost: expected 5, got %d", cost)
+	} else if model["a"] == model["b"] {
+		t.Errorf("invalid model %v: a and b should have different values", model)
+	}
+	pb = New(
+		Constr{Lits: abc, Coeffs: []int{1, 1, 1}, AtLeast: 2, Comparator: EQ},
+		WeightedClause([]Lit{Var("a")}, 1),
+		WeightedClause([]Lit{Var("b")}, 2),
+		WeightedClause([]Lit{Var("c")}, 3),
+	)
+	if model, cost := pb.Solve(); model == nil || cost != 1 {
+		t.Errorf("invalid cost: expected 1, got %d", cost)
+	} else if model["a"] || !model["b"] || !model["c"] {
+		t.Errorf("invalid model: expected only b and c to be true, got %v", model)
+	}
+	pb = New(
+		Constr{Lits: abc, AtLeast: 1, Comparator: LE},
+		WeightedClause([]Lit{Var("a")}, 1),
+		WeightedClause([]Lit{Var("b")}, 2),
+	)
+	if model, cost := pb.Solve(); model == nil || cost != 1 {
+		t.Errorf("invalid cost: expected 1, got %d", cost)
+	}
+}
+
+func TestAddConstr(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 2),
+		WeightedClause([]Lit{Not("c")}, 4),
+	)
+	pb.SetCostUpperBound(5)
+	model, cost := pb.Solve()
+	if model == nil || cost != 1 || !model["a"] || model["b"] {
+		t.Fatalf("invalid first solution: expected cost 1 with only a true, got %v with cost %d", model, cost)
+	}
+	// Exclude the previous optimum
+	if err := pb.AddConstr(HardClause(Not("a"), Var("c"), Var("d"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if err := pb.AddConstr(HardClause(Not("d"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	model, cost = pb.Solve()
+	if model == nil || cost != 2 || model["a"] || !model["b"] || model["c"] || model["d"] {
+		t.Errorf("invalid second solution: expected cost 2 with only b true, got %v with cost %d", model, cost)
+	}
+	if broken := pb.Broken(); fmt.Sprint(broken) != "[2]" {
+		t.Errorf("invalid broken constraints: expected [2], got %v", broken)
+	}
+	if err := pb.AddConstr(HardClause(Not("b"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if model, cost = pb.Solve(); model == nil || cost != 5 {
+		t.Errorf("invalid third solution: expected cost 5, got %v with cost %d", model, cost)
+	}
+	if err := pb.AddConstr(HardClause(Not("c"))); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if model, _ = pb.Solve(); model != nil {
+		t.Errorf("expected UNSAT problem, got %v", model)
+	}
+	pb.ClearCostUpperBound()
+	if model, _ = pb.Solve(); model != nil {
+		t.Errorf("expected UNSAT problem after clearing bound, got %v", model)
+	}
+	if err := pb.AddConstr(SoftClause(Var("a"))); err == nil {
+		t.Errorf("expected an error when adding a soft constraint")
+	}
+}
+
+func TestTimings(t *testing.T) {
+	pb := New(generateTSP(5)...)
+	if timings := pb.Timings(); timings.Parse <= 0 || timings.Solve != 0 {
+		t.Errorf("invalid timings before solving: %+v", timings)
+	}
+	pb.Solve()
+	if timings := pb.Timings(); timings.Parse <= 0 || timings.Solve <= 0 {
+		t.Errorf("invalid timings after solving: %+v", timings)
+	}
+}
+
+func TestSolveSat(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b"), Var("c")),
+		HardPBConstr([]Lit{Not("a"), Not("b"), Not("c")}, nil, 2),
+		WeightedClause([]Lit{Var("a"), Var("b")}, 5),
+		WeightedClause([]Lit{Not("a"), Not("b")}, 5),
+		WeightedClause([]Lit{Var("c")}, 5),
+	)
+	model, ok := pb.SolveSat()
+	if !ok {
+		t.Fatalf("expected sat, got unsat")
+	}
+	nbTrue := 0
+	for _, v := range []string{"a", "b", "c"} {
+		if model[v] {
+			nbTrue++
+		}
+	}
+	if nbTrue != 1 {
+		t.Errorf("invalid model %v: exactly one var should be true", model)
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 5 {
+		t.Errorf("invalid cost after SolveSat: expected 5, got %d", cost)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")), SoftClause(Var("b")))
+	if model, ok := pb.SolveSat(); ok || model != nil {
+		t.Errorf("expected unsat, got %v", model)
+	}
+}
+
+func TestSolveFixing(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("c")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 3),
+		WeightedClause([]Lit{Var("c")}, 1),
+	)
+	model, cost, broken := pb.SolveFixing(map[string]bool{"a": false, "z": true})
+	if model == nil {
+		t.Fatalf("expected a model, got nil")
+	}
+	if model["a"] || !model["b"] || !model["c"] || !model["z"] {
+		t.Errorf("invalid model %v", model)
+	}
+	if cost != 3 || fmt.Sprint(broken) != "[3]" {
+		t.Errorf("invalid solution: expected cost 3 and broken [3], got %d and %v", cost, broken)
+	}
+	if model, cost, broken := pb.SolveFixing(map[string]bool{"a": true, "c": true}); model != nil || cost != -1 || broken != nil {
+		t.Errorf("expected infeasible fixings, got %v, %d, %v", model, cost, broken)
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 2 || !model["a"] {
+		t.Errorf("fixings persisted: expected cost 2 with a true, got %v with cost %d", model, cost)
+	}
+}
+
+func TestMinimizeUnder(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Not("c")),
+		WeightedClause([]Lit{Not("a")}, 1),
+		WeightedClause([]Lit{Not("b")}, 3),
+		WeightedClause([]Lit{Var("c")}, 1),
+	)
+	tests := []struct {
+		assumptions map[string]bool
+		cost        int
+	}{
+		{map[string]bool{"a": false}, 3},
+		{map[string]bool{"a": false, "c": true}, 3},
+		{map[string]bool{}, 2},
+		{map[string]bool{"a": true}, 2},
+		{map[string]bool{"a": true, "c": true}, -1},
+		{map[string]bool{"b": false}, 2},
+	}
+	for _, test := range tests {
+		model, cost, _ := pb.MinimizeUnder(test.assumptions)
+		if cost != test.cost {
+			t.Errorf("under %v: expected cost %d, got %d", test.assumptions, test.cost, cost)
+		}
+		if (model == nil) != (test.cost == -1) {
+			t.Errorf("under %v: unexpected model %v", test.assumptions, model)
+		}
+		for name, val := range test.assumptions {
+			if model != nil && model[name] != val {
+				t.Errorf("under %v: assumption on %s not respected in %v", test.assumptions, name, model)
+			}
+		}
+	}
+	pb.DisableSoft(3)
+	if _, cost, _ := pb.MinimizeUnder(map[string]bool{"a": false}); cost != 0 {
+		t.Errorf("after disabling soft constraint: expected cost 0, got %d", cost)
+	}
+}
+
+func TestNumVarsClauses(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, nil, 2),
+		SoftClause(Var("c")),
+		HardClause(Var("d")),
+	)
+	if nb := pb.NumVars(); nb != 5 {
+		t.Errorf("invalid number of vars: expected 5, got %d", nb)
+	}
+	if nb := pb.NumClauses(); nb != 3 {
+		t.Errorf("invalid number of clauses: expected 3, got %d", nb)
+	}
+}
+
+func TestParetoFront(t *testing.T) {
+	pb := New(
+		HardPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, nil, 1),
+		SoftClause(Not("a")),
+	)
+	lits := []Lit{Var("a"), Var("b"), Var("c"), Var("unknown")}
+	front := pb.ParetoFront(lits, lits, []int{1, 2, 3, 1}, []int{3, 2, 1, 1})
+	if len(front) != 3 {
+		t.Fatalf("expected 3 points, got %v", front)
+	}
+	for i, point := range front {
+		if point.CostA != i+1 || point.CostB != 3-i {
+			t.Errorf("invalid point #%d: expected costs (%d, %d), got (%d, %d)", i, i+1, 3-i, point.CostA, point.CostB)
+		}
+		if got := fmt.Sprint(point.Model["a"], point.Model["b"], point.Model["c"]); got != fmt.Sprint(i == 0, i == 1, i == 2) {
+			t.Errorf("invalid model for point #%d: %v", i, point.Model)
+		}
+	}
+	if model, cost := pb.Solve(); model == nil || cost != 0 {
+		t.Errorf("invalid solution after computing Pareto front: expected cost 0, got %v with cost %d", model, cost)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	if front := pb.ParetoFront(lits, lits, nil, nil); front != nil {
+		t.Errorf("expected no point for UNSAT problem, got %v", front)
+	}
+}
+
+func TestParseWBO(t *testing.T) {
+	const wbo = `* #variable= 4 #constraint= 4 #soft= 2 mincost= 2 maxcost= 5 sumcost= 7
+soft: 10 ;
+[5] +1 x1 +1 x2 +1 x3 >= 2 ;
+[2] +2 ~x1 +1 x4 >= 2 ;
++1 x1 +1 x2 <= 1 ;
++1 x2 +1 x3 +1 x4 = 1 ;
+`
+	pb, err := ParseWBO(strings.NewReader(wbo))
+	if err != nil {
+		t.Fatalf("could not parse WBO: %v", err)
+	}
+	model, cost := pb.Solve()
+	if cost != 2 {
+		t.Fatalf("invalid cost: expected 2, got %d", cost)
+	}
+	if !model["x1"] || model["x2"] || !model["x3"] || model["x4"] {
+		t.Errorf("invalid model %v", model)
+	}
+	pb, err = ParseWBO(strings.NewReader(strings.Replace(wbo, "soft: 10 ;", "soft: 2 ;", 1)))
+	if err != nil {
+		t.Fatalf("could not parse WBO: %v", err)
+	}
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected no model with cost lower than top, got %v with cost %d", model, cost)
+	}
+	_, err = ParseWBO(strings.NewReader("soft: ;\n[x] +1 x1 >= 1 ;\n"))
+	var perr *solver.ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("expected a parse error on line 2, got %v", err)
+	}
+}
+
+func TestSolveBits(t *testing.T) {
+	constrs := generateTSP(5)
+	model, cost := New(constrs...).Solve()
+	pb := New(constrs...)
+	bits, cost2, broken := pb.SolveBits()
+	if cost2 != cost || fmt.Sprint(broken) != fmt.Sprint(pb.Broken()) {
+		t.Fatalf("invalid solution: expected cost %d, got %d with broken %v", cost, cost2, broken)
+	}
+	cm, cost3 := pb.SolveCompact()
+	if cost3 != cost {
+		t.Fatalf("invalid compact solution: expected cost %d, got %d", cost, cost3)
+	}
+	model2 := pb.decode(pb.lastModel)
+	for name := range model {
+		id, ok := pb.ID(name)
+		if !ok {
+			t.Fatalf("no id for var %q", name)
+		}
+		if cm.Value(name) != model2[name] || cm.Bits().Get(id) != model2[name] {
+			t.Errorf("invalid binding for %q", name)
+		}
+	}
+	if cm.Value("unknown") || bits.Get(0) || bits.Get(1<<20) {
+		t.Errorf("unknown vars should be false")
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")))
+	if bits, cost, broken := pb.SolveBits(); bits != nil || cost != -1 || broken != nil {
+		t.Errorf("expected no solution, got %v, %d, %v", bits, cost, broken)
+	}
+}
+
+func TestBinarySearch(t *testing.T) {
+	constrs := generateTSP(6)
+	_, optimum := New(constrs...).Solve()
+	pb := New(constrs...)
+	if !pb.TestCostBound(optimum) || pb.TestCostBound(optimum-1) {
+		t.Errorf("invalid probes around optimal cost %d", optimum)
+	}
+	model, cost := pb.OptimalByBinarySearch()
+	if model == nil || cost != optimum {
+		t.Fatalf("invalid binary search result: expected cost %d, got %d", optimum, cost)
+	}
+	if model, cost := pb.Solve(); model == nil || cost != optimum {
+		t.Errorf("invalid cost after binary search: expected %d, got %d", optimum, cost)
+	}
+	pb.SetCostUpperBound(optimum - 1)
+	if pb.TestCostBound(optimum) {
+		t.Errorf("bound set by SetCostUpperBound should still be enforced")
+	}
+	if model, cost := pb.OptimalByBinarySearch(); model != nil || cost != -1 {
+		t.Errorf("expected no model under bound, got cost %d", cost)
+	}
+}
+
+func TestFromBF(t *testing.T) {
+	a, b, c := bf.Var("a"), bf.Var("b"), bf.Var("c")
+	hard := []bf.Formula{bf.Xor(a, b)}
+	soft := []bf.WeightedFormula{{Formula: a, Weight: 3}, {Formula: b, Weight: 1}, {Formula: bf.And(a, c), Weight: 2}}
+	pb, err := FromBF(hard, soft)
+	if err != nil {
+		t.Fatalf("could not build problem: %v", err)
+	}
+	model, cost := pb.Solve()
+	if cost != 1 {
+		t.Fatalf("invalid cost: expected 1, got %d", cost)
+	}
+	if len(model) != 3 || !model["a"] || model["b"] || !model["c"] {
+		t.Errorf("invalid model %v", model)
+	}
+	if broken := pb.Broken(); fmt.Sprint(broken) != "[1]" {
+		t.Errorf("invalid broken formulas: expected [1], got %v", broken)
+	}
+	if _, err := FromBF(hard, []bf.WeightedFormula{{Formula: a}}); err == nil {
+		t.Errorf("expected an error for null weight")
+	}
+	pb, err = FromBF([]bf.Formula{bf.And(a, bf.Not(a))}, soft)
+	if err != nil {
+		t.Fatalf("could not build problem: %v", err)
+	}
+	if model, cost := pb.Solve(); model != nil || cost != -1 {
+		t.Errorf("expected unsat problem, got %v with cost %d", model, cost)
+	}
+}
+
+func TestSolveMax(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 3),
+		WeightedClause([]Lit{Var("c")}, 4),
+	)
+	if w := pb.MaxWeight(); w != 9 {
+		t.Errorf("invalid max weight: expected 9, got %d", w)
+	}
+	if model, w := pb.SolveMax(); model == nil || w != 7 {
+		t.Errorf("invalid satisfied weight: expected 7, got %d", w)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")), SoftClause(Var("b")))
+	if model, w := pb.SolveMax(); model != nil || w != -1 {
+		t.Errorf("expected unsat, got %v with weight %d", model, w)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	prob := solver.ParsePBConstrs([]solver.PBConstr{
+		solver.PropClause(1, 2),
+		solver.PropClause(-1, 3),
+		solver.PropClause(-2, 4),
+		solver.AtMost([]int{3, 4}, 1),
+	})
+	prob.SetCostFunc(solver.IntsToLits(3, 4), []int{2, 5})
+	pb := Wrap(prob, map[int]string{1: "a", 2: "b"})
+	model, cost := pb.Solve()
+	if model == nil {
+		t.Fatalf("expected a model, got nil")
+	}
+	if cost != 2 || len(model) != 2 || !model["a"] || model["b"] {
+		t.Errorf("invalid solution: expected a=true, b=false with cost 2, got %v with cost %d", model, cost)
+	}
+	if broken := pb.Broken(); fmt.Sprint(broken) != "[0]" {
+		t.Errorf("invalid broken constraints: expected [0], got %v", broken)
+	}
+	if w := pb.MaxWeight(); w != 7 {
+		t.Errorf("invalid max weight: expected 7, got %d", w)
+	}
+}
+
+func TestTags(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a")),
+		HardClause(Not("b")),
+		WeightedClause([]Lit{Var("c")}, 3),
+		HardClause(Not("c")),
+	)
+	if model, _ := pb.Solve(); model != nil {
+		t.Fatalf("expected unsat, got %v", model)
+	}
+	pb.TagConstr(1, "no-a")
+	pb.TagConstr(4, "no-c")
+	pb.SetTagEnabled("no-a", false)
+	model, cost := pb.Solve()
+	if model == nil || !model["a"] || model["b"] || cost != 3 {
+		t.Errorf("invalid solution with no-a disabled: got %v with cost %d", model, cost)
+	}
+	pb.SetTagEnabled("no-c", false)
+	if model, cost := pb.Solve(); model == nil || !model["c"] || cost != 0 {
+		t.Errorf("invalid solution with no-a and no-c disabled: got %v with cost %d", model, cost)
+	}
+	pb.SetTagEnabled("no-a", true)
+	if model, _ := pb.Solve(); model != nil {
+		t.Errorf("expected unsat with no-a enabled, got %v", model)
+	}
+	pb.TagConstr(2, "no-a")
+	pb.SetTagEnabled("no-a", false)
+	if model, cost := pb.Solve(); model == nil || cost != 0 || fmt.Sprint(pb.Broken()) != "[]" {
+		t.Errorf("invalid solution with no-a and no-c disabled: got %v with cost %d", model, cost)
+	}
+}
+
+func TestUnusedVars(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardPBConstr([]Lit{Var("c"), Not("d"), Var("e")}, []int{2, 0, 1}, 1),
+	)
+	if unused := pb.UnusedVars(); fmt.Sprint(unused) != "[d]" {
+		t.Errorf("invalid unused vars: expected [d], got %v", unused)
+	}
+	if err := pb.AddConstr(HardPBConstr([]Lit{Var("d"), Var("f")}, []int{1, 0}, 0)); err != nil {
+		t.Fatalf("could not add constraint: %v", err)
+	}
+	if unused := pb.UnusedVars(); fmt.Sprint(unused) != "[f]" {
+		t.Errorf("invalid unused vars: expected [f], got %v", unused)
+	}
+	prob := solver.ParsePBConstrs([]solver.PBConstr{solver.PropClause(1, 2)})
+	pb = Wrap(prob, map[int]string{1: "a", 2: "b", 3: "c"})
+	if unused := pb.UnusedVars(); unused != nil {
+		t.Errorf("invalid unused vars in wrapped problem: expected none, got %v", unused)
+	}
+}
+
+func TestOptimalityCertificate(t *testing.T) {
+	pb := New(
+		HardClause(Not("a"), Not("b")),
+		HardClause(Not("b"), Not("c")),
+		WeightedClause([]Lit{Var("a")}, 3),
+		WeightedClause([]Lit{Var("b")}, 5),
+		WeightedClause([]Lit{Var("c")}, 4),
+	)
+	_, cost := pb.Solve()
+	lb, cores := pb.OptimalityCertificate()
+	if lb != cost {
+		t.Errorf("invalid lower bound: expected %d, got %d (cores: %v)", cost, lb, cores)
+	}
+	if fmt.Sprint(cores) != "[[3 4] [2 3]]" {
+		t.Errorf("invalid cores: expected [[3 4] [2 3]], got %v", cores)
+	}
+	pb = New(HardClause(Var("a")), HardClause(Not("a")), SoftClause(Var("b")))
+	if lb, cores := pb.OptimalityCertificate(); lb != -1 || cores != nil {
+		t.Errorf("expected unsat, got %d and %v", lb, cores)
+	}
+}
+
+func TestFreeVars(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Var("c")),
+		HardPBConstr([]Lit{Var("d"), Var("e")}, []int{1, 0}, 0),
+		WeightedClause([]Lit{Not("c")}, 2),
+		WeightedClause([]Lit{Var("f"), Var("g")}, 1),
+	)
+	model, cost := pb.Solve()
+	if model == nil || cost != 0 {
+		t.Fatalf("expected a model with cost 0, got %v with cost %d", model, cost)
+	}
+	// a and c are false, b is true: a is bound, since it would falsify the second clause
+	expected := "[d e]"
+	switch {
+	case model["f"] && model["g"]:
+		expected = "[d e f g]"
+	case model["f"]:
+		expected = "[d e g]"
+	case model["g"]:
+		expected = "[d e f]"
+	}
+	if free := pb.FreeVars(model); fmt.Sprint(free) != expected {
+		t.Errorf("invalid free vars for %v: expected %s, got %v", model, expected, free)
+	}
+	pb.TagConstr(3, "no-c")
+	pb.SetTagEnabled("no-c", false)
+	model = Model{"a": false, "b": true, "c": false, "d": false, "e": false, "f": true, "g": false}
+	if free := pb.FreeVars(model); fmt.Sprint(free) != "[c d e g]" {
+		t.Errorf("invalid free vars with no-c disabled: expected [c d e g], got %v", free)
+	}
+}
+
+func TestDisableSoft(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		WeightedClause([]Lit{Not("b")}, 3),
+		WeightedClause([]Lit{Var("c")}, 1),
+	)
+	if _, cost := pb.Solve(); cost != 2 {
+		t.Errorf("invalid cost: expected 2, got %d", cost)
+	}
+	pb.DisableSoft(2)
+	pb.DisableSoft(2)
+	pb.DisableSoft(0)
+	if w := pb.MaxWeight(); w != 3 {
+		t.Errorf("invalid max weight with #2 disabled: expected 3, got %d", w)
+	}
+	model, cost := pb.Solve()
+	if model == nil || cost != 0 || model["a"] {
+		t.Errorf("invalid solution with #2 disabled: got %v with cost %d", model, cost)
+	}
+	if broken := pb.Broken(); broken != nil {
+		t.Errorf("invalid broken constraints with #2 disabled: expected none, got %v", broken)
+	}
+	pb.SetCostUpperBound(1)
+	pb.DisableSoft(1)
+	if _, cost := pb.Solve(); cost != 0 {
+		t.Errorf("invalid cost with #1 and #2 disabled: expected 0, got %d", cost)
+	}
+	pb.EnableSoft(1)
+	pb.EnableSoft(2)
+	if model, cost := pb.Solve(); model != nil {
+		t.Errorf("expected no model with a cost of at most 1, got %v with cost %d", model, cost)
+	}
+	pb.ClearCostUpperBound()
+	if _, cost := pb.Solve(); cost != 2 || pb.MaxWeight() != 6 {
+		t.Errorf("invalid cost once all enabled: expected 2 and max weight 6, got %d and %d", cost, pb.MaxWeight())
+	}
+}
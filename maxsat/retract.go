@@ -0,0 +1,72 @@
+package maxsat
+
+import (
+	"sort"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// AddRetractable adds a new hard constraint to the problem, that can later be removed by calling Retract
+// with the returned token.
+// The constraint is guarded by a dedicated activation var, which is assumed by all later solves until
+// the constraint is retracted, so the underlying solver is reused and keeps the clauses it learned,
+// both when adding and when retracting the constraint.
+// The constraint can contain vars that were not part of the problem yet. Its weight is ignored:
+// retractable constraints are always hard.
+func (pb *Problem) AddRetractable(c Constr) (token int) {
+	for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+		pb.varInts = append(pb.varInts, "")
+	}
+	lits := pb.intLits(c.Lits)
+	var coeffs []int
+	if len(c.Coeffs) != 0 {
+		coeffs = make([]int, len(c.Coeffs))
+		copy(coeffs, c.Coeffs)
+	}
+	pb.varInts = append(pb.varInts, "") // Create new activation var
+	act := len(pb.varInts)
+	var cs []solver.PBConstr
+	for _, c2 := range pb.translate(c, lits, coeffs) {
+		c2 = relax(c2, -act)
+		cs = append(cs, c2)
+		if c2.AtLeast > 0 { // Otherwise, c2 is trivially satisfied
+			pb.solver.AppendClause(copyPBConstr(c2).Clause())
+		}
+	}
+	if pb.retractables == nil {
+		pb.retractables = make(map[int][]solver.PBConstr)
+	}
+	pb.retractables[act] = cs
+	pb.solver.EnsureNbVars(len(pb.varInts)) // Its vars might not appear in the clauses given to the solver
+	pb.lastModel = nil
+	pb.InvalidateHardCache()
+	return act
+}
+
+// Retract permanently removes the constraint added by AddRetractable that returned the given token.
+// Clauses learned by the solver are kept, but optimal costs remembered by MinimizeUnder, and the lower bound
+// given by SeedCores, are forgotten, since removing a constraint can lower the optimal cost.
+// If the constraint was already retracted, or if there is no such token, nothing happens.
+func (pb *Problem) Retract(token int) {
+	if _, ok := pb.retractables[token]; !ok {
+		return
+	}
+	delete(pb.retractables, token)
+	pb.solver.AppendClause(solver.NewClause([]solver.Lit{solver.IntToLit(int32(-token))}))
+	pb.optima = nil
+	pb.coreBound = 0
+	pb.solver.SetCostLowerBound(0)
+	pb.lastModel = nil
+	pb.InvalidateHardCache()
+}
+
+// activations returns the activation vars of the constraints added by AddRetractable that were not retracted yet,
+// in increasing order.
+func (pb *Problem) activations() []int {
+	acts := make([]int, 0, len(pb.retractables))
+	for act := range pb.retractables {
+		acts = append(acts, act)
+	}
+	sort.Ints(acts)
+	return acts
+}
@@ -0,0 +1,84 @@
+package maxsat
+
+import (
+	"math/rand"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// Shuffle deterministically permutes the integer ids of the vars of the problem, and the order in which
+// its constraints are given to the solver, according to the given seed, and rebuilds the solver.
+// It is a testing aid: solving the problem again must yield the same optimal cost, and, although the model
+// might differ if there are several optimal ones, constraints are still identified by their position
+// in the list given to New, as in the result of Broken.
+// The clauses learned by the solver are lost.
+// Problems made by Wrap cannot be shuffled, since their constraints are not known: nothing happens for them.
+func (pb *Problem) Shuffle(seed int64) {
+	if pb.wrapUsed != nil {
+		return
+	}
+	rng := rand.New(rand.NewSource(seed))
+	perm := rng.Perm(len(pb.varInts))
+	newID := func(lit int) int { // Returns the new integer counterpart of lit
+		if lit < 0 {
+			return -(perm[-lit-1] + 1)
+		}
+		return perm[lit-1] + 1
+	}
+	varInts := make([]string, len(pb.varInts))
+	for i, name := range pb.varInts {
+		id := newID(i + 1)
+		varInts[id-1] = name
+		if name != "" {
+			pb.intVars[name] = id
+		}
+	}
+	pb.varInts = varInts
+	blockWeights := make(map[int]int, len(pb.blockWeights))
+	for bl, w := range pb.blockWeights {
+		blockWeights[newID(bl)] = w
+	}
+	pb.blockWeights = blockWeights
+	for i, bl := range pb.blocks {
+		if bl != 0 {
+			pb.blocks[i] = newID(bl)
+		}
+	}
+	for i, ctrl := range pb.ctrls {
+		pb.ctrls[i] = newID(ctrl)
+	}
+	for _, c := range pb.capped {
+		for j, l := range c.lits {
+			c.lits[j] = newID(l)
+		}
+	}
+	remap := func(cs []solver.PBConstr) {
+		for j, c := range cs {
+			c = copyPBConstr(c)
+			for k, lit := range c.Lits {
+				c.Lits[k] = newID(lit)
+			}
+			cs[j] = c
+		}
+	}
+	for _, cs := range pb.constrs {
+		remap(cs)
+	}
+	if pb.retractables != nil {
+		retractables := make(map[int][]solver.PBConstr, len(pb.retractables))
+		for act, cs := range pb.retractables {
+			remap(cs)
+			retractables[newID(act)] = cs
+		}
+		pb.retractables = retractables
+	}
+	if pb.lastModel != nil {
+		model := make([]bool, len(pb.varInts))
+		for i := 0; i < len(model) && i < len(pb.lastModel); i++ {
+			model[newID(i+1)-1] = pb.lastModel[i]
+		}
+		pb.lastModel = model
+	}
+	pb.order = rng.Perm(len(pb.constrs))
+	pb.build()
+}
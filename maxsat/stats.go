@@ -0,0 +1,44 @@
+package maxsat
+
+import (
+	"time"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// Stats is a snapshot of the statistics of the underlying solver, taken while solving.
+type Stats struct {
+	solver.Stats
+	Time time.Time // When the snapshot was taken
+}
+
+// StatsStream returns a channel on which snapshots of the statistics of the underlying solver are sent
+// roughly every interval while the next call to Solve is running, typically on another goroutine.
+// The channel is closed when that call returns.
+// Snapshots are taken by the goroutine running the search, so reading them is race-free;
+// a snapshot is dropped if the previous one was not received yet, so that a slow reader does not slow the search down.
+// Calling StatsStream again before Solve returns closes the previous channel.
+func (pb *Problem) StatsStream(interval time.Duration) <-chan Stats {
+	pb.closeStats()
+	if pb.dirty { // Rebuild the solver now, so that the hook is not lost
+		pb.build()
+	}
+	ch := make(chan Stats, 1)
+	pb.stats = ch
+	pb.solver.SetStatsHook(interval, func(st solver.Stats) {
+		select {
+		case ch <- Stats{Stats: st, Time: time.Now()}:
+		default: // Reader is late: drop this snapshot
+		}
+	})
+	return ch
+}
+
+// closeStats closes the channel returned by StatsStream, if any, and stops sending snapshots.
+func (pb *Problem) closeStats() {
+	if pb.stats != nil {
+		pb.solver.SetStatsHook(0, nil)
+		close(pb.stats)
+		pb.stats = nil
+	}
+}
@@ -0,0 +1,69 @@
+package maxsat
+
+import (
+	"github.com/crillab/gophersat/solver"
+)
+
+// A stepState is the state of an optimization performed by successive calls to Step.
+type stepState struct {
+	model   []bool // Best model found so far, as returned by the solver, or nil if none was found yet
+	cost    int    // Cost of model
+	resumed bool   // Whether the state was restored by Resume, so that the solver is not bounded by cost yet
+}
+
+// Step advances the search for an optimal model by at most maxConflicts conflicts, and returns whether the search is over,
+// along with the best model found so far and its cost, or nil and -1 if no model was found yet.
+// Once the search is over, the returned model is optimal, or nil if the hard constraints cannot be satisfied,
+// as with Solve, and the next call to Step starts a new search.
+// Everything learned by the solver is kept between two calls, so repeated calls make steady progress,
+// which makes it possible to solve a problem without blocking for a long time, e.g from an event loop.
+// Each model found improves on the previous one, and is then required to be improved upon.
+// Other solving methods must not be called before the search is over.
+// If maxConflicts is 0 or less, the search is not limited.
+func (pb *Problem) Step(maxConflicts int) (done bool, m Model, cost int) {
+	if pb.step == nil {
+		pb.step = &stepState{cost: -1}
+		if pb.assume(nil) == solver.Unsat {
+			return pb.endStep()
+		}
+	} else if pb.step.resumed {
+		pb.step.resumed = false
+		if pb.assume(nil) == solver.Unsat {
+			return pb.endStep()
+		}
+		pb.solver.SetCostBound(pb.step.cost - 1)
+	}
+	pb.solver.SetConflictBudget(maxConflicts)
+	defer pb.solver.SetConflictBudget(0)
+	for {
+		switch pb.solver.Solve() {
+		case solver.Indet: // Budget exhausted
+			if pb.step.model == nil {
+				return false, nil, -1
+			}
+			return false, pb.decode(pb.step.model), pb.step.cost
+		case solver.Unsat: // Last model, if any, is optimal
+			return pb.endStep()
+		}
+		model := pb.solver.Model()
+		cost := pb.cost(model)
+		pb.step.model, pb.step.cost = model, cost
+		if cost == 0 {
+			return pb.endStep()
+		}
+		pb.solver.SetCostBound(cost - 1)
+	}
+}
+
+// endStep ends the search performed by Step, restores the cost bound set by the user, if any,
+// and returns the best model found and its cost.
+func (pb *Problem) endStep() (done bool, m Model, cost int) {
+	st := pb.step
+	pb.step = nil
+	pb.restoreCostBound()
+	pb.lastModel = st.model
+	if st.model == nil {
+		return true, nil, -1
+	}
+	return true, pb.decode(st.model), st.cost
+}
@@ -0,0 +1,21 @@
+package maxsat
+
+// Stop asks the search currently run by Solve, or by any other solving method of the problem, to stop as soon as possible.
+// It is meant to be called from another goroutine, e.g when a user cancels the search, and is safe to call concurrently
+// with a search. Solve then returns the best model found so far, and its cost, or a nil model if none was found yet,
+// and Interrupted tells such results apart from optimal ones or from proofs that the problem cannot be satisfied.
+// If no search is running, the next one is stopped right away, so that a request made just before a search starts is not lost,
+// and a request is only dropped once a search was stopped by it, as described in solver.Solver.Stop.
+// Nothing learned so far is lost, so calling Solve again resumes the search, from the best model found so far.
+func (pb *Problem) Stop() {
+	pb.solverMu.Lock()
+	defer pb.solverMu.Unlock()
+	pb.solver.Stop()
+}
+
+// Interrupted returns true iff the last call to Solve, or to another method relying on it, such as SolveCost,
+// was stopped by Stop, so that the model it returned is not necessarily optimal, or, if it is nil,
+// the problem is not necessarily unsatisfiable.
+func (pb *Problem) Interrupted() bool {
+	return pb.interrupted
+}
@@ -0,0 +1,96 @@
+package maxsat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/crillab/gophersat/solver"
+)
+
+// TagConstr associates the constraint whose index is constrIndex with the given tag,
+// so that it can be enabled or disabled along with all other constraints with the same tag, through SetTagEnabled.
+// Indices are the positions of the constraints in the list given to New, followed by the constraints added with AddConstr.
+// A constraint has at most one tag: tagging it again replaces its previous tag.
+// Each tagged constraint is guarded by a dedicated control var, and is only enforced when this var is assumed,
+// so tagging a constraint that was not tagged yet requires the underlying solver to be rebuilt once,
+// before the next solve, thus losing the clauses it learned so far; enabling and disabling tags is cheap, though.
+// It panics if there is no constraint with such an index.
+func (pb *Problem) TagConstr(constrIndex int, tag string) {
+	if constrIndex < 0 || constrIndex >= len(pb.constrs) {
+		panic(fmt.Sprintf("cannot tag constraint #%d: no such constraint", constrIndex))
+	}
+	if pb.ctrls == nil {
+		pb.ctrls = make(map[int]int)
+		pb.tags = make(map[int]string)
+	}
+	pb.tags[constrIndex] = tag
+	if pb.ctrls[constrIndex] != 0 {
+		return
+	}
+	for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+		pb.varInts = append(pb.varInts, "")
+	}
+	pb.varInts = append(pb.varInts, "") // Create new control var
+	pb.ctrls[constrIndex] = len(pb.varInts)
+	pb.dirty = true
+	if pb.contradiction != nil { // The constraint can be disabled now, so the contradiction might not hold anymore
+		pb.checkContradiction()
+	}
+	pb.InvalidateHardCache()
+}
+
+// SetTagEnabled enables or disables all constraints with the given tag.
+// A disabled constraint is ignored by later solves: hard constraints do not have to be satisfied anymore,
+// and soft constraints do not cost anything anymore.
+// By default, all constraints are enabled.
+// Clauses learned by the solver are kept when enabling or disabling tags.
+func (pb *Problem) SetTagEnabled(tag string, enabled bool) {
+	pb.InvalidateHardCache()
+	if enabled {
+		delete(pb.disabled, tag)
+		return
+	}
+	if pb.disabled == nil {
+		pb.disabled = make(map[string]bool)
+	}
+	pb.disabled[tag] = true
+	pb.optima = nil // Disabling constraints can lower optimal costs
+	pb.coreBound = 0
+	pb.solver.SetCostLowerBound(0)
+}
+
+// assume assumes the given lits in the underlying solver, along with the control vars of tagged constraints:
+// they are assumed to be true if their tag is enabled, and false otherwise.
+// The activation vars of the constraints added by AddRetractable that were not retracted yet are assumed, too,
+// as well as the negations of the blocking lits hardened by SetUpperBoundHint.
+// If new constraints were tagged, the solver is rebuilt first.
+func (pb *Problem) assume(lits []solver.Lit) solver.Status {
+	if pb.dirty {
+		pb.build()
+	}
+	if pb.decisionVars != nil { // The solver might have been rebuilt, or have new vars
+		pb.freezeVars()
+	}
+	hardened := pb.hardened()
+	if len(pb.ctrls) == 0 && len(pb.retractables) == 0 && len(hardened) == 0 {
+		return pb.solver.Assume(lits)
+	}
+	idx := make([]int, 0, len(pb.ctrls))
+	for i := range pb.ctrls {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx) // Always assume control vars in the same order, for reproducibility
+	all := make([]solver.Lit, 0, len(pb.ctrls)+len(pb.retractables)+len(hardened)+len(lits))
+	for _, i := range idx {
+		ctrl := pb.ctrls[i]
+		if pb.disabled[pb.tags[i]] {
+			ctrl = -ctrl
+		}
+		all = append(all, solver.IntToLit(int32(ctrl)))
+	}
+	for _, act := range pb.activations() {
+		all = append(all, solver.IntToLit(int32(act)))
+	}
+	all = append(all, hardened...)
+	return pb.solver.Assume(append(all, lits...))
+}
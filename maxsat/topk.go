@@ -0,0 +1,70 @@
+package maxsat
+
+import "github.com/crillab/gophersat/solver"
+
+// TopKSolutions returns up to k models of the problem, sorted by increasing cost, along with their costs:
+// the first one is optimal, the second one is optimal among the models that differ from the first one, and so on.
+// Models are distinct over the vars of the problem, i.e auxiliary vars such as blocking literals are ignored,
+// and fewer than k models are returned if there are not that many of them.
+// The bound set by SetCostUpperBound, if any, is enforced. Broken then refers to the first, optimal model.
+// If the hard constraints cannot be satisfied, or if k is not positive, it returns nil, nil.
+//
+// Models are found by minimizing the cost, then blocking the model that was found with a clause and starting again.
+// Blocking clauses are only enforced through an assumption, so they do not impact later calls to Solve,
+// while clauses learned during the search are kept.
+func (pb *Problem) TopKSolutions(k int) ([]Model, []int) {
+	pb.lastModel = nil
+	if k <= 0 || pb.assume(nil) == solver.Unsat { // Also rebuilds the solver if needed
+		return nil, nil
+	}
+	// Activation var of the blocking clauses, once one was added
+	act := 0
+	defer func() { // Blocking clauses are not needed anymore: disable them forever
+		pb.assume(nil)
+		if act != 0 {
+			pb.solver.AppendClause(solver.NewClause([]solver.Lit{solver.IntToLit(int32(-act))}))
+		}
+	}()
+	var models []Model
+	var costs []int
+	var assumptions []solver.Lit
+	for len(models) < k {
+		if pb.assume(assumptions) == solver.Unsat {
+			break
+		}
+		cost := pb.solver.Minimize()
+		if cost == -1 {
+			break
+		}
+		model := pb.solver.Model()
+		if pb.lastModel == nil {
+			pb.lastModel = model
+		}
+		models = append(models, pb.decode(model))
+		costs = append(costs, cost)
+		if act == 0 {
+			for len(pb.varInts) < pb.solver.NbVars() { // Ids of vars created by the solver itself cannot be used
+				pb.varInts = append(pb.varInts, "")
+			}
+			pb.varInts = append(pb.varInts, "")
+			act = len(pb.varInts)
+			assumptions = []solver.Lit{solver.IntToLit(int32(act))}
+		}
+		lits := []solver.Lit{solver.IntToLit(int32(-act))}
+		for i, name := range pb.varInts {
+			if name == "" {
+				continue
+			}
+			lit := i + 1
+			if model[i] {
+				lit = -lit
+			}
+			lits = append(lits, solver.IntToLit(int32(lit)))
+		}
+		if len(lits) == 1 { // No var: there is no other model
+			break
+		}
+		pb.solver.AppendClause(solver.NewClause(lits))
+	}
+	return models, costs
+}
@@ -1,5 +1,10 @@
 package solver
 
+import (
+	"fmt"
+	"sort"
+)
+
 // A CardConstr is a cardinality constraint, i.e a set of literals (represented with integer variables) associated with a minimal number of literals that must be true.
 // A propositional clause (i.e a disjunction of literals) is a cardinality constraint with a minimal cardinality of 1.
 type CardConstr struct {
@@ -26,3 +31,130 @@ func AtMost1(lits ...int) CardConstr {
 func Exactly1(lits ...int) []CardConstr {
 	return []CardConstr{AtLeast1(lits...), AtMost1(lits...)}
 }
+
+// A CardinalityEncoder translates cardinality constraints into clauses, with a sequential counter encoding,
+// and caches the translations, so that identical constraints share the same encoding and auxiliary vars.
+// This is useful when a problem, or a set of related problems, contains many identical cardinality constraints.
+type CardinalityEncoder struct {
+	// NbVars is the highest var id used so far: auxiliary vars are numbered from NbVars+1.
+	// It is updated as constraints are encoded. If the problem has vars that do not appear in the encoded constraints,
+	// it must be set to the number of vars of the problem before the first call to Encode.
+	NbVars int
+	cache  map[string][]PBConstr // Translation of each constraint encoded so far, indexed by its sorted lits and its bound
+}
+
+// NewCardinalityEncoder returns a new encoder, with an empty cache.
+func NewCardinalityEncoder() *CardinalityEncoder {
+	return &CardinalityEncoder{cache: make(map[string][]PBConstr)}
+}
+
+// Encode returns clauses equivalent to the constraint stating that at least k of the given lits must be true,
+// in the same format as the result of AtLeast, along with auxiliary vars.
+// Encoding the same lits, in any order, with the same bound, returns the same clauses, with the same auxiliary vars.
+// The returned constraints are copies, and can be freely modified, e.g given to a solver.
+// If the constraint is trivially satisfied, no constraint is returned; if it cannot be satisfied, the result is an empty clause.
+func (e *CardinalityEncoder) Encode(lits []int, k int) []PBConstr {
+	sorted := make([]int, len(lits))
+	copy(sorted, lits)
+	sort.Ints(sorted)
+	key := fmt.Sprint(sorted, k)
+	cs, ok := e.cache[key]
+	if !ok {
+		for _, lit := range lits {
+			if abs(lit) > e.NbVars {
+				e.NbVars = abs(lit)
+			}
+		}
+		cs = e.encode(sorted, k)
+		e.cache[key] = cs
+	}
+	res := make([]PBConstr, len(cs))
+	for i, c := range cs {
+		res[i] = c.clone()
+	}
+	return res
+}
+
+// encode returns clauses stating that at least k of the given lits must be true.
+// The encoding is a sequential counter over the true lits, up to k, or over the false lits, up to len(lits)-k,
+// whichever is smaller, so that it needs O(n·min(k, n-k)) clauses and auxiliary vars, where n is the number of lits.
+func (e *CardinalityEncoder) encode(lits []int, k int) []PBConstr {
+	n := len(lits)
+	switch {
+	case k <= 0:
+		return nil
+	case k > n:
+		return []PBConstr{PropClause()}
+	case k == 1:
+		return []PBConstr{PropClause(append([]int(nil), lits...)...)}
+	case k == n:
+		res := make([]PBConstr, n)
+		for i, lit := range lits {
+			res[i] = PropClause(lit)
+		}
+		return res
+	}
+	if k <= n-k {
+		return e.encodeTrue(lits, k)
+	}
+	return e.encodeFalse(lits, k)
+}
+
+// counter returns fresh auxiliary vars s(i, j), for 0 <= i < n-1 and 1 <= j <= m, for a sequential counter
+// over n lits counting up to m. s[i][0] is unused, so that indices match the counted number of lits.
+func (e *CardinalityEncoder) counter(n, m int) [][]int {
+	s := make([][]int, n-1)
+	for i := range s {
+		s[i] = make([]int, m+1)
+		for j := 1; j <= m; j++ {
+			e.NbVars++
+			s[i][j] = e.NbVars
+		}
+	}
+	return s
+}
+
+// encodeTrue encodes the constraint with a sequential counter over the true lits, with 1 < k < len(lits):
+// aux var s(i, j) implies that at least j of the first i+1 lits are true.
+func (e *CardinalityEncoder) encodeTrue(lits []int, k int) []PBConstr {
+	n := len(lits)
+	s := e.counter(n, k)
+	var res []PBConstr
+	res = append(res, PropClause(-s[0][1], lits[0]))
+	for j := 2; j <= k; j++ {
+		res = append(res, PropClause(-s[0][j]))
+	}
+	for i := 1; i < n-1; i++ {
+		res = append(res, PropClause(-s[i][1], s[i-1][1], lits[i]))
+		for j := 2; j <= k; j++ {
+			res = append(res, PropClause(-s[i][j], s[i-1][j], lits[i]), PropClause(-s[i][j], s[i-1][j], s[i-1][j-1]))
+		}
+	}
+	// At least k of all lits are true iff at least k of the first n-1 are, or at least k-1 of them are and the last one is
+	res = append(res, PropClause(s[n-2][k], lits[n-1]), PropClause(s[n-2][k], s[n-2][k-1]))
+	return res
+}
+
+// encodeFalse encodes the constraint with a sequential counter over the false lits, with 1 < k < len(lits):
+// at least k lits are true iff at most len(lits)-k of their negations are, and
+// aux var s(i, j) is true if at least j of the first i+1 negations are true.
+func (e *CardinalityEncoder) encodeFalse(lits []int, k int) []PBConstr {
+	n := len(lits)
+	m := n - k // Max number of false lits
+	s := e.counter(n, m)
+	// x is false iff -x is true; "x is false" is thus written lit, and "x is true" is written -lit, in the clauses below
+	var res []PBConstr
+	res = append(res, PropClause(lits[0], s[0][1]))
+	for j := 2; j <= m; j++ {
+		res = append(res, PropClause(-s[0][j]))
+	}
+	for i := 1; i < n-1; i++ {
+		res = append(res, PropClause(lits[i], s[i][1]), PropClause(-s[i-1][1], s[i][1]))
+		for j := 2; j <= m; j++ {
+			res = append(res, PropClause(lits[i], -s[i-1][j-1], s[i][j]), PropClause(-s[i-1][j], s[i][j]))
+		}
+		res = append(res, PropClause(lits[i], -s[i-1][m]))
+	}
+	res = append(res, PropClause(lits[n-1], -s[n-2][m]))
+	return res
+}
@@ -1,6 +1,7 @@
 package solver
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -40,3 +41,48 @@ func TestExactly1(t *testing.T) {
 		}
 	}
 }
+
+func TestCardinalityEncoder(t *testing.T) {
+	lits := []int{1, -2, 3, 4, -5}
+	for k := 0; k <= len(lits)+1; k++ {
+		e := NewCardinalityEncoder()
+		cs := e.Encode(lits, k)
+		for _, c := range cs {
+			if c.Weights != nil || (c.AtLeast != 1 && len(c.Lits) != 0) {
+				t.Fatalf("k=%d: constraint %v is not a clause", k, c)
+			}
+		}
+		for assign := 0; assign < 1<<len(lits); assign++ {
+			constrs := append([]PBConstr(nil), e.Encode(lits, k)...)
+			nbTrue := 0
+			for i, lit := range lits {
+				v := i + 1
+				if assign&(1<<i) == 0 {
+					v = -v
+				}
+				if v == lit {
+					nbTrue++
+				}
+				constrs = append(constrs, PropClause(v))
+			}
+			status := New(ParsePBConstrs(constrs)).Solve()
+			if expected := nbTrue >= k; (status == Sat) != expected {
+				t.Errorf("k=%d, assignment %b: expected sat=%t, got %v", k, assign, expected, status)
+			}
+		}
+	}
+	e := NewCardinalityEncoder()
+	cs1 := e.Encode([]int{1, 2, 3, 4}, 2)
+	nbVars := e.NbVars
+	cs2 := e.Encode([]int{4, 3, 2, 1}, 2)
+	if e.NbVars != nbVars || fmt.Sprint(cs1) != fmt.Sprint(cs2) {
+		t.Errorf("expected identical constraints to share their encoding")
+	}
+	cs2[0].Lits[0] = 42
+	if cs3 := e.Encode([]int{1, 2, 3, 4}, 2); fmt.Sprint(cs1) != fmt.Sprint(cs3) {
+		t.Errorf("cached encoding was modified")
+	}
+	if e.Encode([]int{1, 2, 3, 4}, 3); e.NbVars == nbVars {
+		t.Errorf("expected new auxiliary vars for a different bound")
+	}
+}
@@ -17,19 +17,14 @@ type Clause struct {
 	lits []Lit
 	// lbdValue's bits are as follow:
 	// leftmost bit: learned flag.
-	// second bit: locked flag (if learned).
-	// last 30 bits: LBD value (if learned) or minimal cardinality - 1 (if !learned).
+	// last 31 bits: LBD value (if learned) or minimal cardinality - 1 (if !learned).
 	// NOTE: actual cardinality is value + 1, since this is the default value and go defaults to 0.
 	lbdValue uint32
 	activity float32
 	pbData   *pbData
 }
 
-const (
-	learnedMask uint32 = 1 << 31
-	lockedMask  uint32 = 1 << 30
-	bothMasks   uint32 = learnedMask | lockedMask
-)
+const learnedMask uint32 = 1 << 31
 
 // NewClause returns a clause whose lits are given as an argument.
 func NewClause(lits []Lit) *Clause {
@@ -88,7 +83,7 @@ func (c *Clause) Cardinality() int {
 	if c.Learned() {
 		return 1
 	}
-	return int(c.lbdValue & ^bothMasks) + 1
+	return int(c.lbdValue & ^learnedMask) + 1
 }
 
 // Learned returns true iff c was a learned clause.
@@ -102,30 +97,18 @@ func (c *Clause) PseudoBoolean() bool {
 	return c.pbData != nil
 }
 
-func (c *Clause) lock() {
-	c.lbdValue = c.lbdValue | lockedMask
-}
-
-func (c *Clause) unlock() {
-	c.lbdValue = c.lbdValue & ^lockedMask
-}
-
 func (c *Clause) lbd() int {
-	return int(c.lbdValue & ^bothMasks)
+	return int(c.lbdValue & ^learnedMask)
 }
 
 func (c *Clause) setLbd(lbd int) {
-	c.lbdValue = (c.lbdValue & bothMasks) | uint32(lbd)
+	c.lbdValue = (c.lbdValue & learnedMask) | uint32(lbd)
 }
 
 func (c *Clause) incLbd() {
 	c.lbdValue++
 }
 
-func (c *Clause) isLocked() bool {
-	return c.lbdValue&bothMasks == bothMasks
-}
-
 // Len returns the nb of lits in the clause.
 func (c *Clause) Len() int {
 	return len(c.lits)
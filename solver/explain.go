@@ -0,0 +1,236 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxExplainVars is the maximum number of vars of a problem for ExplainUnsat to give a detailed explanation.
+// Explanations of bigger problems would be too long to be of any help.
+const maxExplainVars = 100
+
+// ExplainUnsat returns a human-readable explanation of why the problem is UNSAT.
+// The explanation is a sequence of steps, one per line, each stating which clause and which previous literals
+// force which literal, ending with the clause that is falsified by the previous steps.
+// Clauses from the problem are referenced by their index in the problem, starting at 0;
+// learned clauses are only referenced as such, since they are implied by the problem.
+// Detailed explanations are only given for small problems, and for conflicts arising at the top level;
+// in all other cases, only a short note is returned.
+// If the last call to Solve did not prove the problem UNSAT, the empty string is returned.
+func (s *Solver) ExplainUnsat() string {
+	if s.status != Unsat {
+		return ""
+	}
+	if s.explanation == "" {
+		return "problem is UNSAT, but no detailed explanation is available"
+	}
+	return s.explanation
+}
+
+// explainUnsat records an explanation for the top-level conflict that was just met.
+// confl is the conflict clause, if any, and unit is the learned unit literal that contradicts a top-level binding,
+// or -1 if there is none.
+// It must be called before bindings are cleaned up.
+func (s *Solver) explainUnsat(confl *Clause, unit Lit) {
+	s.explanation = ""
+	if s.nbVars > maxExplainVars {
+		s.explanation = fmt.Sprintf("problem is UNSAT, but it is too big (%d vars) to be explained", s.nbVars)
+		return
+	}
+	var roots []Lit // False lits that lead to the contradiction
+	var last string
+	if confl != nil && s.falseAtTopLevel(confl) {
+		for i := 0; i < confl.Len(); i++ {
+			roots = append(roots, confl.Get(i).Negation())
+		}
+		last = fmt.Sprintf("%s is falsified → contradiction", s.clauseName(confl))
+	} else if unit != -1 && abs(s.model[unit.Var()]) == 1 {
+		roots = []Lit{unit.Negation()}
+		last = fmt.Sprintf("a learned clause forces %s → contradiction", litName(unit))
+	} else {
+		return
+	}
+	steps := make([]string, 0, len(roots)+1)
+	for _, lit := range s.topLevelImplicants(roots) {
+		v := lit.Var()
+		reason := s.reason[v]
+		switch {
+		case reason != nil:
+			var premises []string
+			for i := 0; i < reason.Len(); i++ {
+				if lit2 := reason.Get(i); lit2.Var() != v && s.litStatus(lit2) == Unsat {
+					premises = append(premises, litName(lit2.Negation()))
+				}
+			}
+			if len(premises) == 0 {
+				steps = append(steps, fmt.Sprintf("%s forces %s", s.clauseName(reason), litName(lit)))
+			} else {
+				steps = append(steps, fmt.Sprintf("%s and %s force %s", s.clauseName(reason), strings.Join(premises, ", "), litName(lit)))
+			}
+		case s.assumptions[v]:
+			steps = append(steps, fmt.Sprintf("%s is assumed", litName(lit)))
+		default:
+			steps = append(steps, fmt.Sprintf("%s is a unit, either given or learned", litName(lit)))
+		}
+	}
+	s.explanation = strings.Join(append(steps, last), ";\n")
+}
+
+// falseAtTopLevel returns true iff all lits in c are false at the top level.
+func (s *Solver) falseAtTopLevel(c *Clause) bool {
+	for i := 0; i < c.Len(); i++ {
+		lit := c.Get(i)
+		if abs(s.model[lit.Var()]) != 1 || s.litStatus(lit) != Unsat {
+			return false
+		}
+	}
+	return true
+}
+
+// topLevelImplicants returns the given top-level lits, along with all the lits they were deduced from,
+// in the order they were bound.
+func (s *Solver) topLevelImplicants(lits []Lit) []Lit {
+	seen := make(map[Var]bool)
+	var res []Lit
+	for len(lits) > 0 {
+		lit := lits[len(lits)-1]
+		lits = lits[:len(lits)-1]
+		v := lit.Var()
+		if seen[v] || abs(s.model[v]) != 1 {
+			continue
+		}
+		seen[v] = true
+		res = append(res, lit)
+		if reason := s.reason[v]; reason != nil {
+			for i := 0; i < reason.Len(); i++ {
+				if lit2 := reason.Get(i); lit2.Var() != v && s.litStatus(lit2) == Unsat {
+					lits = append(lits, lit2.Negation())
+				}
+			}
+		}
+	}
+	pos := make(map[Var]int, len(s.trail))
+	for i, lit := range s.trail {
+		pos[lit.Var()] = i
+	}
+	sort.Slice(res, func(i, j int) bool { return pos[res[i].Var()] < pos[res[j].Var()] })
+	return res
+}
+
+// clauseName returns a human-readable reference to the given clause.
+func (s *Solver) clauseName(c *Clause) string {
+	desc := c.PBString()
+	if !c.PseudoBoolean() && c.Cardinality() == 1 {
+		lits := make([]string, c.Len())
+		for i := range lits {
+			lits[i] = litName(c.Get(i))
+		}
+		desc = strings.Join(lits, " ∨ ")
+	}
+	if c.Learned() {
+		return fmt.Sprintf("learned clause (%s)", desc)
+	}
+	for i, c2 := range s.wl.origClauses {
+		if c2 == c {
+			return fmt.Sprintf("clause #%d (%s)", i, desc)
+		}
+	}
+	return fmt.Sprintf("clause (%s)", desc)
+}
+
+// litName returns a human-readable representation of lit, such as "x3" or "¬x3".
+func litName(lit Lit) string {
+	if lit.IsPositive() {
+		return fmt.Sprintf("x%d", lit.Int())
+	}
+	return fmt.Sprintf("¬x%d", -lit.Int())
+}
+
+// TopLevelConflict returns the indices of constraints that contradict each other according to unit propagation alone,
+// i.e without any decision, in increasing order, or nil if unit propagation finds no contradiction.
+// Constraints are propagated with the solver's own propagation, and the returned indices are those of the falsified
+// constraint and of the constraints that forced the bindings falsifying it, so the set is small, but not necessarily minimal.
+// Unlike ParsePBConstrs, no simplification takes place, so that each binding can be traced back to a constraint.
+func TopLevelConflict(constrs []PBConstr) []int {
+	nbVars := 0
+	for _, constr := range constrs {
+		for _, lit := range constr.Lits {
+			if abs(lit) > nbVars {
+				nbVars = abs(lit)
+			}
+		}
+	}
+	s := New(&Problem{NbVars: nbVars, Model: make([]decLevel, nbVars)})
+	index := make(map[*Clause]int, len(constrs))
+	for i, constr := range constrs {
+		if constr.AtLeast <= 0 { // Trivially satisfied
+			continue
+		}
+		if constr.WeightSum() < constr.AtLeast { // Cannot be satisfied, whatever the bindings
+			return []int{i}
+		}
+		// All constraints are PB constraints, so that they all propagate through their slack, including units
+		lits := make([]Lit, len(constr.Lits))
+		weights := make([]int, len(constr.Lits))
+		for j, lit := range constr.Lits {
+			lits[j] = IntToLit(int32(lit))
+			weights[j] = 1
+			if constr.Weights != nil {
+				weights[j] = constr.Weights[j]
+			}
+		}
+		c := NewPBClause(lits, weights, constr.AtLeast)
+		index[c] = i
+		s.appendClause(c)
+	}
+	// Constraints that propagate with no binding at all are not triggered by their watched lits: check them all once
+	ptr := 0
+	for _, c := range s.wl.origClauses {
+		confl := c
+		if s.simplifyPseudoBool(c, 1) {
+			confl = s.propagate(ptr, 1)
+		}
+		if confl != nil {
+			return s.conflictIndices(confl, index)
+		}
+		ptr = len(s.trail)
+	}
+	return nil
+}
+
+// conflictIndices returns the sorted indices, according to index, of the top-level conflict clause confl
+// and of the reasons of the bindings that falsify it, recursively.
+// Only bindings made before a lit can explain it, so reasons are only followed backwards in the trail.
+func (s *Solver) conflictIndices(confl *Clause, index map[*Clause]int) []int {
+	pos := make([]int, s.nbVars) // For each bound var, its position in the trail, starting at 1
+	for i, lit := range s.trail {
+		pos[lit.Var()] = i + 1
+	}
+	type step struct {
+		c     *Clause
+		limit int // Only vars bound before this position can explain c
+	}
+	idx := map[int]bool{index[confl]: true}
+	seen := make([]bool, s.nbVars)
+	stack := []step{{confl, len(s.trail) + 1}}
+	for len(stack) > 0 {
+		st := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, lit := range st.c.lits {
+			if v := lit.Var(); !seen[v] && s.litStatus(lit) == Unsat && pos[v] < st.limit {
+				seen[v] = true
+				if reason := s.reason[v]; reason != nil {
+					idx[index[reason]] = true
+					stack = append(stack, step{reason, pos[v]})
+				}
+			}
+		}
+	}
+	res := make([]int, 0, len(idx))
+	for i := range idx {
+		res = append(res, i)
+	}
+	sort.Ints(res)
+	return res
+}
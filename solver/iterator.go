@@ -0,0 +1,75 @@
+package solver
+
+// A ModelIterator iterates over the models of a problem, one at a time, as in:
+//
+//	it := s.ModelIterator()
+//	defer it.Close()
+//	for it.Next() {
+//		model := it.Model()
+//		// ...
+//	}
+//
+// Each model is blocked by a clause guarded by an activation literal, so that the blocking clauses
+// can be retracted by Close once the iteration is over.
+type ModelIterator struct {
+	s      *Solver
+	act    Lit    // Activation literal guarding the blocking clauses
+	nbVars int    // Number of vars of the problem when the iterator was created; the other ones are not part of the models
+	model  []bool // Current model, or nil if Next was not called yet or returned false
+	done   bool   // Whether Next returned false, or Close was called
+}
+
+// ModelIterator returns an iterator over the models of the problem.
+// Models only contain the vars that existed when the iterator was created.
+// Other methods of the solver should not be called until the iterator is closed.
+func (s *Solver) ModelIterator() *ModelIterator {
+	it := &ModelIterator{s: s, nbVars: s.nbVars}
+	it.act = s.newActivation()
+	s.guards = append(s.guards, it.act)
+	s.Assume(s.assumed)
+	return it
+}
+
+// Next advances to the next model and returns true, or returns false if there is no other model,
+// or if the iterator was closed.
+func (it *ModelIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if it.model != nil { // Block the current model
+		blocking := make([]Lit, 0, len(it.model)+1)
+		for i, val := range it.model {
+			blocking = append(blocking, Var(i).SignedLit(val))
+		}
+		it.s.appendGuarded(NewClause(append(blocking, it.act.Negation())))
+	}
+	if it.s.Solve() != Sat {
+		it.done = true
+		it.model = nil
+		return false
+	}
+	it.model = it.s.Model()[:it.nbVars]
+	return true
+}
+
+// Model returns the current model, i.e the one found by the last call to Next.
+// It panics if the last call to Next returned false.
+func (it *ModelIterator) Model() []bool {
+	if it.model == nil {
+		panic("cannot call Model() when Next() did not return true")
+	}
+	return it.model
+}
+
+// Close ends the iteration and retracts the clauses blocking the models that were visited,
+// so that the solver can be used again as if the iteration never happened.
+// Calling Close several times has no effect.
+func (it *ModelIterator) Close() {
+	if it.s == nil {
+		return
+	}
+	it.s.retract(it.act, it.s.assumed)
+	it.s = nil
+	it.done = true
+	it.model = nil
+}
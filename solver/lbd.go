@@ -87,3 +87,16 @@ func (l *lbdStats) clear() {
 	l.lbdData.nbRecent = 0
 	l.lbdData.recentAvg = 0.0
 }
+
+// LBDHistogram returns, for each LBD value, the number of learned clauses currently in the database with that LBD.
+// It reflects the state of the database at the moment of the call: clauses that were deleted are not counted.
+// Only learned propositional clauses are considered, constraints learned with cutting planes have no LBD.
+func (s *Solver) LBDHistogram() map[int]int {
+	res := make(map[int]int)
+	for _, c := range s.wl.learned {
+		if c.Learned() {
+			res[c.lbd()]++
+		}
+	}
+	return res
+}
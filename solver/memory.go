@@ -0,0 +1,72 @@
+package solver
+
+import "errors"
+
+// ErrMemoryLimit is returned by Err when the last search was stopped because the memory limit set by SetMemoryLimit was exceeded.
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// Estimated sizes, in bytes, of the structures making the clause database, on a 64-bit architecture.
+const (
+	clauseSize  = 48 // A Clause, including allocation overhead
+	litSize     = 4  // A Lit in a clause
+	watcherSize = 16 // A watcher in a watch list; each clause is watched twice
+	pbDataSize  = 48 // The pbData of a PB or cardinality constraint
+	pbLitSize   = 17 // The weight and watched flag of a lit of a PB constraint, along with its entry in the PB watch lists
+)
+
+// memCheckInterval is the number of conflicts between two checks of the memory usage, since it is costly to estimate.
+const memCheckInterval = 64
+
+// SetMemoryLimit limits the memory the clause database can use while searching, in bytes, as estimated by MemoryUsage.
+// Once the limit is exceeded, Solve stops and returns Indet, and Err returns ErrMemoryLimit, rather than
+// learning more clauses. Minimize then returns the cost of the best model found so far, if any.
+// The limit is checked regularly rather than after each conflict, so it can be slightly exceeded.
+// Nothing learned so far is lost, so calling Solve again resumes the search, as long as the limit was raised.
+// A limit of 0 or less removes the limit.
+func (s *Solver) SetMemoryLimit(bytes int64) {
+	if bytes <= 0 {
+		bytes = 0
+	}
+	s.memLimit = bytes
+}
+
+// MemoryUsage returns an estimate, in bytes, of the memory used by the clause database of the solver,
+// i.e by the clauses of the problem and the learned ones, along with their watchers.
+// Other structures, whose size only depends on the number of vars, are not taken into account.
+func (s *Solver) MemoryUsage() int64 {
+	var res int64
+	for _, clauses := range [][]*Clause{s.wl.origClauses, s.wl.learned} {
+		for _, c := range clauses {
+			res += clauseSize + watcherSize*2 + int64(litSize*c.Len())
+			if c.pbData != nil {
+				res += pbDataSize + int64(pbLitSize*c.Len())
+			}
+		}
+	}
+	return res
+}
+
+// Err returns ErrMemoryLimit if the last call to Solve stopped because the limit set by SetMemoryLimit was exceeded,
+// ErrStopped if it stopped because Stop was called, and nil otherwise.
+func (s *Solver) Err() error {
+	switch {
+	case s.memExceeded:
+		return ErrMemoryLimit
+	case s.stopped:
+		return ErrStopped
+	}
+	return nil
+}
+
+// memoryExceeded returns true iff the limit set by SetMemoryLimit, if any, was exceeded.
+// The memory usage is only estimated every memCheckInterval conflicts.
+func (s *Solver) memoryExceeded() bool {
+	if s.memLimit == 0 {
+		return false
+	}
+	if !s.memExceeded && s.Stats.NbConflicts >= s.memChecked+memCheckInterval {
+		s.memChecked = s.Stats.NbConflicts
+		s.memExceeded = s.MemoryUsage() > s.memLimit
+	}
+	return s.memExceeded
+}
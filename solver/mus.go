@@ -0,0 +1,78 @@
+package solver
+
+import "fmt"
+
+// DeletionMUS returns the indices of the constraints of an UNSAT problem that form a minimal unsatisfiable subset (MUS):
+// the problem made of these constraints only is UNSAT, but removing any of them makes it satisfiable.
+// Indices are the positions of the constraints in the slice given to ParsePBConstrs or, for a problem
+// read by ParseOPB, the positions of the constraint lines in the file, the objective function excluded.
+// They are sorted in increasing order.
+// Each constraint is relaxed by a selector var, and the subset is minimized by trying to remove each constraint in turn,
+// through assumptions, so that a single solver is used.
+// An error is returned if the problem was not built from PB constraints, or if it is satisfiable.
+func (pb *Problem) DeletionMUS() ([]int, error) {
+	if pb.constrs == nil {
+		return nil, fmt.Errorf("problem was not built by ParsePBConstrs or ParseOPB")
+	}
+	var relaxed []PBConstr
+	var idx []int  // Indices of the constraints that can take part in a MUS
+	var sels []Lit // For each constraint in idx, its selector
+	for i, constrs := range pb.constrs {
+		sel := pb.NbVars + len(idx) + 1
+		used := false
+		for _, c := range constrs {
+			if c.AtLeast <= 0 { // Trivially satisfied
+				continue
+			}
+			used = true
+			relaxed = append(relaxed, relaxPB(c, sel))
+		}
+		if used {
+			idx = append(idx, i)
+			sels = append(sels, IntToLit(int32(sel)))
+		}
+	}
+	s := New(ParsePBConstrs(relaxed))
+	if s.Assume(sels) != Unsat && s.Solve() != Unsat {
+		return nil, fmt.Errorf("problem is satisfiable")
+	}
+	inMUS := make([]bool, len(idx))
+	for i := range inMUS {
+		inMUS[i] = true
+	}
+	for i := range idx {
+		inMUS[i] = false
+		var assumptions []Lit
+		for j, ok := range inMUS {
+			if ok {
+				assumptions = append(assumptions, sels[j])
+			}
+		}
+		if s.Assume(assumptions) != Unsat && s.Solve() != Unsat { // Constraint is needed
+			inMUS[i] = true
+		}
+	}
+	var mus []int
+	for i, ok := range inMUS {
+		if ok {
+			mus = append(mus, idx[i])
+		}
+	}
+	return mus, nil
+}
+
+// relaxPB returns a copy of c that is only enforced when the var sel, in the DIMACS format, is true.
+func relaxPB(c PBConstr, sel int) PBConstr {
+	res := PBConstr{Lits: make([]int, len(c.Lits), len(c.Lits)+1), AtLeast: c.AtLeast}
+	copy(res.Lits, c.Lits)
+	res.Lits = append(res.Lits, -sel)
+	res.Weights = make([]int, len(c.Lits), len(c.Lits)+1)
+	for i := range c.Lits {
+		res.Weights[i] = 1
+		if c.Weights != nil {
+			res.Weights[i] = c.Weights[i]
+		}
+	}
+	res.Weights = append(res.Weights, c.AtLeast)
+	return res
+}
@@ -36,6 +36,35 @@ func runMinTest(test optimTest, t *testing.T) {
 	}
 }
 
+func TestMinimizeAgain(t *testing.T) {
+	pb, err := ParseOPB(strings.NewReader("min: +1 x1 +2 x2 +3 x3 ;\n+1 x1 +1 x2 +1 x3 >= 1 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	s := New(pb)
+	if cost := s.Minimize(); cost != 1 {
+		t.Errorf("invalid cost: expected 1, got %d", cost)
+	}
+	// Bounds on the cost added by the first call must not prevent finding more expensive models afterwards
+	s.AppendClause(NewClause([]Lit{IntToLit(-1)}))
+	if cost := s.Minimize(); cost != 2 {
+		t.Errorf("invalid cost after adding a clause: expected 2, got %d", cost)
+	}
+	s.Assume([]Lit{IntToLit(-2)})
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost under assumptions: expected 3, got %d", cost)
+	}
+}
+
+func TestSetCostFuncTriviallyUnsat(t *testing.T) {
+	s := New(ParseSlice([][]int{{1}, {-1}, {2, 3}}))
+	s.SetCostFunc([]Lit{IntToLit(2), IntToLit(3)}, []int{1, 2})
+	s.SetCostBound(1)
+	if cost := s.Minimize(); cost != -1 {
+		t.Errorf("expected unsat, got cost %d", cost)
+	}
+}
+
 func runOptimTest(test optimTest, results chan Result, t *testing.T) {
 	f, err := os.Open(test.path)
 	if err != nil {
@@ -117,3 +146,91 @@ func runOptimBench(path string, b *testing.B) {
 func BenchmarkLo88(b *testing.B) {
 	runOptimBench("testcnf/lo_8x8_009.opb", b)
 }
+
+func TestCostBound(t *testing.T) {
+	pb := ParsePBConstrs([]PBConstr{AtLeast([]int{1, 2, 3}, 2)})
+	pb.SetCostFunc(IntsToLits(1, 2, 3), []int{1, 2, 3})
+	s := New(pb)
+	s.SetCostBound(2)
+	if cost := s.Minimize(); cost != -1 {
+		t.Errorf("expected no model with cost <= 2, got cost %d", cost)
+	}
+	s.SetCostBound(4)
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost: expected 3, got %d", cost)
+	}
+	s.ClearCostBound()
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost after clearing bound: expected 3, got %d", cost)
+	}
+	nbVars, nbClauses := s.NbVars(), s.NbClauses()
+	for _, bound := range []int{5, 2, 3, 4, 1, 6} {
+		s.SetCostBound(bound)
+		expected := 3
+		if bound < 3 {
+			expected = -1
+		}
+		if cost := s.Minimize(); cost != expected {
+			t.Errorf("bound %d: invalid cost: expected %d, got %d", bound, expected, cost)
+		}
+	}
+	if s.NbVars() != nbVars || s.NbClauses() != nbClauses {
+		t.Errorf("expected bounds to reuse the same constraint, got %d vars and %d clauses instead of %d and %d",
+			s.NbVars(), s.NbClauses(), nbVars, nbClauses)
+	}
+}
+
+func TestCostLowerBound(t *testing.T) {
+	pb := ParsePBConstrs([]PBConstr{AtLeast([]int{1, 2, 3}, 2)})
+	pb.SetCostFunc(IntsToLits(1, 2, 3), []int{1, 2, 3})
+	s := New(pb)
+	s.SetCostLowerBound(3)
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost with valid lower bound: expected 3, got %d", cost)
+	}
+	s.SetCostLowerBound(6) // Invalid bound: any model is accepted
+	if cost := s.Minimize(); cost < 3 || cost > 5 {
+		t.Errorf("invalid cost with invalid lower bound: expected a cost between 3 and 5, got %d", cost)
+	}
+	s.SetCostLowerBound(0)
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost after clearing lower bound: expected 3, got %d", cost)
+	}
+}
+
+func TestMaxObjective(t *testing.T) {
+	opb := `* #variable= 4 #constraint= 3
+max: +3 x1 +2 x2 +4 x3 -1 x4 ;
++1 ~x1 +1 ~x3 >= 1 ;
++1 ~x2 +1 ~x3 >= 1 ;
++1 x4 >= 1 ;
+`
+	pb, err := ParseOPB(strings.NewReader(opb))
+	if err != nil {
+		t.Fatalf("could not parse OPB: %v", err)
+	}
+	if dir := pb.ObjectiveDirection(); dir != Maximization {
+		t.Errorf("invalid objective direction: expected %d, got %d", Maximization, dir)
+	}
+	s := New(pb)
+	cost := s.Minimize()
+	if cost == -1 {
+		t.Fatalf("expected sat, got unsat")
+	}
+	if val := pb.ObjectiveValue(cost); val != 4 {
+		t.Errorf("invalid objective value: expected 4, got %d", val)
+	}
+	if model := s.Model(); !model[0] || !model[1] || model[2] || !model[3] {
+		t.Errorf("invalid optimal model %v", model)
+	}
+	pb, err = ParseOPB(strings.NewReader("min: +3 x1 ;\n+1 x1 >= 1 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse OPB: %v", err)
+	}
+	if dir := pb.ObjectiveDirection(); dir != Minimization {
+		t.Errorf("invalid objective direction: expected %d, got %d", Minimization, dir)
+	}
+	if val := pb.ObjectiveValue(New(pb).Minimize()); val != 3 {
+		t.Errorf("invalid objective value: expected 3, got %d", val)
+	}
+}
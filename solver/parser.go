@@ -6,12 +6,14 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ParseSlice parse a slice of slice of lits and returns the equivalent problem.
 // The argument is supposed to be a well-formed CNF.
 func ParseSlice(cnf [][]int) *Problem {
 	var pb Problem
+	defer pb.setParseDuration(time.Now())
 	pb.parseSlice(cnf)
 	return &pb
 }
@@ -21,6 +23,7 @@ func ParseSlice(cnf [][]int) *Problem {
 // The number of vars is provided because clauses might be added to it later.
 func ParseSliceNb(cnf [][]int, nbVars int) *Problem {
 	pb := Problem{NbVars: nbVars}
+	defer pb.setParseDuration(time.Now())
 	pb.parseSlice(cnf)
 	return &pb
 }
@@ -72,6 +75,64 @@ func (pb *Problem) parseSlice(cnf [][]int) {
 	pb.simplify2()
 }
 
+// A ParseError is an error met while parsing a problem, because its syntax is invalid.
+// Errors due to the underlying reader are not ParseErrors, but they are wrapped
+// in the errors returned by the parsing functions, so that callers can use errors.As to tell both kinds apart.
+type ParseError struct {
+	Line int    // Line where the error was found, starting at 1.
+	Col  int    // Column where the error was found, starting at 1, or 0 if unknown.
+	Msg  string // Description of the error.
+}
+
+func (e *ParseError) Error() string {
+	if e.Col == 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d, col %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// A posReader is a bufio.Reader that keeps track of the position of the last read byte, for error reporting.
+type posReader struct {
+	*bufio.Reader
+	line, col int
+	eol       bool // Was the last read byte a newline?
+}
+
+func newPosReader(r io.Reader) *posReader {
+	return &posReader{Reader: bufio.NewReader(r), line: 1}
+}
+
+// advance updates the current position after b was read.
+func (r *posReader) advance(b byte) {
+	if r.eol {
+		r.line++
+		r.col = 0
+	}
+	r.col++
+	r.eol = b == '\n'
+}
+
+func (r *posReader) ReadByte() (byte, error) {
+	b, err := r.Reader.ReadByte()
+	if err == nil {
+		r.advance(b)
+	}
+	return b, err
+}
+
+func (r *posReader) ReadString(delim byte) (string, error) {
+	line, err := r.Reader.ReadString(delim)
+	for i := 0; i < len(line); i++ {
+		r.advance(line[i])
+	}
+	return line, err
+}
+
+// errorf returns a ParseError located at the last read byte.
+func (r *posReader) errorf(format string, args ...interface{}) error {
+	return &ParseError{Line: r.line, Col: r.col, Msg: fmt.Sprintf(format, args...)}
+}
+
 func isSpace(b byte) bool {
 	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
 }
@@ -81,7 +142,7 @@ func isSpace(b byte) bool {
 // The int can be negated.
 // All spaces before the int value are ignored.
 // Can return EOF.
-func readInt(b *byte, r *bufio.Reader) (res int, err error) {
+func readInt(b *byte, r *posReader) (res int, err error) {
 	for err == nil && isSpace(*b) {
 		*b, err = r.ReadByte()
 	}
@@ -89,19 +150,19 @@ func readInt(b *byte, r *bufio.Reader) (res int, err error) {
 		return res, io.EOF
 	}
 	if err != nil {
-		return res, fmt.Errorf("could not read digit: %v", err)
+		return res, fmt.Errorf("could not read digit: %w", err)
 	}
 	neg := 1
 	if *b == '-' {
 		neg = -1
 		*b, err = r.ReadByte()
 		if err != nil {
-			return 0, fmt.Errorf("cannot read int: %v", err)
+			return 0, fmt.Errorf("cannot read int: %w", err)
 		}
 	}
 	for err == nil {
 		if *b < '0' || *b > '9' {
-			return 0, fmt.Errorf("cannot read int: %q is not a digit", *b)
+			return 0, r.errorf("cannot read int: %q is not a digit", *b)
 		}
 		res = 10*res + int(*b-'0')
 		*b, err = r.ReadByte()
@@ -113,33 +174,35 @@ func readInt(b *byte, r *bufio.Reader) (res int, err error) {
 	return res, err
 }
 
-func parseHeader(r *bufio.Reader) (nbVars, nbClauses int, err error) {
+func parseHeader(r *posReader) (nbVars, nbClauses int, err error) {
 	line, err := r.ReadString('\n')
 	if err != nil {
-		return 0, 0, fmt.Errorf("cannot read header: %v", err)
+		return 0, 0, fmt.Errorf("cannot read header: %w", err)
 	}
 	fields := strings.Fields(line)
 	if len(fields) < 3 {
-		return 0, 0, fmt.Errorf("invalid syntax %q in header", line)
+		return 0, 0, &ParseError{Line: r.line, Msg: fmt.Sprintf("invalid syntax %q in header", line)}
 	}
 	nbVars, err = strconv.Atoi(fields[1])
 	if err != nil {
-		return 0, 0, fmt.Errorf("nbvars not an int : %q", fields[1])
+		return 0, 0, &ParseError{Line: r.line, Msg: fmt.Sprintf("nbvars not an int : %q", fields[1])}
 	}
 	nbClauses, err = strconv.Atoi(fields[2])
 	if err != nil {
-		return 0, 0, fmt.Errorf("nbClauses not an int : '%s'", fields[2])
+		return 0, 0, &ParseError{Line: r.line, Msg: fmt.Sprintf("nbClauses not an int : '%s'", fields[2])}
 	}
 	return nbVars, nbClauses, nil
 }
 
 // ParseCNF parses a CNF file and returns the corresponding Problem.
+// Syntax errors are reported as *ParseError, and errors from f are wrapped in the returned error.
 func ParseCNF(f io.Reader) (*Problem, error) {
-	r := bufio.NewReader(f)
+	r := newPosReader(f)
 	var (
 		nbClauses int
 		pb        Problem
 	)
+	defer pb.setParseDuration(time.Now())
 	b, err := r.ReadByte()
 	for err == nil {
 		if b == 'c' { // Ignore comment
@@ -150,7 +213,7 @@ func ParseCNF(f io.Reader) (*Problem, error) {
 		} else if b == 'p' { // Parse header
 			pb.NbVars, nbClauses, err = parseHeader(r)
 			if err != nil {
-				return nil, fmt.Errorf("cannot parse CNF header: %v", err)
+				return nil, fmt.Errorf("cannot parse CNF header: %w", err)
 			}
 			pb.Model = make([]decLevel, pb.NbVars)
 			pb.Clauses = make([]*Clause, 0, nbClauses)
@@ -165,14 +228,14 @@ func ParseCNF(f io.Reader) (*Problem, error) {
 					break // When there are only several useless spaces at the end of the file, that is ok
 				}
 				if err != nil {
-					return nil, fmt.Errorf("cannot parse clause: %v", err)
+					return nil, fmt.Errorf("cannot parse clause: %w", err)
 				}
 				if val == 0 {
 					pb.Clauses = append(pb.Clauses, NewClause(lits))
 					break
 				} else {
 					if val > pb.NbVars || -val > pb.NbVars {
-						return nil, fmt.Errorf("invalid literal %d for problem with %d vars only", val, pb.NbVars)
+						return nil, r.errorf("invalid literal %d for problem with %d vars only", val, pb.NbVars)
 					}
 					lits = append(lits, IntToLit(int32(val)))
 				}
@@ -181,7 +244,7 @@ func ParseCNF(f io.Reader) (*Problem, error) {
 		b, err = r.ReadByte()
 	}
 	if err != io.EOF {
-		return nil, err
+		return nil, fmt.Errorf("could not read CNF: %w", err)
 	}
 	pb.simplify2()
 	return &pb, nil
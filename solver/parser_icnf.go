@@ -0,0 +1,134 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An IncrementalProblem is a sequence of incremental queries, as read from a file in the ICNF format.
+// Each query is made of the clauses that appear before it in the file, and of a set of assumptions.
+// Queries are run one after the other by SolveAssumptions, on a single solver that keeps its learned clauses
+// from one query to the next.
+type IncrementalProblem struct {
+	NbVars  int // Highest var id in the file
+	solver  *Solver
+	queries []icnfQuery
+	next    int // Index of the next query to run
+}
+
+// An icnfQuery is an incremental query: the clauses added since the previous query, and the assumptions.
+type icnfQuery struct {
+	clauses     [][]int
+	assumptions []int
+}
+
+// ParseICNF parses an ICNF file and returns the corresponding IncrementalProblem.
+// The file starts with a "p inccnf" header, followed by clauses, in the DIMACS format, and by assumption lines,
+// such as "a 1 -2 0", each of which defines a query. Clauses that appear after the last query are ignored.
+// Syntax errors are reported as *ParseError, and errors from r are wrapped in the returned error.
+func ParseICNF(r io.Reader) (*IncrementalProblem, error) {
+	pr := newPosReader(r)
+	var (
+		pb        IncrementalProblem
+		clauses   [][]int
+		cur       []int // Lits read so far for the current clause or assumption line
+		assumeCur bool  // Whether cur is an assumption line
+		header    bool
+	)
+	for {
+		line, err := pr.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("could not read ICNF: %w", err)
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0 || fields[0] == "c":
+		case fields[0] == "p":
+			if len(fields) != 2 || fields[1] != "inccnf" {
+				return nil, &ParseError{Line: pr.line, Msg: fmt.Sprintf("invalid syntax %q in header", strings.TrimSpace(line))}
+			}
+			header = true
+		default:
+			if !header {
+				return nil, &ParseError{Line: pr.line, Msg: "missing \"p inccnf\" header"}
+			}
+			if fields[0] == "a" {
+				if len(cur) != 0 || assumeCur {
+					return nil, &ParseError{Line: pr.line, Msg: "assumption line in the middle of a clause"}
+				}
+				assumeCur = true
+				fields = fields[1:]
+			}
+			for _, field := range fields {
+				val, err := strconv.Atoi(field)
+				if err != nil {
+					return nil, &ParseError{Line: pr.line, Msg: fmt.Sprintf("cannot read int: %q", field)}
+				}
+				if val < 0 && -val > pb.NbVars {
+					pb.NbVars = -val
+				} else if val > pb.NbVars {
+					pb.NbVars = val
+				}
+				if val != 0 {
+					cur = append(cur, val)
+					continue
+				}
+				if assumeCur {
+					pb.queries = append(pb.queries, icnfQuery{clauses: clauses, assumptions: cur})
+					clauses = nil
+					assumeCur = false
+				} else {
+					clauses = append(clauses, cur)
+				}
+				cur = nil
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if !header {
+		return nil, &ParseError{Line: pr.line, Msg: "missing \"p inccnf\" header"}
+	}
+	if len(cur) != 0 || assumeCur {
+		return nil, &ParseError{Line: pr.line, Msg: "unterminated clause or assumption line"}
+	}
+	pb.solver = New(ParseSliceNb(nil, pb.NbVars))
+	return &pb, nil
+}
+
+// NbQueries returns the number of queries of the problem.
+func (pb *IncrementalProblem) NbQueries() int {
+	return len(pb.queries)
+}
+
+// SolveAssumptions runs the next query: the clauses that precede it are added to the solver,
+// and the problem is solved under its assumptions.
+// It returns the status of the query, and true, or Indet and false once all queries were run.
+func (pb *IncrementalProblem) SolveAssumptions() (Status, bool) {
+	if pb.next >= len(pb.queries) {
+		return Indet, false
+	}
+	q := pb.queries[pb.next]
+	pb.next++
+	for _, clause := range q.clauses {
+		if err := pb.solver.AddClause(clause); err != nil {
+			panic(err) // Cannot happen: null lits end clauses
+		}
+	}
+	lits := make([]Lit, len(q.assumptions))
+	for i, val := range q.assumptions {
+		lits[i] = IntToLit(int32(val))
+	}
+	if pb.solver.Assume(lits) == Unsat {
+		return Unsat, true
+	}
+	return pb.solver.Solve(), true
+}
+
+// Solver returns the solver used to run the queries, e.g to get the model found by the last query.
+func (pb *IncrementalProblem) Solver() *Solver {
+	return pb.solver
+}
@@ -6,12 +6,14 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // ParseCardConstrs parses the given cardinality constraints.
 // Will panic if a zero value appears in the literals.
 func ParseCardConstrs(constrs []CardConstr) *Problem {
 	var pb Problem
+	defer pb.setParseDuration(time.Now())
 	for _, constr := range constrs {
 		card := constr.AtLeast
 		if card <= 0 { // Clause is trivially SAT, ignore
@@ -76,6 +78,11 @@ func (pb *Problem) appendClause(constr PBConstr) {
 // ParsePBConstrs parses and returns a PB problem from PBConstr values.
 func ParsePBConstrs(constrs []PBConstr) *Problem {
 	var pb Problem
+	defer pb.setParseDuration(time.Now())
+	pb.constrs = make([][]PBConstr, len(constrs))
+	for i, constr := range constrs { // Copied, since building clauses reorders weights
+		pb.constrs[i] = []PBConstr{constr.clone()}
+	}
 	for _, constr := range constrs {
 		for i := range constr.Lits {
 			lit := IntToLit(int32(constr.Lits[i]))
@@ -129,7 +136,10 @@ func ParsePBConstrs(constrs []PBConstr) *Problem {
 	return &pb
 }
 
-// parsePBOptim parses the "min:" instruction.
+// parsePBOptim parses the "min:" or "max:" instruction.
+// A "max:" objective is turned into a cost function by negating its weights: since w.l = w - w.~l,
+// each resulting negative term is then rewritten with the negation of its lit and a positive weight,
+// and the constant part is recorded as an offset.
 func (pb *Problem) parsePBOptim(fields []string, line string) error {
 	weights, lits, err := pb.parseTerms(fields[1:], line)
 	if err != nil {
@@ -140,6 +150,17 @@ func (pb *Problem) parsePBOptim(fields []string, line string) error {
 		pb.minLits[i] = IntToLit(int32(lit))
 	}
 	pb.minWeights = weights
+	if fields[0] == "max:" {
+		pb.objDir = Maximization
+		for i, w := range weights {
+			if w > 0 {
+				pb.minLits[i] = pb.minLits[i].Negation()
+				pb.objOffset -= w
+			} else {
+				weights[i] = -w
+			}
+		}
+	}
 	return nil
 }
 
@@ -151,7 +172,7 @@ func (pb *Problem) parsePBLine(line string) error {
 	if len(fields) == 0 {
 		return fmt.Errorf("empty line in file")
 	}
-	if fields[0] == "min:" { // Optimization constraint
+	if fields[0] == "min:" || fields[0] == "max:" { // Optimization constraint
 		return pb.parsePBOptim(fields, line)
 	}
 	return pb.parsePBConstrLine(fields, line)
@@ -179,6 +200,11 @@ func (pb *Problem) parsePBConstrLine(fields []string, line string) error {
 	} else {
 		constrs = Eq(lits, weights, rhs)
 	}
+	record := make([]PBConstr, len(constrs))
+	for i, constr := range constrs { // Copied, since building clauses reorders weights
+		record[i] = constr.clone()
+	}
+	pb.constrs = append(pb.constrs, record)
 	for _, constr := range constrs {
 		card := constr.AtLeast
 		sumW := constr.WeightSum()
@@ -245,20 +271,24 @@ func (pb *Problem) parseTerms(terms []string, line string) (weights []int, lits
 
 // ParseOPB parses a file corresponding to the OPB syntax.
 // See http://www.cril.univ-artois.fr/PB16/format.pdf for more details.
+// Syntax errors are reported as *ParseError, and errors from f are wrapped in the returned error.
 func ParseOPB(f io.Reader) (*Problem, error) {
 	scanner := bufio.NewScanner(f)
 	var pb Problem
+	defer pb.setParseDuration(time.Now())
+	lineNb := 0
 	for scanner.Scan() {
+		lineNb++
 		line := scanner.Text()
 		if line == "" || line[0] == '*' {
 			continue
 		}
 		if err := pb.parsePBLine(line); err != nil {
-			return nil, err
+			return nil, &ParseError{Line: lineNb, Msg: err.Error()}
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("could not parse OPB: %v", err)
+		return nil, fmt.Errorf("could not parse OPB: %w", err)
 	}
 	pb.Model = make([]decLevel, pb.NbVars)
 	pb.simplifyPB()
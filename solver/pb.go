@@ -1,5 +1,10 @@
 package solver
 
+import (
+	"fmt"
+	"strings"
+)
+
 // A PBConstr is a Pseudo-Boolean constraint.
 type PBConstr struct {
 	Lits    []int // List of literals, designed with integer values. A positive value means the literal is true, a negative one it is false.
@@ -19,6 +24,35 @@ func (c PBConstr) WeightSum() int {
 	return res
 }
 
+// clone returns a deep copy of c.
+func (c PBConstr) clone() PBConstr {
+	res := PBConstr{Lits: make([]int, len(c.Lits)), AtLeast: c.AtLeast}
+	copy(res.Lits, c.Lits)
+	if c.Weights != nil {
+		res.Weights = make([]int, len(c.Weights))
+		copy(res.Weights, c.Weights)
+	}
+	return res
+}
+
+// pbString returns a representation of c in the OPB format, with its lits in their original order.
+func (c PBConstr) pbString() string {
+	terms := make([]string, len(c.Lits))
+	for i, val := range c.Lits {
+		weight := 1
+		if c.Weights != nil {
+			weight = c.Weights[i]
+		}
+		sign := ""
+		if val < 0 {
+			val = -val
+			sign = "~"
+		}
+		terms[i] = fmt.Sprintf("%d %sx%d", weight, sign, val)
+	}
+	return fmt.Sprintf("%s >= %d ;", strings.Join(terms, " +"), c.AtLeast)
+}
+
 // Clause returns the clause (in fact, a constraint but the type is named Clause) associated with the given constraint.
 func (c PBConstr) Clause() *Clause {
 	lits := make([]Lit, len(c.Lits))
@@ -111,3 +145,48 @@ func Eq(lits []int, weights []int, n int) []PBConstr {
 	}
 	return res
 }
+
+// Reify returns PB constraints stating that the literal b is true iff the sum of all literals multiplied
+// by their weight is at least n, i.e b <=> (weights.lits >= n).
+// Both implications are encoded as a single PB constraint each, relaxed by b or its negation.
+// If weights is nil, all weights are 1.
+// Contrary to GtEq, it does not take ownership of lits and weights.
+// Will panic if weights is not nil and len(weights) != len(lits).
+func Reify(b int, lits []int, weights []int, n int) []PBConstr {
+	if weights != nil && len(lits) != len(weights) {
+		panic("not as many lits as weights")
+	}
+	lits2 := make([]int, len(lits))
+	weights2 := make([]int, len(lits))
+	copy(lits2, lits)
+	for i := range weights2 {
+		weights2[i] = 1
+		if weights != nil {
+			weights2[i] = weights[i]
+		}
+	}
+	ge := GtEq(lits2, weights2, n)
+	card := ge.AtLeast
+	sum := ge.WeightSum()
+	if card <= 0 { // Constraint is always satisfied
+		return []PBConstr{PropClause(b)}
+	}
+	if sum < card { // Constraint can never be satisfied
+		return []PBConstr{PropClause(-b)}
+	}
+	// b => sum >= card
+	fwdLits := append(append([]int(nil), ge.Lits...), -b)
+	fwdWeights := append(append([]int(nil), ge.Weights...), card)
+	// ~b => sum <= card - 1, i.e the sum of the negated lits is at least sum - card + 1
+	card2 := sum - card + 1
+	bwdLits := make([]int, len(ge.Lits)+1)
+	for i, lit := range ge.Lits {
+		bwdLits[i] = -lit
+	}
+	bwdLits[len(ge.Lits)] = b
+	bwdWeights := append(append([]int(nil), ge.Weights...), card2)
+	return []PBConstr{
+		{Lits: fwdLits, Weights: fwdWeights, AtLeast: card},
+		{Lits: bwdLits, Weights: bwdWeights, AtLeast: card2},
+	}
+}
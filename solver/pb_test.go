@@ -1,7 +1,9 @@
 package solver
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -225,3 +227,90 @@ func Benchmark3Col(b *testing.B) {
 func BenchmarkBandwidth(b *testing.B) {
 	runPBBench("testcnf/fixed-bandwidth-10.cnf.gz-extracted.pb", b)
 }
+
+func TestReify(t *testing.T) {
+	for _, test := range []struct {
+		weights []int
+		n       int
+	}{{[]int{2, 1, -1}, 2}, {nil, 2}, {[]int{1, 1, 1}, 0}, {[]int{1, 1, 1}, 4}} {
+		pb := ParsePBConstrs(Reify(4, []int{1, 2, -3}, test.weights, test.n))
+		s := New(pb)
+		nb := s.ForEachModel(func(model []bool) bool {
+			sum := 0
+			for i, lit := range []int{1, 2, -3} {
+				w := 1
+				if test.weights != nil {
+					w = test.weights[i]
+				}
+				if model[abs(lit)-1] == (lit > 0) {
+					sum += w
+				}
+			}
+			if model[3] != (sum >= test.n) {
+				t.Errorf("invalid model %v for weights %v and bound %d", model, test.weights, test.n)
+			}
+			return true
+		})
+		if nb != 8 {
+			t.Errorf("invalid #models for weights %v and bound %d: expected 8, got %d", test.weights, test.n, nb)
+		}
+	}
+}
+
+func TestDeletionMUS(t *testing.T) {
+	pb := ParsePBConstrs([]PBConstr{
+		PropClause(1, 2),
+		PropClause(3),
+		PropClause(-1),
+		GtEq([]int{1, 2, 3}, []int{1, 1, 1}, 0),
+		PropClause(-2),
+		PropClause(-1, -2),
+	})
+	mus, err := pb.DeletionMUS()
+	if err != nil {
+		t.Fatalf("could not compute MUS: %v", err)
+	}
+	if fmt.Sprint(mus) != "[0 2 4]" {
+		t.Errorf("invalid MUS %v", mus)
+	}
+	pb, err = ParseOPB(strings.NewReader("min: +1 x1 ;\n+1 x1 +1 x2 >= 1 ;\n+1 x3 >= 1 ;\n+1 x1 +1 x2 = 0 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse OPB: %v", err)
+	}
+	if mus, err := pb.DeletionMUS(); err != nil || fmt.Sprint(mus) != "[0 2]" {
+		t.Errorf("invalid MUS %v (err: %v)", mus, err)
+	}
+	if _, err := ParsePBConstrs([]PBConstr{PropClause(1, 2)}).DeletionMUS(); err == nil {
+		t.Errorf("expected an error for satisfiable problem")
+	}
+	if _, err := ParseSlice([][]int{{1}, {-1}}).DeletionMUS(); err == nil {
+		t.Errorf("expected an error for CNF problem")
+	}
+}
+
+func TestPreserveOrder(t *testing.T) {
+	pb := ParsePBConstrs([]PBConstr{
+		GtEq([]int{1, 2, -3}, []int{1, 3, 2}, 3),
+		PropClause(4, -1),
+		AtLeast([]int{2, 3, 4}, 2),
+	})
+	canonical := pb.PBString()
+	pb.SetPreserveOrder(true)
+	expected := "1 x1 +3 x2 +2 ~x3 >= 3 ;\n1 x4 +1 ~x1 >= 1 ;\n1 x2 +1 x3 +1 x4 >= 2 ;\n"
+	if got := pb.PBString(); got != expected {
+		t.Errorf("invalid output with preserved order: expected %q, got %q", expected, got)
+	}
+	pb.SetPreserveOrder(false)
+	if got := pb.PBString(); got != canonical {
+		t.Errorf("invalid canonical output: expected %q, got %q", canonical, got)
+	}
+	pb, err := ParseOPB(strings.NewReader("+1 x1 +2 x2 >= 2 ;\n+3 x3 +1 x1 = 3 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse OPB: %v", err)
+	}
+	pb.SetPreserveOrder(true)
+	expected = "1 x1 +2 x2 >= 2 ;\n3 x3 +1 x1 >= 3 ;\n3 ~x3 +1 ~x1 >= 1 ;\n"
+	if got := pb.PBString(); got != expected {
+		t.Errorf("invalid OPB output with preserved order: expected %q, got %q", expected, got)
+	}
+}
@@ -2,17 +2,61 @@ package solver
 
 import (
 	"fmt"
+	"time"
 )
 
 // A Problem is a list of clauses & a nb of vars.
 type Problem struct {
-	NbVars     int        // Total nb of vars
-	Clauses    []*Clause  // List of non-empty, non-unit clauses
-	Status     Status     // Status of the problem. Can be trivially UNSAT (if empty clause was met or inferred by UP) or Indet.
-	Units      []Lit      // List of unit literal found in the problem.
-	Model      []decLevel // For each var, its inferred binding. 0 means unbound, 1 means bound to true, -1 means bound to false.
-	minLits    []Lit      // For an optimisation problem, the list of lits whose sum must be minimized
-	minWeights []int      // For an optimisation problem, the weight of each lit.
+	NbVars     int                // Total nb of vars
+	Clauses    []*Clause          // List of non-empty, non-unit clauses
+	Status     Status             // Status of the problem. Can be trivially UNSAT (if empty clause was met or inferred by UP) or Indet.
+	Units      []Lit              // List of unit literal found in the problem.
+	Model      []decLevel         // For each var, its inferred binding. 0 means unbound, 1 means bound to true, -1 means bound to false.
+	minLits    []Lit              // For an optimisation problem, the list of lits whose sum must be minimized
+	minWeights []int              // For an optimisation problem, the weight of each lit.
+	objDir     ObjectiveDirection // Whether the objective function must be minimized or maximized.
+	objOffset  int                // Constant term of the cost function, so that all of its weights are positive.
+	parseDur   time.Duration      // Time spent parsing the problem
+	constrs    [][]PBConstr       // For a PB problem, the translation of each of its original constraints, used by DeletionMUS
+	preserve   bool               // Whether PBString writes the original constraints, in their original order
+}
+
+// An ObjectiveDirection indicates whether the objective function of an optimization problem
+// must be minimized or maximized.
+type ObjectiveDirection int
+
+const (
+	// Minimization means the objective function must be minimized. This is the default.
+	Minimization ObjectiveDirection = iota
+	// Maximization means the objective function must be maximized.
+	Maximization
+)
+
+// ObjectiveDirection returns the direction of the objective function, as given in the problem.
+// The solver always minimizes a cost: a maximization problem is solved by minimizing
+// the opposite of its objective function.
+func (pb *Problem) ObjectiveDirection() ObjectiveDirection {
+	return pb.objDir
+}
+
+// ObjectiveValue returns the value of the objective function, in the terms of the problem as given,
+// for a model of the given cost, as returned by the solver.
+// For a maximization problem, minimal costs correspond to maximal values.
+func (pb *Problem) ObjectiveValue(cost int) int {
+	if pb.objDir == Maximization {
+		return -(pb.objOffset + cost)
+	}
+	return pb.objOffset + cost
+}
+
+// ParseDuration returns the time that was spent parsing the problem.
+func (pb *Problem) ParseDuration() time.Duration {
+	return pb.parseDur
+}
+
+// setParseDuration sets the time spent parsing the problem to the time elapsed since start.
+func (pb *Problem) setParseDuration(start time.Time) {
+	pb.parseDur = time.Since(start)
 }
 
 // Optim returns true iff pb is an optimisation problem, ie
@@ -34,9 +78,25 @@ func (pb *Problem) CNF() string {
 	return res
 }
 
+// SetPreserveOrder sets whether PBString writes the constraints as they were given to ParsePBConstrs or read by ParseOPB,
+// with their lits in their original order, rather than the simplified, canonical constraints given to the solver,
+// whose lits are sorted by decreasing weight. This is useful to compare the output with the one of another tool.
+// Negative weights are still normalized, and problems that were not built from PB constraints are not impacted.
+func (pb *Problem) SetPreserveOrder(preserve bool) {
+	pb.preserve = preserve
+}
+
 // PBString returns a representation of the problem as a pseudo-boolean problem.
 func (pb *Problem) PBString() string {
 	res := pb.costFuncString()
+	if pb.preserve && pb.constrs != nil {
+		for _, constrs := range pb.constrs {
+			for _, constr := range constrs {
+				res += fmt.Sprintf("%s\n", constr.pbString())
+			}
+		}
+		return res
+	}
 	for _, unit := range pb.Units {
 		sign := ""
 		if !unit.IsPositive() {
@@ -62,6 +122,14 @@ func (pb *Problem) SetCostFunc(lits []Lit, weights []int) {
 	pb.minWeights = weights
 }
 
+// CostFunc returns the function to minimize when optimizing the problem, as set by SetCostFunc or
+// parsed from a "min:" or "max:" OPB instruction.
+// If the problem is not an optimization problem, lits is nil.
+// weights can be nil if all weights are 1.
+func (pb *Problem) CostFunc() (lits []Lit, weights []int) {
+	return pb.minLits, pb.minWeights
+}
+
 // costFuncString returns a string representation of the cost function of the problem, if any, followed by a \n.
 // If there is no cost function, the empty string will be returned.
 func (pb *Problem) costFuncString() string {
@@ -25,6 +25,28 @@ type Stats struct {
 	NbBinaryLearned int // How many binary clauses were learned
 	NbLearned       int // How many clauses were learned
 	NbDeleted       int // How many clauses were deleted
+	NbVivified      int // How many learned clauses were strengthened by vivification
+	NbVivifiedLits  int // How many lits were removed from learned clauses by vivification
+	// Derived metrics, computed incrementally during search
+	AvgBackjump        float64 // Average number of decision levels undone after a conflict
+	LearnedPerConflict float64 // Average number of clauses learned per conflict, including unit ones
+	AvgLBD             float64 // Average LBD of learned clauses; not computed when using cutting planes
+	AvgLearnedLen      float64 // Average number of lits of learned clauses, including unit ones; not computed when using cutting planes
+	nbLBD              int     // How many LBD values were taken into account in AvgLBD and AvgLearnedLen
+}
+
+// addBackjump updates the derived metrics after a conflict led to undo the given number of decision levels.
+func (st *Stats) addBackjump(nbLevels decLevel) {
+	nbConfl := float64(st.NbConflicts)
+	st.AvgBackjump += (float64(nbLevels) - st.AvgBackjump) / nbConfl
+	st.LearnedPerConflict = float64(st.NbLearned+st.NbUnitLearned) / nbConfl
+}
+
+// addLBD updates the average LBD and length of learned clauses after a clause with the given LBD and length was learned.
+func (st *Stats) addLBD(lbd, length int) {
+	st.nbLBD++
+	st.AvgLBD += (float64(lbd) - st.AvgLBD) / float64(st.nbLBD)
+	st.AvgLearnedLen += (float64(length) - st.AvgLearnedLen) / float64(st.nbLBD)
 }
 
 // The level a decision was made.
@@ -66,6 +88,7 @@ type Solver struct {
 	lastModel     Model     // Placeholder for last model found, useful when looking for several models
 	activity      []float64 // How often each var is involved in conflicts
 	polarity      []bool    // Preferred sign for each var
+	frozen        []bool    // For each var, whether it is excluded from decisions by FreezeVar; nil if no var was ever frozen
 	assumptions   []bool    // True iff the var's binding is assumed
 	// For each var, clause considered when it was unified
 	// If the var is not bound yet, or if it was bound by a decision, value is nil.
@@ -74,16 +97,39 @@ type Solver struct {
 	varInc          float64 // On each var bump, how big the increment should be
 	clauseInc       float32 // On each var bump, how big the increment should be
 	lbdStats        lbdStats
-	lubyNextRestart int     // When will the next restart happen when using Luby's strategy?
-	Stats           Stats   // Statistics about the solving process.
-	minLits         []Lit   // Lits to minimize if the problem was an optimization problem.
-	minWeights      []int   // Weight of each lit to minimize if the problem was an optimization problem.
-	hypothesis      []Lit   // Literals that are, ideally, true. Useful when trying to minimize a function.
-	localNbRestarts int     // How many restarts since Solve() was called?
-	varDecay        float64 // On each var decay, how much the varInc should be decayed
-	trailBuf        []int   // A buffer while cleaning bindings
-	pbSetBuf        []int   // A buffer to reduce allocation when performing cutting planes
-	pbSetBuf2       []int   // A buffer to reduce allocation when performing cutting planes
+	lubyNextRestart int              // When will the next restart happen when using Luby's strategy?
+	Stats           Stats            // Statistics about the solving process.
+	minLits         []Lit            // Lits to minimize if the problem was an optimization problem.
+	minWeights      []int            // Weight of each lit to minimize if the problem was an optimization problem.
+	hypothesis      []Lit            // Literals that are, ideally, true. Useful when trying to minimize a function.
+	assumed         []Lit            // Literals currently assumed, as given to Assume.
+	guards          []Lit            // Activation literals of retractable constraints, always assumed.
+	costConstr      *Clause          // Guarded constraint bounding the cost, shared by SetCostBound and Minimize; nil if not created yet.
+	costGuard       Lit              // Activation literal of costConstr.
+	hasCostBound    bool             // Was a bound on the cost set?
+	costBound       int              // Bound set by SetCostBound, if hasCostBound is true.
+	costLowerBound  int              // Minimize stops as soon as it finds a model with at most this cost.
+	conflictLimit   int              // If not 0, Solve stops once Stats.NbConflicts reaches this value.
+	memLimit        int64            // If not 0, Solve stops once the clause database is estimated to use more bytes than this value.
+	memChecked      int              // Value of Stats.NbConflicts when the memory usage was last estimated.
+	memExceeded     bool             // Did the last call to Solve stop because memLimit was exceeded?
+	stopReq         int32            // 1 if Stop was called and no search stopped since; only accessed atomically.
+	stopped         bool             // Did the last call to Solve stop because Stop was called?
+	statsHook       func(Stats)      // If not nil, called regularly with a copy of Stats while searching.
+	onLearn         func([]Lit, int) // If not nil, called with each clause learned during conflict analysis, and its LBD.
+	statsInterval   time.Duration    // Minimal duration between two calls to statsHook.
+	lastStats       time.Time        // Last time statsHook was called.
+	unsat           bool             // Was the problem proven UNSAT, no matter the assumptions?
+	explanation     string           // Human-readable explanation of the last top-level conflict, if any
+	vivification    bool             // Should learned clauses be vivified on restarts?
+	minimization    int              // How learned clauses are minimized: 0 for not at all, 1 for local minimization, 2 for recursive minimization
+	solveDuration   time.Duration    // Total time spent searching
+	nbVivified      int              // Learned clauses before this index were already vivified
+	localNbRestarts int              // How many restarts since Solve() was called?
+	varDecay        float64          // On each var decay, how much the varInc should be decayed
+	trailBuf        []int            // A buffer while cleaning bindings
+	pbSetBuf        []int            // A buffer to reduce allocation when performing cutting planes
+	pbSetBuf2       []int            // A buffer to reduce allocation when performing cutting planes
 }
 
 // New makes a solver, given a number of variables and a set of clauses.
@@ -115,6 +161,7 @@ func New(problem *Problem) *Solver {
 		minLits:         problem.minLits,
 		minWeights:      problem.minWeights,
 		varDecay:        defaultVarDecay,
+		minimization:    1,
 		trailBuf:        make([]int, nbVars),
 		pbSetBuf:        make([]int, nbVars),
 		pbSetBuf2:       make([]int, nbVars),
@@ -145,9 +192,15 @@ func (s *Solver) newVar(v Var) {
 			s.activity = append(s.activity, 0.)
 			s.polarity = append(s.polarity, false)
 			s.reason = append(s.reason, nil)
+			s.assumptions = append(s.assumptions, false)
 			s.trailBuf = append(s.trailBuf, 0)
+			s.pbSetBuf = append(s.pbSetBuf, 0)
+			s.pbSetBuf2 = append(s.pbSetBuf2, 0)
+		}
+		s.varQueue.activity = s.activity // Might have been reallocated
+		for i := s.nbVars; i < cnfVar; i++ {
+			s.varQueue.insert(i)
 		}
-		s.varQueue = newQueue(s.activity)
 		s.addVarWatcherList(v)
 		s.nbVars = cnfVar
 	}
@@ -173,6 +226,29 @@ func (s *Solver) resetOptimPolarity() {
 	}
 }
 
+// SetCostFunc replaces the function to minimize when optimizing the problem.
+// If all weights are 1, weights can be nil.
+// In all other cases, len(lits) must be the same as len(weights).
+// Learned clauses are kept, since they do not depend on the cost function,
+// but the bounds set by SetCostBound and SetCostLowerBound, if any, are removed.
+func (s *Solver) SetCostFunc(lits []Lit, weights []int) {
+	if weights != nil && len(lits) != len(weights) {
+		panic("length of lits and of weights don't match")
+	}
+	assumed := s.assumed
+	s.assume(nil)
+	s.hasCostBound = false
+	s.dropCostConstr() // It was built for the previous cost function
+	s.Assume(assumed)
+	s.costLowerBound = 0
+	s.minLits = lits
+	s.minWeights = weights
+	if s.model == nil { // Problem was trivially UNSAT: there is no var to set the polarity of
+		return
+	}
+	s.resetOptimPolarity()
+}
+
 // Optim returns true iff the underlying problem is an optimization problem (rather than a satisfaction one).
 func (s *Solver) Optim() bool {
 	return s.minLits != nil
@@ -214,6 +290,36 @@ func (s *Solver) litStatus(l Lit) Status {
 	return Unsat
 }
 
+// VarValue returns the value the variable is currently bound to.
+// During search, this is a partial assignment; once Solve returned, it is the last state of the solver,
+// which is not necessarily the model returned by Model.
+// Vars that are not part of the problem are Undef.
+func (s *Solver) VarValue(v Var) LBool {
+	if int(v) < 0 || int(v) >= len(s.model) {
+		return Undef
+	}
+	switch assign := s.model[v]; {
+	case assign > 0:
+		return True
+	case assign < 0:
+		return False
+	default:
+		return Undef
+	}
+}
+
+// LitValue returns the value the literal is currently bound to, as for VarValue.
+func (s *Solver) LitValue(l Lit) LBool {
+	switch val := s.VarValue(l.Var()); {
+	case val == Undef || l.IsPositive():
+		return val
+	case val == True:
+		return False
+	default:
+		return True
+	}
+}
+
 func (s *Solver) varDecayActivity() {
 	s.varInc *= 1 / s.varDecay
 }
@@ -255,10 +361,13 @@ func (s *Solver) clauseBumpActivity(c *Clause) {
 func (s *Solver) chooseLit() Lit {
 	v := Var(-1)
 	for v == -1 && !s.varQueue.empty() {
-		if v2 := Var(s.varQueue.removeMin()); s.model[v2] == 0 { // Ignore already bound vars
+		if v2 := Var(s.varQueue.removeMin()); s.model[v2] == 0 && !s.isFrozen(v2) { // Ignore already bound vars, and frozen ones
 			v = v2
 		}
 	}
+	if v == -1 && s.frozen != nil {
+		v = s.unboundFrozen()
+	}
 	if v == -1 {
 		return Lit(-1)
 	}
@@ -266,6 +375,48 @@ func (s *Solver) chooseLit() Lit {
 	return v.SignedLit(!s.polarity[v])
 }
 
+// FreezeVar excludes v from the candidates of the decision heuristic, so that it is only bound by propagation,
+// e.g for auxiliary vars of an encoding, whose value follows from the other vars.
+// If all the vars that are not frozen are bound but some frozen ones are not, decisions are made on the latter anyway,
+// so that models are complete and the search stays correct.
+// Vars that do not exist are ignored.
+func (s *Solver) FreezeVar(v Var) {
+	if int(v) < 0 || int(v) >= s.nbVars {
+		return
+	}
+	for len(s.frozen) <= int(v) {
+		s.frozen = append(s.frozen, false)
+	}
+	s.frozen[v] = true
+}
+
+// UnfreezeVar makes v a candidate of the decision heuristic again, after it was excluded by FreezeVar.
+// Vars that do not exist, or that are not frozen, are ignored.
+func (s *Solver) UnfreezeVar(v Var) {
+	if !s.isFrozen(v) {
+		return
+	}
+	s.frozen[v] = false
+	if s.model[v] == 0 && !s.varQueue.contains(int(v)) {
+		s.varQueue.insert(int(v))
+	}
+}
+
+// isFrozen returns true iff v was excluded from decisions by FreezeVar.
+func (s *Solver) isFrozen(v Var) bool {
+	return int(v) >= 0 && int(v) < len(s.frozen) && s.frozen[v]
+}
+
+// unboundFrozen returns a frozen var that is not bound yet, or -1 if there is none.
+func (s *Solver) unboundFrozen() Var {
+	for v, frozen := range s.frozen {
+		if frozen && s.model[v] == 0 {
+			return Var(v)
+		}
+	}
+	return -1
+}
+
 type number interface {
 	int | int32 | decLevel
 }
@@ -297,10 +448,7 @@ func (s *Solver) cleanupBindings(lvl decLevel) {
 				lit2 := s.trail[j]
 				v := lit2.Var()
 				s.model[v] = 0
-				if s.reason[v] != nil {
-					s.reason[v].unlock()
-					s.reason[v] = nil
-				}
+				s.reason[v] = nil
 				s.polarity[v] = lit2.IsPositive()
 				if !s.varQueue.contains(int(v)) {
 					s.varQueue.insert(int(v))
@@ -313,10 +461,7 @@ func (s *Solver) cleanupBindings(lvl decLevel) {
 		lit2 := s.trail[j]
 		v := lit2.Var()
 		s.model[v] = 0
-		if s.reason[v] != nil {
-			s.reason[v].unlock()
-			s.reason[v] = nil
-		}
+		s.reason[v] = nil
 		s.polarity[v] = lit2.IsPositive()
 		if !s.varQueue.contains(int(v)) {
 			toInsert = append(toInsert, int(v))
@@ -335,10 +480,7 @@ func (s *Solver) cleanupBindings(lvl decLevel) {
 			break
 		}
 		s.model[v] = 0
-		if s.reason[v] != nil {
-			s.reason[v].unlock()
-			s.reason[v] = nil
-		}
+		s.reason[v] = nil
 		s.polarity[v] = lit.IsPositive()
 		if !s.varQueue.contains(int(v)) {
 			s.varQueue.insert(int(v))
@@ -386,9 +528,16 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 	for lit != -1 {
 		// log.Printf("picked %d at lvl %d", lit.Int(), lvl)
 		if conflict := s.unifyLiteral(lit, lvl); conflict == nil { // Pick new branch or restart
+			if s.budgetExhausted() {
+				s.cleanupBindings(1)
+				return Indet
+			}
 			if s.lbdStats.mustRestart() {
 				s.lbdStats.clear()
 				s.cleanupBindings(1)
+				if s.vivification && len(s.assumed) == 0 && len(s.guards) == 0 && !s.vivify() {
+					return Unsat
+				}
 				return Indet
 			}
 			if s.Stats.NbConflicts >= s.wl.idxReduce*s.wl.nbMax {
@@ -403,18 +552,28 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 			if s.Stats.NbConflicts%5_000 == 0 && s.varDecay < 0.95 {
 				s.varDecay += 0.01
 			}
+			if s.statsHook != nil {
+				s.reportStats()
+			}
 			s.lbdStats.addConflict(len(s.trail))
 			learnt, unit := s.learnClause(conflict, lvl)
 			if learnt == nil { // Unit clause was learned: this lit is known for sure
 				if unit == -1 || (abs(s.model[unit.Var()]) == 1 && s.litStatus(unit) == Unsat) { // Top-level conflict
+					s.explainUnsat(conflict, unit)
 					return s.setUnsat()
 				}
 				s.Stats.NbUnitLearned++
 				s.lbdStats.addLbd(1)
+				s.Stats.addLBD(1, 1)
+				if s.onLearn != nil {
+					s.onLearn([]Lit{unit}, 1)
+				}
+				s.Stats.addBackjump(lvl - 1)
 				s.cleanupBindings(1)
 				s.addLearnedUnit(unit)
 				s.model[unit.Var()] = lvlToSignedLvl(unit, 1)
 				if conflict = s.unifyLiteral(unit, 1); conflict != nil { // top-level conflict
+					s.explainUnsat(conflict, -1)
 					return s.setUnsat()
 				}
 				s.rebuildOrderHeap()
@@ -425,12 +584,18 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 					s.Stats.NbBinaryLearned++
 				}
 				s.Stats.NbLearned++
-				s.lbdStats.addLbd(learnt.lbd())
+				lbd := learnt.lbd()
+				s.lbdStats.addLbd(lbd)
+				s.Stats.addLBD(lbd, learnt.Len())
+				if s.onLearn != nil {
+					s.onLearn(learnt.lits, lbd)
+				}
 				s.addLearned(learnt)
+				confLvl := lvl
 				lvl, lit = backtrackData(learnt, s.model)
+				s.Stats.addBackjump(confLvl - lvl)
 				s.cleanupBindings(lvl)
 				s.reason[lit.Var()] = learnt
-				learnt.lock()
 			}
 		}
 	}
@@ -442,6 +607,10 @@ func (s *Solver) propagateAndSearchPB(lit Lit, lvl decLevel) Status {
 	for lit != -1 {
 		// log.Printf("picked %d at lvl %d", lit.Int(), lvl)
 		if conflict := s.unifyLiteral(lit, lvl); conflict == nil { // Pick new branch or restart
+			if s.budgetExhausted() {
+				s.cleanupBindings(1)
+				return Indet
+			}
 			if s.Stats.NbConflicts >= s.lubyNextRestart {
 				s.lubyNextRestart += int(lubyConstant * luby(uint(s.Stats.NbRestarts)+2))
 				s.cleanupBindings(1)
@@ -461,15 +630,20 @@ func (s *Solver) propagateAndSearchPB(lit Lit, lvl decLevel) Status {
 				if s.Stats.NbConflicts%5_000 == 0 && s.varDecay < 0.95 {
 					s.varDecay += 0.01
 				}
+				if s.statsHook != nil {
+					s.reportStats()
+				}
 				s.lbdStats.addConflict(len(s.trail))
 				learnt, propagated, newLvl := s.cuttingPlanes(conflict, lvl)
 				// log.Printf("learnt=%v, propagated=%v, newLvl=%d", learnt, propagated, newLvl)
 				if newLvl == -1 { // Generated constraint is false
+					s.explainUnsat(conflict, -1)
 					return s.setUnsat()
 				}
 				if newLvl == 1 {
 					for _, unit := range propagated {
 						if unit == -1 || (abs(s.model[unit.Var()]) == 1 && s.litStatus(unit) == Unsat) { // Top-level conflict
+							s.explainUnsat(conflict, unit)
 							return s.setUnsat()
 						}
 						s.Stats.NbUnitLearned++
@@ -478,13 +652,17 @@ func (s *Solver) propagateAndSearchPB(lit Lit, lvl decLevel) Status {
 						s.addLearnedUnit(unit)
 						s.model[unit.Var()] = lvlToSignedLvl(unit, 1)
 						if conflict = s.unifyLiteral(unit, 1); conflict != nil { // top-level conflict
+							s.explainUnsat(conflict, -1)
 							return s.setUnsat()
 						}
 					}
+					s.Stats.addBackjump(lvl - 1)
 					s.rebuildOrderHeap()
 					lit = s.chooseLit()
 					lvl = 2
 				} else {
+					s.Stats.NbLearned++
+					s.Stats.addBackjump(lvl - newLvl)
 					lvl = newLvl
 					// A constraint was learned and lits have to be propagated at lvl > 1
 					// if learnt != nil {
@@ -495,9 +673,7 @@ func (s *Solver) propagateAndSearchPB(lit Lit, lvl decLevel) Status {
 					// for _, lit := range propagated {
 					// 	log.Printf("%d ", lit.Int())
 					// }
-					s.Stats.NbLearned++
 					s.addLearned(learnt)
-					learnt.lock()
 					lvl = newLvl
 					s.cleanupBindings(lvl)
 					for _, lit := range propagated {
@@ -528,6 +704,17 @@ func (s *Solver) setUnsat() Status {
 	return Unsat
 }
 
+// SolveDuration returns the total time spent searching for models, i.e in Solve, Minimize, Optimal,
+// Enumerate, ForEachModel and CountModels, since the solver was created.
+func (s *Solver) SolveDuration() time.Duration {
+	return s.solveDuration
+}
+
+// addSolveDuration adds the time elapsed since start to the time spent searching.
+func (s *Solver) addSolveDuration(start time.Time) {
+	s.solveDuration += time.Since(start)
+}
+
 // Searches until a restart is needed.
 func (s *Solver) search() Status {
 	s.localNbRestarts++
@@ -537,12 +724,71 @@ func (s *Solver) search() Status {
 	return s.status
 }
 
+// SetConflictBudget limits the number of conflicts later calls to Solve can meet, starting from now:
+// once n conflicts were met, Solve stops and returns Indet.
+// Nothing learned so far is lost, so calling Solve again resumes the search, as long as the budget was raised.
+// A budget of 0 or less removes the limit.
+func (s *Solver) SetConflictBudget(n int) {
+	if n <= 0 {
+		s.conflictLimit = 0
+	} else {
+		s.conflictLimit = s.Stats.NbConflicts + n
+	}
+}
+
+// SetStatsHook makes the solver call fn with a copy of its statistics roughly every interval while it is searching.
+// fn is called from the goroutine running the search, which is paused until fn returns, so it should return quickly,
+// but it can safely use the statistics it is given.
+// Calling SetStatsHook with a nil fn removes the hook.
+func (s *Solver) SetStatsHook(interval time.Duration, fn func(Stats)) {
+	s.statsHook = fn
+	s.statsInterval = interval
+	s.lastStats = time.Now()
+}
+
+// OnLearn makes the solver call fn with the lits and the LBD of each clause it learns during conflict analysis,
+// including unit clauses, whose LBD is 1, e.g to share them with other solvers through AddLemma.
+// fn is called synchronously from the goroutine running the search, which is paused until fn returns,
+// so it should return quickly. The lits must not be modified, and must be copied if they are kept after fn returns.
+// Constraints learned by cutting planes, when solving PB problems with that strategy, are not reported.
+// Calling OnLearn with a nil fn removes the callback.
+func (s *Solver) OnLearn(fn func(lits []Lit, lbd int)) {
+	s.onLearn = fn
+}
+
+// reportStats calls the stats hook if it was not called for long enough.
+// To keep the overhead low, time is only checked every few conflicts.
+func (s *Solver) reportStats() {
+	if s.Stats.NbConflicts%64 != 0 {
+		return
+	}
+	if now := time.Now(); now.Sub(s.lastStats) >= s.statsInterval {
+		s.lastStats = now
+		s.statsHook(s.Stats)
+	}
+}
+
+// budgetExhausted returns true iff the budget set by SetConflictBudget, if any, was exhausted,
+// the limit set by SetMemoryLimit, if any, was exceeded, or Stop was called.
+func (s *Solver) budgetExhausted() bool {
+	return (s.conflictLimit != 0 && s.Stats.NbConflicts >= s.conflictLimit) || s.memoryExceeded() || s.stopRequested()
+}
+
 // Solve solves the problem associated with the solver and returns the appropriate status.
+// If a budget was set with SetConflictBudget and exhausted, a limit was set with SetMemoryLimit and exceeded,
+// or Stop was called, it returns Indet; in the latter cases, Err returns ErrMemoryLimit or ErrStopped.
 func (s *Solver) Solve() Status {
+	defer s.addSolveDuration(time.Now())
+	s.memExceeded = false
+	s.stopped = false
+	s.memChecked = s.Stats.NbConflicts - memCheckInterval // Check the memory usage as soon as possible
 	if s.status == Unsat {
 		return s.status
 	}
 	s.status = Indet
+	if s.stopRequested() { // Stop was called before the search started, or during a previous one that did not notice it
+		return s.status
+	}
 	//s.lbdStats.clear()
 	s.localNbRestarts = 0
 	var end chan struct{}
@@ -579,6 +825,9 @@ func (s *Solver) Solve() Status {
 		if s.status == Indet {
 			s.Stats.NbRestarts++
 			s.rebuildOrderHeap()
+			if s.budgetExhausted() {
+				break
+			}
 		}
 	}
 	if s.status == Sat {
@@ -594,30 +843,228 @@ func (s *Solver) Solve() Status {
 
 // Assume adds unit literals to the solver.
 // This is useful when calling the solver several times, e.g to keep it "hot" while removing clauses.
+// Literals assumed by a previous call are discarded, but top-level units (either from the problem
+// or learned during a previous search) are kept, so the solver can be reused safely.
+// Calling Assume(nil) thus removes all assumptions.
+// Activation literals of retractable constraints, such as the cost bound, are always assumed.
 func (s *Solver) Assume(lits []Lit) Status {
+	s.assume(append(s.guards[:len(s.guards):len(s.guards)], lits...))
+	s.assumed = lits
+	return s.status
+}
+
+// assume assumes the given literals, and only them.
+func (s *Solver) assume(lits []Lit) Status {
+	if s.model == nil || s.unsat { // Problem was trivially UNSAT, or became so after a clause was appended
+		s.status = Unsat
+		return s.status
+	}
+	s.explanation = ""
+	units := s.topLevelUnits()
 	s.cleanupBindings(0)
 	s.trail = s.trail[:0]
 	s.assumptions = make([]bool, s.nbVars)
-
+	for _, unit := range units {
+		s.model[unit.Var()] = lvlToSignedLvl(unit, 1)
+		s.trail = append(s.trail, unit)
+	}
+	s.status = Indet
 	for _, lit := range lits {
-
-		s.addLearnedUnit(lit)
+		switch s.litStatus(lit) {
+		case Sat: // Already a unit, or assumed twice
+			continue
+		case Unsat: // Assumption contradicts a unit or another assumption
+			s.explanation = fmt.Sprintf("%s is assumed, but its negation is a unit or is assumed too → contradiction", litName(lit))
+			s.status = Unsat
+			return s.status
+		}
+		s.model[lit.Var()] = lvlToSignedLvl(lit, 1)
 		s.assumptions[lit.Var()] = true
 		s.trail = append(s.trail, lit)
 	}
-	s.status = Indet
 	if confl := s.propagate(0, 1); confl != nil {
 		// Conflict after unit propagation
+		s.explainUnsat(confl, -1)
 		s.status = Unsat
 		return s.status
 	}
 	return s.status
 }
 
+// topLevelUnits returns the literals bound at the top level that are neither assumptions
+// nor propagated, i.e units from the problem and learned units.
+// Those literals stay true no matter what is assumed later.
+func (s *Solver) topLevelUnits() []Lit {
+	var units []Lit
+	for _, lit := range s.trail {
+		v := lit.Var()
+		if abs(s.model[v]) != 1 { // Top-level bindings are all at the beginning of the trail
+			break
+		}
+		if s.reason[v] == nil && !s.assumptions[v] {
+			units = append(units, lit)
+		}
+	}
+	return units
+}
+
+// newActivation creates a new variable and returns it as a positive literal.
+// It is typically used as an activation literal, i.e a literal added to a constraint so that
+// the constraint is only enforced when the literal is assumed.
+func (s *Solver) newActivation() Lit {
+	v := Var(s.nbVars)
+	s.newVar(v)
+	return v.Lit()
+}
+
+// appendGuarded appends the given constraint, which is supposed to contain the negation of an activation literal
+// with a weight equal to its cardinality, so that it is only enforced when the activation literal is assumed.
+// Current assumptions are kept.
+func (s *Solver) appendGuarded(c *Clause) {
+	assumed := s.assumed
+	s.assume(nil) // Don't simplify the new constraint with assumptions
+	s.simplifyAndAppend(c)
+	s.Assume(assumed)
+}
+
+// retract permanently disables the constraints guarded by the given activation literal.
+// Learned clauses depending on these constraints will then be trivially satisfied.
+// The assumptions are then reset to lits.
+func (s *Solver) retract(act Lit, lits []Lit) {
+	for i, guard := range s.guards {
+		if guard == act {
+			s.guards = append(s.guards[:i], s.guards[i+1:]...)
+			break
+		}
+	}
+	s.assume(nil)
+	s.simplifyAndAppend(NewClause([]Lit{act.Negation()}))
+	s.Assume(lits)
+}
+
+// boundCost makes costConstr state the cost must be at most bound, and returns false if the bound is trivially true,
+// or if the problem was trivially UNSAT, in which case there is nothing to bound.
+// The constraint is created on first use, and then updated in place, so that it is shared by all bounds.
+// When the bound gets looser, learned clauses that depend on the constraint are removed first, since they might not hold anymore.
+// It must be called when no literal is assumed.
+func (s *Solver) boundCost(bound int) bool {
+	if s.model == nil { // Problem was trivially UNSAT
+		return false
+	}
+	maxCost := 0
+	for i := range s.minLits {
+		if s.minWeights == nil {
+			maxCost++
+		} else {
+			maxCost += s.minWeights[i]
+		}
+	}
+	card := maxCost - bound
+	if card <= 0 {
+		return false
+	}
+	if card > maxCost+1 { // Cannot be satisfied anyway
+		card = maxCost + 1
+	}
+	if s.costConstr != nil && s.litStatus(s.costGuard) != Indet { // Guard was found to be false at the top level
+		s.dropCostConstr()
+	}
+	if s.costConstr == nil {
+		// The guard weighs more than any possible cardinality, so that only the cardinality has to be updated later on
+		lits := make([]Lit, 0, len(s.minLits)+1)
+		weights := make([]int, 0, len(s.minLits)+1)
+		for i, lit := range s.minLits {
+			w := 1
+			if s.minWeights != nil {
+				w = s.minWeights[i]
+			}
+			lits = append(lits, lit.Negation())
+			weights = append(weights, w)
+		}
+		s.costGuard = s.newActivation()
+		lits = append(lits, s.costGuard.Negation())
+		weights = append(weights, maxCost+1)
+		s.costConstr = NewPBClause(lits, weights, card)
+		s.appendClause(s.costConstr)
+		return true
+	}
+	prev := s.costConstr.Cardinality()
+	if card < prev {
+		s.removeLearnedWith(s.costGuard.Negation())
+	}
+	s.costConstr.updateCardinality(card - prev)
+	s.updateWatchPB(s.costConstr)
+	return true
+}
+
+// dropCostConstr removes costConstr from the problem, along with the learned clauses that depend on it,
+// e.g because the cost function changed. The constraint will be created again by the next call to boundCost.
+// It must be called when no literal is assumed.
+func (s *Solver) dropCostConstr() {
+	if s.costConstr == nil {
+		return
+	}
+	s.setCostGuard(false)
+	s.removeLearnedWith(s.costGuard.Negation())
+	s.unwatchPB(s.costConstr)
+	s.wl.origClauses = removeFrom(s.wl.origClauses, s.costConstr)
+	s.costConstr = nil
+}
+
+// setCostGuard makes sure the guard of costConstr is among the guards iff enabled is true.
+func (s *Solver) setCostGuard(enabled bool) {
+	if s.costConstr == nil {
+		return
+	}
+	for i, guard := range s.guards {
+		if guard == s.costGuard {
+			s.guards = append(s.guards[:i], s.guards[i+1:]...)
+			break
+		}
+	}
+	if enabled {
+		s.guards = append(s.guards, s.costGuard)
+	}
+}
+
+// SetCostBound constrains the models found by the solver to have a cost of at most bound.
+// The bound is enforced through a single pseudo-boolean constraint guarded by an activation literal,
+// so that it can be changed or removed cheaply between two calls to Solve or Minimize, while
+// keeping learned clauses, as long as the bound gets tighter.
+// A new call replaces the previous bound.
+// If the problem is not an optimization problem, the cost of any model is 0.
+func (s *Solver) SetCostBound(bound int) {
+	assumed := s.assumed
+	s.assume(nil)
+	s.hasCostBound = s.boundCost(bound)
+	s.costBound = bound
+	s.setCostGuard(s.hasCostBound)
+	s.Assume(assumed)
+}
+
+// ClearCostBound removes the bound set by SetCostBound, if any.
+func (s *Solver) ClearCostBound() {
+	if s.hasCostBound {
+		s.hasCostBound = false
+		s.setCostGuard(false)
+		s.Assume(s.assumed)
+	}
+}
+
+// SetCostLowerBound tells Minimize that no model has a cost lower than bound,
+// so that it can stop as soon as it finds a model whose cost is bound, since it is optimal,
+// instead of proving there is no better model.
+// It is the caller's responsibility to ensure the bound is valid: if it is not, Minimize can return a suboptimal model.
+// A bound of 0, the default, means no bound is known.
+func (s *Solver) SetCostLowerBound(bound int) {
+	s.costLowerBound = bound
+}
+
 // Enumerate returns the total number of models for the given problems.
 // if "models" is non-nil, it will write models on it as soon as it discovers them.
 // models will be closed at the end of the method.
 func (s *Solver) Enumerate(models chan []bool, stop chan struct{}) int {
+	defer s.addSolveDuration(time.Now())
 	if models != nil {
 		defer close(models)
 	}
@@ -661,8 +1108,116 @@ func (s *Solver) Enumerate(models chan []bool, stop chan struct{}) int {
 	return nb
 }
 
+// ForEachModel calls fn on each model of the problem, as soon as it is discovered, and returns the number of models visited.
+// If fn returns false, the enumeration stops early.
+// The model given to fn is reused between calls: it must be copied if it has to be kept after fn returns.
+func (s *Solver) ForEachModel(fn func(model []bool) bool) int {
+	defer s.addSolveDuration(time.Now())
+	s.lastModel = make(Model, len(s.model))
+	buf := make([]bool, s.nbVars)
+	nb := 0
+	var lit Lit
+	var lvl decLevel
+	for s.status != Unsat {
+		for s.status == Indet {
+			s.search()
+			if s.status == Indet {
+				s.Stats.NbRestarts++
+			}
+		}
+		if s.status == Sat {
+			copy(s.lastModel, s.model)
+			n, ok := s.visitCurrentModels(buf, fn)
+			nb += n
+			if !ok {
+				return nb
+			}
+			s.status = Indet
+			lits := s.decisionLits()
+			switch len(lits) {
+			case 0:
+				s.status = Unsat
+			case 1:
+				s.propagateUnits(lits)
+			default:
+				c := NewClause(lits)
+				s.appendClause(c)
+				lit = lits[len(lits)-1]
+				v := lit.Var()
+				lvl = abs(s.model[v]) - 1
+				s.cleanupBindings(lvl)
+				s.reason[v] = c // Must do it here because it won't be made by propagateAndSearch
+				s.propagateAndSearch(lit, lvl)
+			}
+		}
+	}
+	return nb
+}
+
+// EnumerateCanonical is like Enumerate, but models that are symmetric to each other are only emitted once.
+// Each element of symmetryGroups is a set of interchangeable vars: permuting the bindings of the vars of a group
+// in a model must always yield another model. Groups are expected to be disjoint.
+// Exactly one model per orbit is emitted on models, if it is non-nil, as soon as it is discovered:
+// the one where, in each group, true vars appear before false ones, in the order of the group.
+// The number of emitted models is returned, and models is closed at the end of the method.
+// The symmetry-breaking clauses and the clauses blocking the emitted models are permanently added to the problem.
+func (s *Solver) EnumerateCanonical(symmetryGroups [][]Var, models chan<- []bool) int {
+	if models != nil {
+		defer close(models)
+	}
+	for _, group := range symmetryGroups {
+		for i := 1; i < len(group); i++ { // group[i-1] is true if group[i] is
+			s.AppendClause(NewClause([]Lit{group[i-1].Lit(), group[i].Lit().Negation()}))
+		}
+	}
+	nb := 0
+	for s.Solve() == Sat {
+		model := s.Model()
+		nb++
+		if models != nil {
+			models <- model
+		}
+		blocking := make([]Lit, len(model))
+		for i, val := range model {
+			blocking[i] = Var(i).SignedLit(val)
+		}
+		s.AppendClause(NewClause(blocking))
+	}
+	return nb
+}
+
+// EnumerateProjected enumerates the distinct projections of the models of the problem on the given vars,
+// and returns their number.
+// If out is non-nil, each projection is written on it as soon as it is discovered, as a slice
+// whose ith value is the binding of vars[i]; out is closed at the end of the method.
+// If limit is strictly positive, the enumeration stops once limit projections were found,
+// so the returned value is limit iff the enumeration might be incomplete.
+// The clauses blocking the emitted projections are permanently added to the problem.
+func (s *Solver) EnumerateProjected(vars []Var, limit int, out chan<- []bool) int {
+	if out != nil {
+		defer close(out)
+	}
+	nb := 0
+	for (limit <= 0 || nb < limit) && s.Solve() == Sat {
+		model := s.Model()
+		proj := make([]bool, len(vars))
+		blocking := make([]Lit, len(vars))
+		for i, v := range vars {
+			proj[i] = model[v]
+			blocking[i] = v.SignedLit(model[v])
+		}
+		nb++
+		if out != nil {
+			out <- proj
+		}
+		s.AppendClause(NewClause(blocking))
+	}
+	return nb
+}
+
 // CountModels returns the total number of models for the given problem.
 func (s *Solver) CountModels() int {
+	defer s.addSolveDuration(time.Now())
 	var end chan struct{}
 	if s.Verbose {
 		end = make(chan struct{})
@@ -807,7 +1362,115 @@ func (s *Solver) PBString() string {
 
 // AppendClause appends a new clause to the set of clauses.
 // This is not a learned clause, but a clause that is part of the problem added afterwards (during model counting, for instance).
+// Current assumptions are kept, but they are not used to simplify the clause, so that it stays valid once they are retracted.
 func (s *Solver) AppendClause(clause *Clause) {
+	if len(s.assumed) != 0 || len(s.guards) != 0 {
+		assumed := s.assumed
+		s.assume(nil)
+		defer s.Assume(assumed)
+	}
+	s.simplifyAndAppend(clause)
+}
+
+// AddClause adds the clause made of the given lits, in the DIMACS format, to the problem.
+// It is a convenience over AppendClause: duplicate lits are removed, tautologies are ignored, and vars that
+// were not part of the problem yet are created.
+// Clauses learned so far are kept, since they are still implied by the problem. The model found by
+// the last call to Solve, if any, is discarded, since it might not satisfy the new clause.
+// If the clause is falsified at the top level, the problem becomes UNSAT.
+// An error is returned if the clause contains a null literal.
+func (s *Solver) AddClause(lits []int) error {
+	for _, val := range lits {
+		if val == 0 {
+			return fmt.Errorf("null literal in clause %v", lits)
+		}
+	}
+	clause := make([]Lit, 0, len(lits))
+	for _, val := range lits {
+		lit := IntToLit(int32(val))
+		dup := false
+		for _, lit2 := range clause {
+			if lit2 == lit.Negation() { // Tautology
+				return nil
+			}
+			dup = dup || lit2 == lit
+		}
+		if !dup {
+			clause = append(clause, lit)
+		}
+	}
+	s.lastModel = nil
+	if s.status == Sat {
+		s.status = Indet
+	}
+	s.AppendClause(NewClause(clause))
+	return nil
+}
+
+// AddLemma adds the clause made of the given lits, in the DIMACS format, as if it had been learned by the solver.
+// Unlike clauses added with AppendClause, the lemma can be deleted later on by the solver if it does not prove useful.
+// It is the caller's responsibility to ensure the lemma is implied by the problem:
+// adding a lemma that is not implied changes the problem, and thus the models found by the solver.
+// Current assumptions are kept, but they are not used to simplify the lemma.
+// An error is returned if the lemma contains a null literal.
+func (s *Solver) AddLemma(lits []int) error {
+	for _, val := range lits {
+		if val == 0 {
+			return fmt.Errorf("null literal in lemma %v", lits)
+		}
+	}
+	if s.model == nil { // Problem was trivially UNSAT, no need to add anything
+		return nil
+	}
+	if len(s.assumed) != 0 || len(s.guards) != 0 {
+		assumed := s.assumed
+		s.assume(nil)
+		defer s.Assume(assumed)
+	}
+	s.cleanupBindings(1)
+	clause := make([]Lit, 0, len(lits))
+	for _, val := range lits {
+		lit := IntToLit(int32(val))
+		s.newVar(lit.Var())
+		switch s.litStatus(lit) {
+		case Sat: // Lemma is already satisfied at the top level
+			return nil
+		case Indet:
+			dup := false
+			for _, lit2 := range clause {
+				if lit2 == lit.Negation() { // Tautology
+					return nil
+				}
+				dup = dup || lit2 == lit
+			}
+			if !dup {
+				clause = append(clause, lit)
+			}
+		}
+	}
+	switch len(clause) {
+	case 0:
+		s.status = Unsat
+		s.unsat = true
+	case 1:
+		s.propagateUnits(clause)
+		s.unsat = s.status == Unsat
+	default:
+		c := NewLearnedClause(clause)
+		c.setLbd(len(clause))
+		s.wl.learned = append(s.wl.learned, c)
+		s.watchClause(c)
+		s.clauseBumpActivity(c)
+	}
+	return nil
+}
+
+// simplifyAndAppend simplifies the given clause with the top-level bindings and appends it to the set of clauses.
+// Top-level bindings must not contain assumptions.
+func (s *Solver) simplifyAndAppend(clause *Clause) {
+	if s.model == nil { // Problem was trivially UNSAT, no need to add anything
+		return
+	}
 	s.cleanupBindings(1)
 	card := clause.Cardinality()
 	minW := 0
@@ -835,15 +1498,75 @@ func (s *Solver) AppendClause(clause *Clause) {
 	}
 	if maxW < card { // clause cannot be satisfied
 		s.status = Unsat
+		s.unsat = true
 		return
 	}
 	if maxW == card { // Unit
 		s.propagateUnits(clause.lits)
+		s.unsat = s.status == Unsat
 	} else {
 		s.appendClause(clause)
 	}
 }
 
+// NbVars returns the number of variables in the solver, including the ones it created for its own purposes,
+// such as activation literals.
+func (s *Solver) NbVars() int {
+	return s.nbVars
+}
+
+// EnsureNbVars makes sure the solver has at least n vars, creating new ones if needed, e.g for vars of the problem
+// that do not appear in any of its clauses, so that later vars created by the solver for its own purposes,
+// such as activation literals, do not reuse their ids.
+func (s *Solver) EnsureNbVars(n int) {
+	if s.model == nil { // Problem was trivially UNSAT: no var is needed
+		return
+	}
+	if n > s.nbVars {
+		s.newVar(IntToVar(int32(n)))
+	}
+}
+
+// TopActivityVars returns the n vars with the highest activity, by decreasing activity, i.e the vars that were
+// the most involved in recent conflicts, and are thus the first ones the solver will branch on.
+// Vars with the same activity are sorted by increasing id. If n is greater than the number of vars, all vars are returned.
+// It must not be called while the solver is searching, e.g from another goroutine.
+func (s *Solver) TopActivityVars(n int) []Var {
+	vars := make([]Var, s.nbVars)
+	for i := range vars {
+		vars[i] = Var(i)
+	}
+	sort.SliceStable(vars, func(i, j int) bool { return s.activity[vars[i]] > s.activity[vars[j]] })
+	if n < len(vars) {
+		vars = vars[:n]
+	}
+	return vars
+}
+
+// ReasonFor returns the lits of the clause or PB constraint that forced the current binding of v through propagation,
+// and true, or nil and false if v is unbound, or if it was bound by a decision, by an assumption or as a unit.
+// Current bindings are those of the last search, so ReasonFor is mostly useful right after Solve returned Sat,
+// or after Assume, to know how the assumptions were propagated.
+// It must not be called while the solver is searching, e.g from another goroutine.
+func (s *Solver) ReasonFor(v Var) ([]Lit, bool) {
+	if int(v) >= s.nbVars || s.model[v] == 0 || s.reason[v] == nil {
+		return nil, false
+	}
+	r := s.reason[v]
+	lits := make([]Lit, r.Len())
+	for i := range lits {
+		lits[i] = r.Get(i)
+	}
+	return lits, true
+}
+
+// NbClauses returns the number of clauses and PB constraints of the problem, including the ones added after
+// the solver was created, but not including learned clauses, nor unit clauses, which are directly
+// turned into bindings.
+func (s *Solver) NbClauses() int {
+	return len(s.wl.origClauses)
+}
+
 // Model returns a slice that associates, to each variable, its binding.
 // If s's status is not Sat, the method will panic.
 func (s *Solver) Model() []bool {
@@ -863,9 +1586,21 @@ func (s *Solver) Model() []bool {
 // For instance, if there are 4 variables in the problem and only 1, 3 and 4 are bound,
 // there are actually 2 models currently: one with 2 set to true, the other with 2 set to false.
 func (s *Solver) addCurrentModels(ch chan []bool) int {
+	nb, _ := s.visitCurrentModels(make([]bool, s.nbVars), func(model []bool) bool {
+		model2 := make([]bool, len(model))
+		copy(model2, model)
+		ch <- model2
+		return true
+	})
+	return nb
+}
+
+// visitCurrentModels is called when a model was found.
+// It calls fn on all the models from this point, using model as a buffer, until fn returns false.
+// It returns the number of models visited, and false if fn stopped the enumeration.
+func (s *Solver) visitCurrentModels(model []bool, fn func(model []bool) bool) (int, bool) {
 	unbound := make([]int, 0, s.nbVars) // indices of unbound variables
 	var nb uint64 = 1                   // total number of models found
-	model := make([]bool, s.nbVars)     // partial model
 	for i, lvl := range s.lastModel {
 		if lvl == 0 {
 			unbound = append(unbound, i)
@@ -881,11 +1616,11 @@ func (s *Solver) addCurrentModels(ch chan []bool) int {
 			idx := unbound[j]
 			model[idx] = cur != 0
 		}
-		model2 := make([]bool, len(model))
-		copy(model2, model)
-		ch <- model2
+		if !fn(model) {
+			return int(i + 1), false
+		}
 	}
-	return int(nb)
+	return int(nb), true
 }
 
 // countCurrentModels is called when a model was found.
@@ -990,30 +1725,39 @@ func (s *Solver) Optimal(results chan Result, stop chan struct{}) (res Result) {
 // Otherwise, calling s.Model() afterwards will return the model that satisfy the formula, such that no other model with a smaller cost exists.
 // If this function is called on a non-optimization problem, it will either return -1, or a cost of 0 associated with a
 // satisfying model (ie any model is an optimal model).
+// The constraints added on the cost while minimizing are retracted before the function returns,
+// so the solver can be used again afterwards, e.g after new constraints were added.
+// If the search is stopped by SetConflictBudget or SetMemoryLimit, the best model found so far is kept
+// and its cost is returned, or -1 if no model was found yet.
 func (s *Solver) Minimize() int {
 	status := s.Solve()
-	if status == Unsat { // Problem cannot be satisfied at all
+	if status == Unsat || status == Indet { // Problem cannot be satisfied at all, or no model was found before the search was stopped
 		return -1
 	}
 	if s.minLits == nil { // No optimization clause: this is a decision problem, solution is optimal
 		return 0
 	}
-	maxCost := 0
-	if s.minWeights == nil {
-		maxCost = len(s.minLits)
-	} else {
-		for _, w := range s.minWeights {
-			maxCost += w
+	weights := make([]int, len(s.minLits))
+	for i := range s.minLits {
+		weights[i] = 1
+		if s.minWeights != nil {
+			weights[i] = s.minWeights[i]
 		}
 	}
 	s.hypothesis = make([]Lit, len(s.minLits))
 	for i, lit := range s.minLits {
 		s.hypothesis[i] = lit.Negation()
 	}
-	weights := make([]int, len(s.minWeights))
-	copy(weights, s.minWeights)
 	sort.Sort(wLits{lits: s.hypothesis, weights: weights})
 	s.lastModel = make(Model, len(s.model))
+	assumed := s.assumed
+	// The bound set by SetCostBound, if any, is restored once done
+	defer func(hasBound bool, bound int) {
+		s.assume(nil)
+		s.hasCostBound = hasBound && s.boundCost(bound)
+		s.setCostGuard(s.hasCostBound)
+		s.Assume(assumed)
+	}(s.hasCostBound, s.costBound)
 	var cost int
 	for status == Sat {
 		copy(s.lastModel, s.model) // Save this model: it might be the last one
@@ -1033,18 +1777,54 @@ func (s *Solver) Minimize() int {
 		if s.Verbose {
 			fmt.Printf("o %d\n", cost)
 		}
-		// Add a constraint incrementing current best cost
-		lits2 := make([]Lit, len(s.minLits))
-		weights2 := make([]int, len(s.minWeights))
-		copy(lits2, s.hypothesis)
-		copy(weights2, weights)
-		s.AppendClause(NewPBClause(lits2, weights2, maxCost-cost+1))
+		if cost <= s.costLowerBound { // Known to be optimal
+			return cost
+		}
+		// Tighten the bound on the cost, so that the next model is better
+		s.assume(nil)
+		s.boundCost(cost - 1)
+		s.setCostGuard(true)
+		s.Assume(assumed)
 		s.rebuildOrderHeap()
 		status = s.Solve()
 	}
 	return cost
 }
 
+// SolveMinWeight returns a model of the problem minimizing the sum of the weights of its true vars,
+// where weights[i] is the weight of the var whose id is i, along with that sum,
+// or nil and -1 if the problem is not satisfiable.
+// If weights is nil, all vars have a weight of 1, so the returned model is a model with the fewest true vars;
+// this includes the vars created by the solver for its own purposes, such as activation literals.
+// Vars beyond len(weights), and vars whose weight is 0, are not taken into account.
+// The cost function of the problem is only replaced for this call, but, as with SetCostFunc,
+// the bounds set by SetCostBound and SetCostLowerBound, if any, are removed.
+func (s *Solver) SolveMinWeight(weights []int) ([]bool, int) {
+	var lits []Lit
+	var ws []int
+	if weights == nil {
+		lits = make([]Lit, s.nbVars)
+		for i := range lits {
+			lits[i] = Var(i).Lit()
+		}
+	} else {
+		for i, w := range weights {
+			if i < s.nbVars && w != 0 {
+				lits = append(lits, Var(i).Lit())
+				ws = append(ws, w)
+			}
+		}
+	}
+	minLits, minWeights := s.minLits, s.minWeights
+	defer s.SetCostFunc(minLits, minWeights)
+	s.SetCostFunc(lits, ws)
+	cost := s.Minimize()
+	if cost == -1 {
+		return nil, -1
+	}
+	return s.Model(), cost
+}
+
 // functions to sort hypothesis for pseudo-boolean minimization clause.
 type wLits struct {
 	lits    []Lit
@@ -1,8 +1,12 @@
 package solver
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -109,6 +113,50 @@ func TestParseSlice(t *testing.T) {
 	}
 }
 
+// failingReader returns some bytes, then an I/O error.
+type failingReader struct{ data string }
+
+var errRead = errors.New("read failure")
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, errRead
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name      string
+		parse     func(io.Reader) (*Problem, error)
+		input     string
+		line, col int
+	}{
+		{"cnf literal", ParseCNF, "p cnf 3 2\n1 2 0\n-1 4 0\n", 3, 5},
+		{"cnf digit", ParseCNF, "c comment\np cnf 3 1\n1 x 0\n", 3, 3},
+		{"cnf header", ParseCNF, "p cnf three 2\n1 2 0\n", 1, 0},
+		{"opb", ParseOPB, "* comment\n+1 x1 +1 x2 >= 1 ;\n+1 x1 >= ;\n", 3, 0},
+	}
+	for _, test := range tests {
+		_, err := test.parse(strings.NewReader(test.input))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%s: expected a ParseError, got %v", test.name, err)
+		} else if perr.Line != test.line || perr.Col != test.col {
+			t.Errorf("%s: expected error at %d:%d, got %d:%d (%v)", test.name, test.line, test.col, perr.Line, perr.Col, err)
+		}
+	}
+	for data, parse := range map[string]func(io.Reader) (*Problem, error){"p cnf 3 1\n1 ": ParseCNF, "* comment\n": ParseOPB} {
+		_, err := parse(&failingReader{data: data})
+		var perr *ParseError
+		if !errors.Is(err, errRead) || errors.As(err, &perr) {
+			t.Errorf("expected a wrapped I/O error, got %v", err)
+		}
+	}
+}
+
 func TestParseSliceSat(t *testing.T) {
 	cnf := [][]int{{1}, {-2, 3}, {-2, 4}, {-5, 3}, {-5, 6}, {-7, 3}, {-7, 8}, {-9, 10}, {-9, 4}, {-1, 10}, {-1, 6}, {3, 10}, {-3, -10}, {4, 6, 8}}
 	pb := ParseSlice(cnf)
@@ -320,6 +368,641 @@ func TestAssume(t *testing.T) {
 	}
 }
 
+func TestAssumeKeepsUnits(t *testing.T) {
+	clauses := [][]int{
+		{1},
+		{-1, 2, 3},
+		{-2, 4},
+	}
+	s := New(ParseSlice(clauses))
+	s.Assume([]Lit{IntToLit(-3)})
+	if s.Solve() != Sat {
+		t.Fatalf("should be sat")
+	}
+	if m := s.Model(); !m[0] || !m[1] || !m[3] {
+		t.Fatalf("invalid model %v", m)
+	}
+	s.Assume([]Lit{IntToLit(-3), IntToLit(-4)})
+	if s.Solve() != Unsat {
+		t.Fatalf("unit 1 should still hold, problem should be unsat")
+	}
+	s.Assume(nil)
+	if s.Solve() != Sat {
+		t.Fatalf("assumptions were removed, should be sat")
+	}
+}
+
+func TestAppendClauseWithAssumptions(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2}}))
+	s.Assume([]Lit{IntToLit(1)})
+	s.AppendClause(NewClause(IntsToLits(-1, 3)))
+	if s.Solve() != Sat {
+		t.Fatalf("should be sat")
+	}
+	if m := s.Model(); !m[0] || !m[2] {
+		t.Fatalf("invalid model %v", m)
+	}
+	s.Assume([]Lit{IntToLit(-3)})
+	if s.Solve() != Sat {
+		t.Fatalf("appended clause should not have been simplified with assumptions, problem should be sat")
+	}
+	s.AppendClause(NewClause(IntsToLits(-2)))
+	if s.Solve() != Unsat {
+		t.Fatalf("should be unsat")
+	}
+	s.Assume(nil)
+	if s.Solve() != Sat {
+		t.Fatalf("should be sat again once assumptions are removed")
+	}
+	s.AppendClause(NewClause(IntsToLits(-3)))
+	s.Assume(nil)
+	if s.Solve() != Unsat {
+		t.Fatalf("appended clauses make the problem unsat, no matter the assumptions")
+	}
+}
+
+func TestSolveMinWeight(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2, 3}, {-1, 4}, {1, 5}}))
+	model, nb := s.SolveMinWeight(nil)
+	if nb != 2 || model == nil {
+		t.Errorf("expected a model with 2 true vars, got %v with cost %d", model, nb)
+	}
+	model, cost := s.SolveMinWeight([]int{5, 1, 1, 1, 1})
+	if cost != 2 || model == nil || model[0] {
+		t.Errorf("expected a model with cost 2 where x1 is false, got %v with cost %d", model, cost)
+	}
+	if s.Optim() {
+		t.Errorf("cost function should have been restored")
+	}
+}
+
+func TestStateRestore(t *testing.T) {
+	parse := func() *Problem {
+		f, err := os.Open("testcnf/125.cnf")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		defer func() { _ = f.Close() }()
+		pb, err := ParseCNF(f)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return pb
+	}
+	s := New(parse())
+	s.SetConflictBudget(200)
+	s.Solve()
+	st := s.State()
+	if len(st.Lemmas) == 0 || len(st.Activity) != s.NbVars() {
+		t.Fatalf("invalid state: %d lemmas, %d activities", len(st.Lemmas), len(st.Activity))
+	}
+	s2 := New(parse())
+	if err := s2.Restore(st); err != nil {
+		t.Fatalf("could not restore state: %v", err)
+	}
+	if len(s2.wl.learned) == 0 {
+		t.Errorf("lemmas should have been restored")
+	}
+	if s2.Solve() != Unsat {
+		t.Errorf("problem should be unsat")
+	}
+	if err := s2.Restore(State{Lemmas: [][]int{{1, 0}}}); err == nil {
+		t.Errorf("expected an error for null literal")
+	}
+}
+
+func TestTopActivityVars(t *testing.T) {
+	f, err := os.Open("testcnf/125.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	s.Solve()
+	top := s.TopActivityVars(10)
+	if len(top) != 10 {
+		t.Fatalf("expected 10 vars, got %d", len(top))
+	}
+	for i := 1; i < len(top); i++ {
+		if s.activity[top[i]] > s.activity[top[i-1]] {
+			t.Errorf("vars are not sorted by decreasing activity: %v", top)
+		}
+	}
+	if len(s.TopActivityVars(s.NbVars()+10)) != s.NbVars() {
+		t.Errorf("expected all vars to be returned")
+	}
+}
+
+func TestParseICNF(t *testing.T) {
+	const icnf = `c incremental problem
+p inccnf
+1 2 0
+-1 2
+0
+a -2 0
+a 1 0
+-1 0
+a 0
+a 1 0
+`
+	pb, err := ParseICNF(strings.NewReader(icnf))
+	if err != nil {
+		t.Fatalf("could not parse ICNF: %v", err)
+	}
+	if pb.NbVars != 2 || pb.NbQueries() != 4 {
+		t.Fatalf("expected 2 vars and 4 queries, got %d and %d", pb.NbVars, pb.NbQueries())
+	}
+	expected := []Status{Unsat, Sat, Sat, Unsat}
+	for i, exp := range expected {
+		status, ok := pb.SolveAssumptions()
+		if !ok || status != exp {
+			t.Errorf("query #%d: expected %v, got %v (%t)", i, exp, status, ok)
+		}
+		if status == Sat && !pb.Solver().Model()[1] {
+			t.Errorf("query #%d: invalid model %v", i, pb.Solver().Model())
+		}
+	}
+	if _, ok := pb.SolveAssumptions(); ok {
+		t.Errorf("expected no more queries")
+	}
+	for _, invalid := range []string{"1 2 0\n", "p cnf 2 1\n1 2 0\n", "p inccnf\na 1 x 0\n", "p inccnf\n1 2\n"} {
+		var perr *ParseError
+		if _, err := ParseICNF(strings.NewReader(invalid)); !errors.As(err, &perr) {
+			t.Errorf("expected a ParseError for %q, got %v", invalid, err)
+		}
+	}
+}
+
+func TestReasonFor(t *testing.T) {
+	s := New(ParseSliceNb([][]int{{-1, 2}, {-2, 3, 4}, {-2, -4}}, 4))
+	s.Assume([]Lit{IntToLit(1)})
+	reason, ok := s.ReasonFor(Var(1))
+	if !ok || len(reason) != 2 {
+		t.Fatalf("expected x2 to be propagated by a binary clause, got %v (%t)", reason, ok)
+	}
+	if reason, ok := s.ReasonFor(Var(0)); ok {
+		t.Errorf("expected assumption to have no reason, got %v", reason)
+	}
+	if reason, ok := s.ReasonFor(Var(2)); !ok || len(reason) != 3 {
+		t.Errorf("expected x3 to be propagated by a ternary clause, got %v (%t)", reason, ok)
+	}
+	if reason, ok := s.ReasonFor(Var(10)); ok {
+		t.Errorf("expected no reason for unknown var, got %v", reason)
+	}
+}
+
+func TestAddClause(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2}, {-1, 3}}))
+	if s.Solve() != Sat {
+		t.Fatalf("should be sat")
+	}
+	if err := s.AddClause([]int{-3, 0}); err == nil {
+		t.Errorf("expected an error for null literal")
+	}
+	if err := s.AddClause([]int{-3, -3}); err != nil {
+		t.Fatalf("could not add clause: %v", err)
+	}
+	if s.Solve() != Sat {
+		t.Fatalf("should still be sat")
+	}
+	if model := s.Model(); model[0] || !model[1] || model[2] {
+		t.Errorf("invalid model %v", model)
+	}
+	if err := s.AddClause([]int{4, -2, -4}); err != nil {
+		t.Fatalf("could not add tautology: %v", err)
+	}
+	if err := s.AddClause([]int{-2, 4}); err != nil {
+		t.Fatalf("could not add clause with new var: %v", err)
+	}
+	if s.Solve() != Sat || s.NbVars() != 4 || !s.Model()[3] {
+		t.Fatalf("new var should be true in model")
+	}
+	if err := s.AddClause([]int{-4}); err != nil {
+		t.Fatalf("could not add clause: %v", err)
+	}
+	if s.Solve() != Unsat {
+		t.Errorf("should be unsat")
+	}
+}
+
+func TestAddLemma(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2}, {-1, 3}, {-2, 3}, {3, 4, 5}}))
+	if err := s.AddLemma([]int{1, 0}); err == nil {
+		t.Errorf("expected an error for null literal")
+	}
+	s.Assume([]Lit{IntToLit(1)})
+	if err := s.AddLemma([]int{3, 2, 3}); err != nil {
+		t.Fatalf("could not add lemma: %v", err)
+	}
+	if len(s.wl.learned) != 1 || !s.wl.learned[0].Learned() || s.wl.learned[0].Len() != 2 {
+		t.Fatalf("lemma should have been added as a learned clause without duplicates, got %v", s.wl.learned)
+	}
+	if err := s.AddLemma([]int{4, -4}); err != nil || len(s.wl.learned) != 1 {
+		t.Errorf("tautology should have been ignored")
+	}
+	if s.Solve() != Sat {
+		t.Fatalf("should be sat")
+	}
+	s.Assume(nil)
+	if err := s.AddLemma([]int{3}); err != nil {
+		t.Fatalf("could not add unit lemma: %v", err)
+	}
+	if s.VarValue(Var(2)) != True {
+		t.Errorf("unit lemma should have been propagated at top level")
+	}
+	if s.Solve() != Sat {
+		t.Fatalf("should still be sat")
+	}
+}
+
+func TestVivification(t *testing.T) {
+	for _, test := range []test{
+		{"testcnf/150.cnf", Unsat},
+		{"testcnf/225.cnf", Sat},
+		{"testcnf/8-pigeons.cnf", Unsat},
+	} {
+		f, err := os.Open(test.path)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		pb, err := ParseCNF(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		s := New(pb)
+		s.SetVivification(true)
+		if status := s.Solve(); status != test.expected {
+			t.Errorf("Invalid result for %q with vivification: expected %v, got %v", test.path, test.expected, status)
+		}
+		if s.Stats.NbVivified == 0 || s.Stats.NbVivifiedLits < s.Stats.NbVivified {
+			t.Errorf("Invalid vivification stats for %q: %d clauses, %d lits", test.path, s.Stats.NbVivified, s.Stats.NbVivifiedLits)
+		}
+	}
+}
+
+func TestMinimizationLevel(t *testing.T) {
+	for _, test := range []test{
+		{"testcnf/150.cnf", Unsat},
+		{"testcnf/8-pigeons.cnf", Unsat},
+	} {
+		var lens []float64
+		for lvl := 0; lvl <= 2; lvl++ {
+			f, err := os.Open(test.path)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			pb, err := ParseCNF(f)
+			_ = f.Close()
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+			s := New(pb)
+			s.SetMinimizationLevel(lvl)
+			if status := s.Solve(); status != test.expected {
+				t.Errorf("Invalid result for %q with minimization level %d: expected %v, got %v", test.path, lvl, test.expected, status)
+			}
+			lens = append(lens, s.Stats.AvgLearnedLen)
+		}
+		if lens[0] <= lens[1] || lens[1] <= lens[2] {
+			t.Errorf("Learned clauses of %q should be shorter with higher minimization levels, got average lengths %v", test.path, lens)
+		}
+	}
+}
+
+func TestOnLearn(t *testing.T) {
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	pb, err := ParseCNF(f)
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	nb, nbUnits := 0, 0
+	s.OnLearn(func(lits []Lit, lbd int) {
+		nb++
+		if len(lits) == 1 {
+			nbUnits++
+		}
+		if len(lits) == 0 || lbd < 1 || lbd > len(lits) {
+			t.Errorf("invalid learned clause %v with LBD %d", lits, lbd)
+		}
+	})
+	if status := s.Solve(); status != Unsat {
+		t.Fatalf("expected unsat, got %v", status)
+	}
+	if nb == 0 || nb != s.Stats.NbLearned+s.Stats.NbUnitLearned || nbUnits != s.Stats.NbUnitLearned {
+		t.Errorf("expected %d learned clauses, including %d units, got %d and %d", s.Stats.NbLearned+s.Stats.NbUnitLearned, s.Stats.NbUnitLearned, nb, nbUnits)
+	}
+	s.OnLearn(nil)
+}
+
+func TestDurations(t *testing.T) {
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if pb.ParseDuration() <= 0 {
+		t.Errorf("parse duration was not recorded")
+	}
+	s := New(pb)
+	if s.SolveDuration() != 0 {
+		t.Errorf("solve duration should be 0 before solving, got %v", s.SolveDuration())
+	}
+	s.Solve()
+	if s.SolveDuration() <= 0 {
+		t.Errorf("solve duration was not recorded")
+	}
+}
+
+func TestLBDHistogram(t *testing.T) {
+	f, err := os.Open("testcnf/125.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	if len(s.LBDHistogram()) != 0 {
+		t.Errorf("expected empty histogram before solving")
+	}
+	s.Solve()
+	nb := 0
+	for lbd, count := range s.LBDHistogram() {
+		if lbd < 1 || count <= 0 {
+			t.Errorf("invalid histogram entry %d: %d", lbd, count)
+		}
+		nb += count
+	}
+	if nb != len(s.wl.learned) {
+		t.Errorf("invalid histogram: %d clauses counted, %d in database", nb, len(s.wl.learned))
+	}
+}
+
+func TestDerivedStats(t *testing.T) {
+	f, err := os.Open("testcnf/125.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	s.Solve()
+	st := s.Stats
+	if st.NbConflicts == 0 {
+		t.Fatalf("expected some conflicts")
+	}
+	if st.AvgBackjump < 1 {
+		t.Errorf("invalid average backjump %f", st.AvgBackjump)
+	}
+	if st.LearnedPerConflict <= 0 || st.LearnedPerConflict > 1 {
+		t.Errorf("invalid number of learned clauses per conflict %f", st.LearnedPerConflict)
+	}
+	if st.AvgLBD < 1 {
+		t.Errorf("invalid average LBD %f", st.AvgLBD)
+	}
+}
+
+func TestConflictBudget(t *testing.T) {
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	nbCalls := 0
+	status := Indet
+	for status == Indet {
+		nbCalls++
+		s.SetConflictBudget(50)
+		status = s.Solve()
+	}
+	if status != Unsat {
+		t.Errorf("expected unsat, got %v", status)
+	}
+	if nbCalls < 2 {
+		t.Errorf("expected the budget to be exhausted at least once")
+	}
+	s.SetConflictBudget(0)
+	if s.conflictLimit != 0 {
+		t.Errorf("budget should have been removed")
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	usage := s.MemoryUsage()
+	if usage <= 0 {
+		t.Fatalf("expected a positive memory usage, got %d", usage)
+	}
+	s.SetMemoryLimit(usage + 1)
+	if status := s.Solve(); status != Indet {
+		t.Errorf("expected indet, got %v", status)
+	}
+	if err := s.Err(); err != ErrMemoryLimit {
+		t.Errorf("expected ErrMemoryLimit, got %v", err)
+	}
+	if s.MemoryUsage() <= usage {
+		t.Errorf("expected clauses to be learned before the limit was exceeded")
+	}
+	s.SetMemoryLimit(0)
+	if status := s.Solve(); status != Unsat {
+		t.Errorf("expected unsat, got %v", status)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("expected no error once the limit was removed, got %v", err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s := New(pb)
+	s.Stop() // No search is running: the next one must stop right away
+	if status := s.Solve(); status != Indet {
+		t.Errorf("expected indet, got %v", status)
+	}
+	if err := s.Err(); err != ErrStopped {
+		t.Errorf("expected ErrStopped, got %v", err)
+	}
+	done := make(chan Status)
+	go func() { done <- s.Solve() }()
+	s.Stop()
+	if status := <-done; status != Indet { // The search was over before it noticed the request: the next one is stopped instead
+		if status := s.Solve(); status != Indet {
+			t.Errorf("expected indet for a pending request, got %v", status)
+		}
+	}
+	if err := s.Err(); err != ErrStopped {
+		t.Errorf("expected ErrStopped, got %v", err)
+	}
+	if status := s.Solve(); status != Unsat {
+		t.Errorf("expected unsat once the request was consumed, got %v", status)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestStopBetweenSolves(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2}, {-1, -2}, {2, 3}}))
+	if status := s.Solve(); status != Sat {
+		t.Fatalf("expected sat, got %v", status)
+	}
+	s.Stop()
+	if status := s.Solve(); status != Indet {
+		t.Errorf("expected indet after a request made between two searches, got %v", status)
+	}
+	if err := s.Err(); err != ErrStopped {
+		t.Errorf("expected ErrStopped, got %v", err)
+	}
+	if status := s.Solve(); status != Sat {
+		t.Errorf("expected sat once the request was consumed, got %v", status)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestWriteLearned(t *testing.T) {
+	parse := func() *Problem {
+		f, err := os.Open("testcnf/150.cnf")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		defer func() { _ = f.Close() }()
+		pb, err := ParseCNF(f)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		return pb
+	}
+	s := New(parse())
+	s.SetConflictBudget(200)
+	s.Solve()
+	var b bytes.Buffer
+	if err := s.WriteLearned(&b); err != nil {
+		t.Fatalf("could not write learned clauses: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	nbLemmas := len(s.State().Lemmas)
+	if nbLemmas == 0 {
+		t.Fatalf("expected clauses to be learned")
+	}
+	if expected := fmt.Sprintf("p cnf %d %d", s.NbVars(), nbLemmas); lines[0] != expected {
+		t.Errorf("expected header %q, got %q", expected, lines[0])
+	}
+	if len(lines) != nbLemmas+1 {
+		t.Fatalf("expected %d clauses, got %d", nbLemmas, len(lines)-1)
+	}
+	s2 := New(parse())
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if fields[len(fields)-1] != "0" {
+			t.Fatalf("clause %q does not end with 0", line)
+		}
+		lemma := make([]int, len(fields)-1)
+		for i := range lemma {
+			lit, err := strconv.Atoi(fields[i])
+			if err != nil {
+				t.Fatalf("invalid lit in clause %q: %v", line, err)
+			}
+			lemma[i] = lit
+		}
+		if err := s2.AddLemma(lemma); err != nil {
+			t.Fatalf("could not add lemma %v: %v", lemma, err)
+		}
+	}
+	if status := s2.Solve(); status != Unsat {
+		t.Errorf("expected unsat, got %v", status)
+	}
+}
+
+func TestFreezeVar(t *testing.T) {
+	clauses := [][]int{ // x4 <=> (x1 & x2), x5 <=> (x4 | x3), x5, ^x3
+		{-4, 1}, {-4, 2}, {4, -1, -2},
+		{-5, 4, 3}, {5, -4}, {5, -3},
+		{5}, {-3},
+	}
+	s := New(ParseSlice(clauses))
+	s.FreezeVar(3)
+	s.FreezeVar(4)
+	s.FreezeVar(42) // Does not exist
+	if status := s.Solve(); status != Sat {
+		t.Fatalf("expected sat, got %v", status)
+	}
+	model := s.Model()
+	for _, clause := range clauses {
+		sat := false
+		for _, lit := range clause {
+			if lit > 0 == model[abs(lit)-1] {
+				sat = true
+			}
+		}
+		if !sat {
+			t.Errorf("model %v does not satisfy clause %v", model, clause)
+		}
+	}
+	s.UnfreezeVar(3)
+	if s.isFrozen(3) || !s.isFrozen(4) {
+		t.Errorf("expected only var 4 to be frozen")
+	}
+	f, err := os.Open("testcnf/150.cnf")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	s = New(pb)
+	for v := 0; v < s.NbVars(); v += 2 {
+		s.FreezeVar(Var(v))
+	}
+	if status := s.Solve(); status != Unsat {
+		t.Errorf("expected unsat, got %v", status)
+	}
+}
+
 func TestCountModel(t *testing.T) {
 	clauses := []CardConstr{
 		AtLeast1(1, 2, 3),
@@ -364,6 +1047,73 @@ func TestEnumerate(t *testing.T) {
 
 }
 
+func TestForEachModel(t *testing.T) {
+	clauses := []CardConstr{
+		AtLeast1(1, 2, 3),
+		AtLeast1(-1, -2, -3),
+		AtLeast1(2, 3, 4),
+		AtLeast1(2, 3, 5),
+		AtLeast1(3, 4, 5),
+		AtLeast1(2, 4, 5),
+	}
+	s := New(ParseCardConstrs(clauses))
+	seen := make(map[string]bool)
+	nb := s.ForEachModel(func(model []bool) bool {
+		seen[fmt.Sprint(model)] = true
+		return true
+	})
+	if nb != 17 || len(seen) != 17 {
+		t.Errorf("Invalid #models: expected %d, got %d (%d distinct)", 17, nb, len(seen))
+	}
+	s = New(ParseCardConstrs(clauses))
+	calls := 0
+	nb = s.ForEachModel(func(model []bool) bool {
+		calls++
+		return calls < 5
+	})
+	if nb != 5 || calls != 5 {
+		t.Errorf("Invalid #models after early stop: expected %d, got %d (%d calls)", 5, nb, calls)
+	}
+}
+
+// runBenchPropagate measures unit propagation alone: all vars are falsified one after the other at level 2,
+// until a conflict arises, then bindings are cleaned up.
+func runBenchPropagate(path string, b *testing.B) {
+	f, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err.Error())
+	}
+	defer func() { _ = f.Close() }()
+	pb, err := ParseCNF(f)
+	if err != nil {
+		b.Fatal(err.Error())
+	}
+	s := New(pb)
+	if s.status == Unsat {
+		b.Fatalf("problem %s is trivially UNSAT", path)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for v := int32(1); v <= int32(s.nbVars); v++ {
+			if s.model[v-1] != 0 {
+				continue
+			}
+			if s.unifyLiteral(IntToLit(-v), 2) != nil {
+				break
+			}
+		}
+		s.cleanupBindings(1)
+	}
+}
+
+func BenchmarkPropagateACG(b *testing.B) {
+	runBenchPropagate("testcnf/ACG-10-5p0.cnf", b)
+}
+
+func BenchmarkPropagateHSAT(b *testing.B) {
+	runBenchPropagate("testcnf/hsat_vc11803.cnf", b)
+}
+
 func BenchmarkCountModels(b *testing.B) {
 	clauses := []CardConstr{
 		AtLeast1(1, 2, 3),
@@ -514,3 +1264,167 @@ func BenchmarkSolver11PigeonsPB(b *testing.B) {
 func BenchmarkSolver11PigeonsPBCP(b *testing.B) {
 	runBenchPB("testcnf/11-pigeons.opb", true, b)
 }
+
+func TestEnumerateCanonical(t *testing.T) {
+	// At least 2 of x1..x4 must be true, x5 is free: 22 models, but only 6 up to permutations of x1..x4
+	cnf := [][]int{{1, 2, 3}, {1, 2, 4}, {1, 3, 4}, {2, 3, 4}}
+	if nb := New(ParseSliceNb(cnf, 5)).CountModels(); nb != 22 {
+		t.Fatalf("expected 22 models, got %d", nb)
+	}
+	s := New(ParseSliceNb(cnf, 5))
+	models := make(chan []bool)
+	done := make(chan int)
+	go func() { done <- s.EnumerateCanonical([][]Var{{0, 1, 2, 3}}, models) }()
+	seen := make(map[string]bool)
+	for model := range models {
+		nbTrue := 0
+		for i, val := range model[:4] {
+			if val {
+				nbTrue++
+				if i > 0 && !model[i-1] {
+					t.Errorf("model %v is not canonical", model)
+				}
+			}
+		}
+		key := fmt.Sprint(nbTrue, model[4])
+		if seen[key] {
+			t.Errorf("model %v is symmetric to a model already emitted", model)
+		}
+		seen[key] = true
+	}
+	if nb := <-done; nb != 6 || len(seen) != 6 {
+		t.Errorf("expected 6 canonical models, got %d (%d distinct orbits)", nb, len(seen))
+	}
+}
+
+func TestEnumerateProjected(t *testing.T) {
+	// x1 or x2, x3 free: 6 models, but only 3 projections on x1, x2
+	cnf := [][]int{{1, 2}}
+	s := New(ParseSliceNb(cnf, 3))
+	out := make(chan []bool)
+	done := make(chan int)
+	go func() { done <- s.EnumerateProjected([]Var{0, 1}, 0, out) }()
+	seen := make(map[string]bool)
+	for proj := range out {
+		key := fmt.Sprint(proj)
+		if seen[key] || (!proj[0] && !proj[1]) {
+			t.Errorf("invalid projection %s", key)
+		}
+		seen[key] = true
+	}
+	if nb := <-done; nb != 3 || len(seen) != 3 {
+		t.Errorf("expected 3 projections, got %d", nb)
+	}
+	s = New(ParseSliceNb(cnf, 3))
+	if nb := s.EnumerateProjected([]Var{0, 1}, 2, nil); nb != 2 {
+		t.Errorf("expected enumeration to stop after 2 projections, got %d", nb)
+	}
+}
+
+func TestModelIterator(t *testing.T) {
+	// x1 or x2, x3 free: 6 models
+	s := New(ParseSliceNb([][]int{{1, 2}}, 3))
+	it := s.ModelIterator()
+	seen := make(map[string]bool)
+	for it.Next() {
+		model := it.Model()
+		key := fmt.Sprint(model)
+		if len(model) != 3 || seen[key] || (!model[0] && !model[1]) {
+			t.Errorf("invalid model %s", key)
+		}
+		seen[key] = true
+	}
+	if len(seen) != 6 {
+		t.Errorf("expected 6 models, got %d", len(seen))
+	}
+	it.Close()
+	it.Close()
+	if it.Next() {
+		t.Errorf("expected no model after Close")
+	}
+	if status := s.Solve(); status != Sat {
+		t.Errorf("expected problem to be sat after Close, got %v", status)
+	}
+	it = s.ModelIterator()
+	nb := 0
+	for it.Next() && nb < 2 {
+		nb++
+	}
+	it.Close()
+	if nb := s.CountModels(); nb != 6 {
+		t.Errorf("expected 6 models after an early Close, got %d", nb)
+	}
+}
+
+func TestExplainUnsat(t *testing.T) {
+	pb := ParseSlice([][]int{{1, 2}, {1, -2}, {-1, 3}, {-1, -3}})
+	s := New(pb)
+	if status := s.Solve(); status != Unsat {
+		t.Fatalf("expected unsat, got %v", status)
+	}
+	expl := s.ExplainUnsat()
+	if !strings.HasSuffix(expl, "→ contradiction") || !strings.Contains(expl, "clause #") {
+		t.Errorf("invalid explanation %q", expl)
+	}
+	pb = ParseSlice([][]int{{1, 2}, {-1, 3}})
+	s = New(pb)
+	if status := s.Solve(); status != Sat {
+		t.Fatalf("expected sat, got %v", status)
+	}
+	if expl := s.ExplainUnsat(); expl != "" {
+		t.Errorf("expected no explanation for a SAT problem, got %q", expl)
+	}
+}
+
+func TestTopLevelConflict(t *testing.T) {
+	constrs := []PBConstr{
+		PropClause(1, 2),
+		PropClause(-3, 4),
+		PropClause(5, 6),
+		PropClause(3),
+		PropClause(-4, -1),
+		AtLeast([]int{5, 6, 7}, 2),
+		PropClause(-4, 1),
+	}
+	if res := TopLevelConflict(constrs); fmt.Sprint(res) != "[1 3 4 6]" {
+		t.Errorf("invalid conflict: expected [1 3 4 6], got %v", res)
+	}
+	constrs = []PBConstr{PropClause(1), GtEq([]int{1, 2}, []int{2, 3}, 6)}
+	if res := TopLevelConflict(constrs); fmt.Sprint(res) != "[1]" {
+		t.Errorf("invalid conflict: expected [1], got %v", res)
+	}
+	constrs = []PBConstr{GtEq([]int{1, 2, 3}, []int{3, 2, 1}, 5), PropClause(-1, -2)}
+	if res := TopLevelConflict(constrs); fmt.Sprint(res) != "[0 1]" {
+		t.Errorf("invalid conflict: expected [0 1], got %v", res)
+	}
+	constrs = []PBConstr{PropClause(1, 2), PropClause(-1, -2)}
+	if res := TopLevelConflict(constrs); res != nil {
+		t.Errorf("expected no conflict, got %v", res)
+	}
+}
+
+func TestLitValue(t *testing.T) {
+	pb := ParseSlice([][]int{{1}, {-1, -2}, {3, 4, 5}})
+	s := New(pb)
+	s.Assume(IntsToLits(5))
+	for _, test := range []struct {
+		lit int32
+		val LBool
+	}{{1, True}, {-1, False}, {2, False}, {-2, True}, {3, Undef}, {-4, Undef}, {5, True}} {
+		if val := s.LitValue(IntToLit(test.lit)); val != test.val {
+			t.Errorf("invalid value for lit %d: expected %v, got %v", test.lit, test.val, val)
+		}
+	}
+	if val := s.VarValue(Var(42)); val != Undef {
+		t.Errorf("invalid value for unknown var: expected %v, got %v", Undef, val)
+	}
+	if status := s.Solve(); status != Sat {
+		t.Fatalf("expected sat, got %v", status)
+	}
+	model := s.Model()
+	for v := range model {
+		if val := s.VarValue(Var(v)); (val == True) != model[v] || val == Undef {
+			t.Errorf("invalid value for var %d after solve: got %v, model is %t", v+1, val, model[v])
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A State is a snapshot of what a solver learned while searching, i.e its learned clauses and the activity of its vars.
+// It can be given to a new solver for the same problem through Restore, so that the search does not start from scratch.
+type State struct {
+	Lemmas   [][]int   // Learned clauses and units, in the DIMACS format. Learned PB constraints are not included.
+	Activity []float64 // Activity of each var
+	VarInc   float64   // Current increment of the activity of vars
+}
+
+// State returns a snapshot of what s learned so far.
+// Current assumptions are kept.
+func (s *Solver) State() State {
+	st := State{Activity: make([]float64, len(s.activity)), VarInc: s.varInc}
+	copy(st.Activity, s.activity)
+	if s.model == nil { // Problem was trivially UNSAT
+		return st
+	}
+	if len(s.assumed) != 0 || len(s.guards) != 0 { // Only keep top-level units that do not depend on assumptions
+		assumed := s.assumed
+		s.assume(nil)
+		defer s.Assume(assumed)
+	}
+	for _, unit := range s.topLevelUnits() {
+		st.Lemmas = append(st.Lemmas, []int{int(unit.Int())})
+	}
+	for _, c := range s.wl.learned {
+		if c.PseudoBoolean() {
+			continue
+		}
+		lemma := make([]int, c.Len())
+		for i := range lemma {
+			lemma[i] = int(c.Get(i).Int())
+		}
+		st.Lemmas = append(st.Lemmas, lemma)
+	}
+	return st
+}
+
+// WriteLearned writes the clauses learned by s so far, and still kept, to w in the DIMACS format:
+// a "p cnf" header, followed by one clause per line, with space-separated lits and a trailing 0.
+// These are the lemmas returned by State, i.e the learned top-level units and clauses, except constraints
+// learned by cutting planes, which are not clauses. They can be given to another solver for the same problem
+// through AddLemma, e.g after parsing them with ParseCNF.
+// An error is returned if the clauses cannot be written to w.
+func (s *Solver) WriteLearned(w io.Writer) error {
+	lemmas := s.State().Lemmas
+	var b strings.Builder
+	fmt.Fprintf(&b, "p cnf %d %d\n", s.nbVars, len(lemmas))
+	for _, lemma := range lemmas {
+		for _, lit := range lemma {
+			b.WriteString(strconv.Itoa(lit))
+			b.WriteByte(' ')
+		}
+		b.WriteString("0\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write learned clauses: %v", err)
+	}
+	return nil
+}
+
+// Restore gives s what another solver for the same problem learned, as returned by State.
+// Lemmas are added through AddLemma, so it is the caller's responsibility to ensure they are implied by the problem.
+// Activities of vars that do not exist in s are ignored.
+// An error is returned if a lemma contains a null literal.
+func (s *Solver) Restore(st State) error {
+	for _, lemma := range st.Lemmas {
+		if err := s.AddLemma(lemma); err != nil {
+			return err
+		}
+	}
+	if st.VarInc != 0 {
+		s.varInc = st.VarInc
+	}
+	for i := 0; i < len(st.Activity) && i < len(s.activity); i++ {
+		s.activity[i] = st.Activity[i]
+	}
+	if s.model != nil {
+		s.rebuildOrderHeap()
+	}
+	return nil
+}
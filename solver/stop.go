@@ -0,0 +1,30 @@
+package solver
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrStopped is returned by Err when the last search was stopped by a call to Stop.
+var ErrStopped = errors.New("search stopped")
+
+// Stop asks the search currently run by Solve or Minimize to stop as soon as possible, typically from another goroutine.
+// Solve then returns Indet, and Err returns ErrStopped, while Minimize returns the cost of the best model found so far, if any.
+// It is safe to call Stop concurrently with a search, and it is the only method of the solver that is.
+// If no search is running, the next one stops right away, so that a request made just before a search starts is not lost.
+// Likewise, a request is only dropped once a search was stopped by it: if the search was over before it could notice
+// the request, e.g because no decision was needed, the next one is stopped instead, so that a method solving
+// several problems in a row, such as Minimize, stops too.
+// Nothing learned so far is lost, so calling Solve again resumes the search.
+func (s *Solver) Stop() {
+	atomic.StoreInt32(&s.stopReq, 1)
+}
+
+// stopRequested returns true iff Stop was called since the current call to Solve began, or before it,
+// if the request was not consumed by an earlier search yet.
+func (s *Solver) stopRequested() bool {
+	if atomic.SwapInt32(&s.stopReq, 0) == 1 {
+		s.stopped = true
+	}
+	return s.stopped
+}
@@ -35,6 +35,31 @@ func (s Status) String() string {
 	}
 }
 
+// LBool is the value of a literal or of a variable in a partial assignment.
+type LBool byte
+
+const (
+	// Undef means the literal or variable is not bound yet.
+	Undef = LBool(iota)
+	// True means the literal or variable is bound to true.
+	True
+	// False means the literal or variable is bound to false.
+	False
+)
+
+func (b LBool) String() string {
+	switch b {
+	case Undef:
+		return "UNDEF"
+	case True:
+		return "TRUE"
+	case False:
+		return "FALSE"
+	default:
+		panic("invalid lbool")
+	}
+}
+
 // Var start at 0 ; thus the CNF variable 1 is encoded as the Var 0.
 type Var int32
 
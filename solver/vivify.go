@@ -0,0 +1,94 @@
+package solver
+
+import "fmt"
+
+// SetVivification enables or disables the vivification of learned clauses.
+// When enabled, on each restart, the solver tries to strengthen the learned clauses that were not vivified yet,
+// by falsifying their literals one after the other and propagating them:
+// literals that are falsified by the propagation of the previous ones can be removed from the clause,
+// and the clause can be truncated as soon as a conflict arises or a literal is satisfied.
+// Strengthened clauses are still implied by the problem, but they are shorter, so they propagate earlier.
+// Vivification is disabled while literals are assumed, since top-level bindings are then not all permanent.
+// It is disabled by default.
+func (s *Solver) SetVivification(enabled bool) {
+	s.vivification = enabled
+}
+
+// vivify tries to strengthen the learned clauses that were not vivified yet.
+// It must be called when only top-level bindings exist, and no literal is assumed.
+// It returns false iff the problem was proven UNSAT.
+func (s *Solver) vivify() bool {
+	for i := s.nbVivified; i < len(s.wl.learned); i++ {
+		c := s.wl.learned[i]
+		if c.PseudoBoolean() || c.Cardinality() != 1 || c.Len() <= 2 || s.isLocked(c) {
+			continue
+		}
+		s.unwatchClause(c) // Don't let c propagate its own lits
+		lits := s.vivifyLits(c)
+		switch len(lits) {
+		case c.Len(): // Nothing to strengthen
+			s.watchClause(c)
+		case 0: // All lits are false at the top level
+			s.unsat = true
+			s.setUnsat()
+			return false
+		case 1: // Unit clause: remove it and propagate its lit
+			last := len(s.wl.learned) - 1
+			s.wl.learned[i] = s.wl.learned[last]
+			s.wl.learned = s.wl.learned[:last]
+			i--
+			s.Stats.NbVivified++
+			s.Stats.NbVivifiedLits += c.Len() - 1
+			unit := lits[0]
+			if s.litStatus(unit) == Sat {
+				continue
+			}
+			s.Stats.NbUnitLearned++
+			s.addLearnedUnit(unit)
+			if s.unifyLiteral(unit, 1) != nil {
+				s.unsat = true
+				s.setUnsat()
+				return false
+			}
+			s.rebuildOrderHeap()
+		default:
+			s.Stats.NbVivified++
+			s.Stats.NbVivifiedLits += c.Len() - len(lits)
+			c.lits = lits
+			if c.lbd() > len(lits) {
+				c.setLbd(len(lits))
+			}
+			s.watchClause(c)
+			if s.Certified {
+				if s.CertChan == nil {
+					fmt.Printf("%s\n", c.CNF())
+				} else {
+					s.CertChan <- c.CNF()
+				}
+			}
+		}
+	}
+	s.nbVivified = len(s.wl.learned)
+	return true
+}
+
+// vivifyLits returns the lits of a clause implied by the problem and c that is a subset of c.
+// The lits of c are falsified one after the other at level 2, until a conflict arises or a lit is satisfied.
+// c must not be watched.
+func (s *Solver) vivifyLits(c *Clause) []Lit {
+	lits := make([]Lit, 0, c.Len())
+	for _, lit := range c.lits {
+		if status := s.litStatus(lit); status == Unsat { // Implied by the negation of the previous lits: remove it
+			continue
+		} else if status == Sat { // Implied by the negation of the previous lits: ignore the next ones
+			lits = append(lits, lit)
+			break
+		}
+		lits = append(lits, lit)
+		if s.unifyLiteral(lit.Negation(), 2) != nil {
+			break
+		}
+	}
+	s.cleanupBindings(1)
+	return lits
+}
@@ -10,16 +10,23 @@ type watcher struct {
 	clause *Clause
 }
 
+// litWatchers holds all the constraints watched by a literal.
+// They are kept together, rather than in one slice per kind of constraint, so that propagating a literal
+// reads the headers of all its lists from a single place in memory.
+type litWatchers struct {
+	bin  []watcher // Binary clauses where the negation of the literal appears
+	prop []watcher // Non-binary clauses where the negation of the literal appears at position 1 or 2
+	pb   []*Clause // PB or cardinality constraints
+	amo  []*Clause // Cardinality constraints where card = length - 1, meaning any false literal propagates all others.
+}
+
 // A watcherList is a structure used to store clauses and propagate unit literals efficiently.
 type watcherList struct {
-	nbMax        int         // Max # of learned clauses at current moment
-	idxReduce    int         // # of calls to reduce + 1
-	wlistBin     [][]watcher // For each literal, a list of binary clauses where its negation appears
-	wlist        [][]watcher // For each literal, a list of non-binary clauses where its negation appears at position 1 or 2
-	wlistPb      [][]*Clause // For each literal, a list of PB or cardinality constraints.
-	wlistCardAMO [][]*Clause // For each literal, a list of cardinality constraints where card = length - 1, meaning any false literal propagates all others.
-	origClauses  []*Clause   // All the problem clauses.
-	learned      []*Clause
+	nbMax       int           // Max # of learned clauses at current moment
+	idxReduce   int           // # of calls to reduce + 1
+	watches     []litWatchers // For each literal, the constraints it watches.
+	origClauses []*Clause     // All the problem clauses.
+	learned     []*Clause
 }
 
 // initWatcherList makes a new watcherList for the solver.
@@ -28,13 +35,10 @@ func (s *Solver) initWatcherList(clauses []*Clause) {
 	newClauses := make([]*Clause, len(clauses))
 	copy(newClauses, clauses)
 	s.wl = watcherList{
-		nbMax:        nbMax,
-		idxReduce:    1,
-		wlistBin:     make([][]watcher, s.nbVars*2),
-		wlist:        make([][]watcher, s.nbVars*2),
-		wlistPb:      make([][]*Clause, s.nbVars*2),
-		wlistCardAMO: make([][]*Clause, s.nbVars*2),
-		origClauses:  newClauses,
+		nbMax:       nbMax,
+		idxReduce:   1,
+		watches:     make([]litWatchers, s.nbVars*2),
+		origClauses: newClauses,
 	}
 	for _, c := range clauses {
 		s.watchClause(c)
@@ -45,10 +49,7 @@ func (s *Solver) initWatcherList(clauses []*Clause) {
 func (s *Solver) addVarWatcherList(v Var) {
 	cnfVar := int(v.Int())
 	for i := s.nbVars; i < cnfVar; i++ {
-		s.wl.wlistBin = append(s.wl.wlistBin, nil, nil)
-		s.wl.wlist = append(s.wl.wlist, nil, nil)
-		s.wl.wlistPb = append(s.wl.wlistPb, nil, nil)
-		s.wl.wlistCardAMO = append(s.wl.wlistCardAMO, nil, nil)
+		s.wl.watches = append(s.wl.watches, litWatchers{}, litWatchers{})
 	}
 }
 
@@ -98,7 +99,7 @@ func (s *Solver) watchClause(c *Clause) {
 			for i := 0; i < c.Cardinality()+1; i++ {
 				lit := c.Get(i)
 				neg := lit.Negation()
-				s.wl.wlistPb[neg] = append(s.wl.wlistPb[neg], c)
+				s.wl.watches[neg].pb = append(s.wl.watches[neg].pb, c)
 			}
 		}
 	} else if c.Len() == 2 {
@@ -107,16 +108,16 @@ func (s *Solver) watchClause(c *Clause) {
 		second := c.Second()
 		neg0 := first.Negation()
 		neg1 := second.Negation()
-		s.wl.wlistBin[neg0] = append(s.wl.wlistBin[neg0], watcher{clause: c, other: second})
-		s.wl.wlistBin[neg1] = append(s.wl.wlistBin[neg1], watcher{clause: c, other: first})
+		s.wl.watches[neg0].bin = append(s.wl.watches[neg0].bin, watcher{clause: c, other: second})
+		s.wl.watches[neg1].bin = append(s.wl.watches[neg1].bin, watcher{clause: c, other: first})
 	} else { // Regular, propositional clause
 		// log.Printf("watching regular %s", c.PBString())
 		first := c.First()
 		second := c.Second()
 		neg0 := first.Negation()
 		neg1 := second.Negation()
-		s.wl.wlist[neg0] = append(s.wl.wlist[neg0], watcher{clause: c, other: second})
-		s.wl.wlist[neg1] = append(s.wl.wlist[neg1], watcher{clause: c, other: first})
+		s.wl.watches[neg0].prop = append(s.wl.watches[neg0].prop, watcher{clause: c, other: second})
+		s.wl.watches[neg1].prop = append(s.wl.watches[neg1].prop, watcher{clause: c, other: first})
 	}
 }
 
@@ -129,7 +130,7 @@ func (s *Solver) watchPB(c *Clause) {
 	for sum < goal && i < c.Len() {
 		lit := c.Get(i)
 		neg := lit.Negation()
-		s.wl.wlistPb[neg] = append(s.wl.wlistPb[neg], c)
+		s.wl.watches[neg].pb = append(s.wl.watches[neg].pb, c)
 		c.pbData.watched[i] = true
 		sum += c.Weight(i)
 		i++
@@ -143,7 +144,7 @@ func (s *Solver) watchCardAMO(c *Clause, card int) {
 	for i := 0; i < card+1; i++ {
 		lit := c.Get(i)
 		neg := lit.Negation()
-		s.wl.wlistCardAMO[neg] = append(s.wl.wlistCardAMO[neg], c)
+		s.wl.watches[neg].amo = append(s.wl.watches[neg].amo, c)
 	}
 }
 
@@ -155,14 +156,14 @@ func (s *Solver) unwatchClause(c *Clause) {
 	for i := 0; i < 2; i++ {
 		neg := c.Get(i).Negation()
 		j := 0
-		length := len(s.wl.wlist[neg])
+		length := len(s.wl.watches[neg].prop)
 		// We're looking for the index of the clause.
 		// This will panic if c is not in wlist[neg], but this shouldn't happen.
-		for s.wl.wlist[neg][j].clause != c {
+		for s.wl.watches[neg].prop[j].clause != c {
 			j++
 		}
-		s.wl.wlist[neg][j] = s.wl.wlist[neg][length-1]
-		s.wl.wlist[neg] = s.wl.wlist[neg][:length-1]
+		s.wl.watches[neg].prop[j] = s.wl.watches[neg].prop[length-1]
+		s.wl.watches[neg].prop = s.wl.watches[neg].prop[:length-1]
 	}
 }
 
@@ -175,15 +176,56 @@ func (s *Solver) unwatchPB(c *Clause) {
 		}
 		neg := c.Get(i).Negation()
 		j := 0
-		length := len(s.wl.wlistPb[neg])
+		length := len(s.wl.watches[neg].pb)
 		// We're looking for the index of the clause.
 		// This will panic if c is not in wlist[neg], but this shouldn't happen.
-		for s.wl.wlistPb[neg][j] != c {
+		for s.wl.watches[neg].pb[j] != c {
+			j++
+		}
+		s.wl.watches[neg].pb[j] = s.wl.watches[neg].pb[length-1]
+		s.wl.watches[neg].pb = s.wl.watches[neg].pb[:length-1]
+	}
+}
+
+// removeLearnedWith removes the learned clauses and constraints that contain lit, e.g because they depend on
+// a constraint guarded by the negation of lit that was modified.
+func (s *Solver) removeLearnedWith(lit Lit) {
+	j := 0
+	for _, c := range s.wl.learned {
+		found := false
+		for _, l := range c.lits {
+			if l == lit {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.wl.learned[j] = c
 			j++
+			continue
+		}
+		s.Stats.NbDeleted++
+		switch {
+		case c.PseudoBoolean():
+			s.unwatchPB(c)
+		case c.Len() == 2:
+			for i := 0; i < 2; i++ {
+				neg := c.Get(i).Negation()
+				ws := s.wl.watches[neg].bin
+				for k := range ws {
+					if ws[k].clause == c {
+						ws[k] = ws[len(ws)-1]
+						s.wl.watches[neg].bin = ws[:len(ws)-1]
+						break
+					}
+				}
+			}
+		default:
+			s.unwatchClause(c)
 		}
-		s.wl.wlistPb[neg][j] = s.wl.wlistPb[neg][length-1]
-		s.wl.wlistPb[neg] = s.wl.wlistPb[neg][:length-1]
 	}
+	s.wl.learned = s.wl.learned[:j]
+	s.nbVivified = 0 // Clauses were reordered
 }
 
 // reduceLearned removes a few learned clauses that are deemed useless.
@@ -197,7 +239,7 @@ func (s *Solver) reduceLearned() {
 	nbRemoved := 0
 	for i := 0; i < length; i++ {
 		c := s.wl.learned[i]
-		if c.lbd() <= 2 || c.isLocked() {
+		if c.lbd() <= 2 || s.isLocked(c) {
 			continue
 		}
 		nbRemoved++
@@ -207,6 +249,7 @@ func (s *Solver) reduceLearned() {
 	}
 	nbLearned -= nbRemoved
 	s.wl.learned = s.wl.learned[:nbLearned]
+	s.nbVivified = 0 // Clauses were reordered
 }
 
 type watcherListPB watcherList // A type synonymous to sort PB constraints a little more efficiently.
@@ -226,7 +269,7 @@ func (s *Solver) reduceLearnedPB() {
 	nbRemoved := 0
 	for i := 0; i < length; i++ {
 		c := s.wl.learned[i]
-		if c.isLocked() {
+		if s.isLocked(c) {
 			continue
 		}
 		nbRemoved++
@@ -238,6 +281,18 @@ func (s *Solver) reduceLearnedPB() {
 	s.wl.learned = s.wl.learned[:nbLearned]
 }
 
+// isLocked returns true iff c is the reason of the current binding of one of its lits, so that it must not be removed.
+// This is computed from the reasons, rather than flagged in the clause itself, so that propagating and backtracking
+// do not have to write to the clauses.
+func (s *Solver) isLocked(c *Clause) bool {
+	for _, lit := range c.lits {
+		if v := lit.Var(); s.reason[v] == c && s.model[v] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // Adds the given learned clause and updates watchers.
 // If too many clauses have been learned yet, one will be removed.
 func (s *Solver) addLearned(c *Clause) {
@@ -290,7 +345,7 @@ func (s *Solver) propagate(ptr int, lvl decLevel) *Clause {
 	for ptr < len(s.trail) {
 		lit := s.trail[ptr]
 		// log.Printf("propagating %d", lit.Int())
-		for _, w := range s.wl.wlistBin[lit] {
+		for _, w := range s.wl.watches[lit].bin {
 			v2 := w.other.Var()
 			if assign := s.model[v2]; assign == 0 { // Other was unbounded: propagate
 				s.reason[v2] = w.clause
@@ -303,7 +358,7 @@ func (s *Solver) propagate(ptr int, lvl decLevel) *Clause {
 		if confl := s.simplifyPropClauses(lit, lvl); confl != nil {
 			return confl
 		}
-		for _, c := range s.wl.wlistPb[lit] {
+		for _, c := range s.wl.watches[lit].pb {
 			if c.PseudoBoolean() {
 				if !s.simplifyPseudoBool(c, lvl) {
 					return c
@@ -314,7 +369,7 @@ func (s *Solver) propagate(ptr int, lvl decLevel) *Clause {
 				}
 			}
 		}
-		for _, c := range s.wl.wlistCardAMO[lit] {
+		for _, c := range s.wl.watches[lit].amo {
 			if !s.simplifyCardAMOConstr(c, lvl) {
 				return c
 			}
@@ -349,13 +404,12 @@ func (s *Solver) propagateUnit(c *Clause, lvl decLevel, unit Lit) {
 	// log.Printf("propagating unit %d", unit.Int())
 	v := unit.Var()
 	s.reason[v] = c
-	c.lock()
 	s.model[v] = lvlToSignedLvl(unit, lvl)
 	s.trail = append(s.trail, unit)
 }
 
 func (s *Solver) simplifyPropClauses(lit Lit, lvl decLevel) *Clause {
-	wl := s.wl.wlist[lit]
+	wl := s.wl.watches[lit].prop
 	j := 0
 	for i, w := range wl {
 		if s.litStatus(w.other) == Sat { // blocking literal is SAT? Don't explore clause!
@@ -379,7 +433,7 @@ func (s *Solver) simplifyPropClauses(lit Lit, lvl decLevel) *Clause {
 				if litK := c.Get(k); s.litStatus(litK) != Unsat {
 					c.swap(1, k)
 					neg := litK.Negation()
-					s.wl.wlist[neg] = append(s.wl.wlist[neg], w2)
+					s.wl.watches[neg].prop = append(s.wl.watches[neg].prop, w2)
 					found = true
 					break
 				}
@@ -389,14 +443,14 @@ func (s *Solver) simplifyPropClauses(lit Lit, lvl decLevel) *Clause {
 				j++
 				if firstStatus == Unsat {
 					copy(wl[j:], wl[i+1:]) // Keep remaining clauses
-					s.wl.wlist[lit] = wl[:len(wl)-((i+1)-j)]
+					s.wl.watches[lit].prop = wl[:len(wl)-((i+1)-j)]
 					return c
 				}
 				s.propagateUnit(c, lvl, c.First())
 			}
 		}
 	}
-	s.wl.wlist[lit] = wl[:j]
+	s.wl.watches[lit].prop = wl[:j]
 	return nil
 }
 
@@ -492,8 +546,8 @@ func (s *Solver) swapFalse(clause *Clause) {
 			j++
 			lit = clause.Get(j)
 		}
-		ni := &s.wl.wlistPb[clause.Get(i).Negation()]
-		nj := &s.wl.wlistPb[clause.Get(j).Negation()]
+		ni := &s.wl.watches[clause.Get(i).Negation()].pb
+		nj := &s.wl.watches[clause.Get(j).Negation()].pb
 		clause.swap(i, j)
 		*ni = removeFrom(*ni, clause)
 		*nj = append(*nj, clause)
@@ -572,14 +626,14 @@ func (s *Solver) updateWatchPB(clause *Clause) {
 		lit := clause.Get(i)
 		if s.litStatus(lit) == Unsat {
 			if clause.pbData.watched[i] {
-				ni := &s.wl.wlistPb[lit.Negation()]
+				ni := &s.wl.watches[lit.Negation()].pb
 				*ni = removeFrom(*ni, clause)
 				clause.pbData.watched[i] = false
 			}
 		} else {
 			weightWatched += clause.Weight(i)
 			if !clause.pbData.watched[i] {
-				ni := &s.wl.wlistPb[lit.Negation()]
+				ni := &s.wl.watches[lit.Negation()].pb
 				*ni = append(*ni, clause)
 				clause.pbData.watched[i] = true
 			}
@@ -589,7 +643,7 @@ func (s *Solver) updateWatchPB(clause *Clause) {
 	// If there are some more watched literals, they are now useless
 	for i := i; i < clause.Len(); i++ {
 		if clause.pbData.watched[i] {
-			ni := &s.wl.wlistPb[clause.Get(i).Negation()]
+			ni := &s.wl.watches[clause.Get(i).Negation()].pb
 			*ni = removeFrom(*ni, clause)
 			clause.pbData.watched[i] = false
 		}